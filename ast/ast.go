@@ -121,6 +121,10 @@ type MemberIdentifier struct {
 	Child       Node
 	ChildTok    *SQLToken
 	ChildIdent  *Identifier
+	// Qualifier is the leading part of a three-part identifier such as
+	// "schema.table.column", in which case Parent is the middle part.
+	Qualifier    Node
+	QualifierTok *SQLToken
 }
 
 func NewMemberIdentifierParent(nodes []Node, parent Node) *MemberIdentifier {
@@ -145,6 +149,16 @@ func NewMemberIdentifier(nodes []Node, parent Node, child Node) *MemberIdentifie
 	return memberIdentifier
 }
 
+// NewQualifiedMemberIdentifier creates a three-part member identifier.
+func NewQualifiedMemberIdentifier(nodes []Node, qualifier Node, parent Node, child Node) *MemberIdentifier {
+	memberIdentifier := NewMemberIdentifier(nodes, parent, child)
+	memberIdentifier.Qualifier = qualifier
+	if tok, ok := qualifier.(Token); ok {
+		memberIdentifier.QualifierTok = tok.GetToken()
+	}
+	return memberIdentifier
+}
+
 func (mi *MemberIdentifier) String() string {
 	var strs []string
 	for _, t := range mi.Toks {
@@ -196,6 +210,15 @@ func (mi *MemberIdentifier) GetChild() Node {
 	}
 	return mi.Child
 }
+
+// QualifierName returns the unquoted qualifier of a three-part identifier,
+// or an empty string for a two-part one.
+func (mi *MemberIdentifier) QualifierName() string {
+	if mi.QualifierTok == nil {
+		return ""
+	}
+	return mi.QualifierTok.NoQuoteString()
+}
 func (mi *MemberIdentifier) GetChildIdent() *Identifier {
 	if mi.ChildIdent == nil {
 		return &Identifier{}
@@ -403,6 +426,14 @@ func (q *Query) End() token.Pos        { return findTo(q) }
 
 type Statement struct {
 	Toks []Node
+	// Query is the statement as it is sent to the database, without the
+	// client syntax of the script around it such as DELIMITER lines and GO
+	// batch separators.
+	Query string
+	// EndsBatch tells that the statement is the last one sent at once with
+	// those before it, as up to a GO line. It is set on every statement of
+	// databases sent one statement at a time.
+	EndsBatch bool
 }
 
 func (s *Statement) String() string {
@@ -521,14 +552,10 @@ func (t *SQLToken) String() string {
 	switch v := t.Value.(type) {
 	case *token.SQLWord:
 		return v.String()
+	case token.HashComment:
+		return "#" + string(v)
 	case string:
-		if t.Kind == token.Comment {
-			return "--" + v
-		}
-		if t.Kind == token.MultilineComment {
-			return "/*" + v + "*/"
-		}
-		return v
+		return tokenText(t.Kind, v)
 	default:
 		return " "
 	}
@@ -538,14 +565,10 @@ func (t *SQLToken) NoQuoteString() string {
 	switch v := t.Value.(type) {
 	case *token.SQLWord:
 		return v.NoQuoteString()
+	case token.HashComment:
+		return "#" + string(v)
 	case string:
-		if t.Kind == token.Comment {
-			return "--" + v
-		}
-		if t.Kind == token.MultilineComment {
-			return "/*" + v + "*/"
-		}
-		return v
+		return tokenText(t.Kind, v)
 	default:
 		return " "
 	}
@@ -555,19 +578,31 @@ func (t *SQLToken) Render(opts *RenderOptions) string {
 	switch v := t.Value.(type) {
 	case *token.SQLWord:
 		return renderSQLWord(v, opts)
+	case token.HashComment:
+		return "#" + string(v)
 	case string:
-		if t.Kind == token.Comment {
-			return "--" + v
-		}
-		if t.Kind == token.MultilineComment {
-			return "/*" + v + "*/"
-		}
-		return v
+		return tokenText(t.Kind, v)
 	default:
 		return " "
 	}
 }
 
+// tokenText returns the text of a token from its value, which leaves out
+// the markers of comments and string prefixes.
+func tokenText(kind token.Kind, v string) string {
+	switch kind {
+	case token.Comment:
+		return "--" + v
+	case token.MultilineComment:
+		return "/*" + v + "*/"
+	case token.NationalStringLiteral:
+		return "N" + v
+	case token.EscapeStringLiteral:
+		return "E" + v
+	}
+	return v
+}
+
 func renderSQLWord(v *token.SQLWord, opts *RenderOptions) string {
 	isIdentifier := v.Kind == dialect.Unmatched
 	if isIdentifier {
@@ -1,5 +1,7 @@
 package dialect
 
+import "unicode"
+
 type Dialect interface {
 	IsIdentifierStart(r rune) bool
 	IsIdentifierPart(r rune) bool
@@ -8,6 +10,42 @@ type Dialect interface {
 	IsPlaceHolderPart(r rune) bool
 }
 
+// SyntaxDialect is implemented by dialects writing strings or comments in
+// a syntax of their own. Dialects which do not implement it have none of
+// it.
+type SyntaxDialect interface {
+	// HasHashComments tells whether # starts a comment to the end of the
+	// line.
+	HasHashComments() bool
+	// HasDollarQuotedStrings tells whether $$ or $tag$ quotes strings.
+	HasDollarQuotedStrings() bool
+	// HasEscapeStrings tells whether E'...' strings take backslash escapes.
+	HasEscapeStrings() bool
+	// HasDoubleQuotedStrings tells whether "..." quotes a string rather
+	// than an identifier.
+	HasDoubleQuotedStrings() bool
+}
+
+// ForDriver returns the dialect scripts of driver are written in, the
+// generic one for databases without a dialect of their own.
+func ForDriver(driver DatabaseDriver) Dialect {
+	switch driver {
+	case DatabaseDriverMySQL, DatabaseDriverMySQL8, DatabaseDriverMySQL57, DatabaseDriverMySQL56:
+		return &MySQLDialect{}
+	case DatabaseDriverPostgreSQL:
+		return &PostgreSQLDialect{}
+	case DatabaseDriverMssql:
+		return &MSSQLDialect{}
+	case DatabaseDriverOracle:
+		return &OracleDialect{}
+	case DatabaseDriverSQLite3:
+		return &SQLiteDialect{}
+	case DatabaseDriverClickhouse:
+		return &ClickHouseDialect{}
+	}
+	return &GenericSQLDialect{}
+}
+
 type GenericSQLDialect struct {
 }
 
@@ -31,4 +69,133 @@ func (*GenericSQLDialect) IsPlaceHolderPart(r rune) bool {
 	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
 }
 
-var _ Dialect = &GenericSQLDialect{}
+func (*GenericSQLDialect) HasHashComments() bool        { return false }
+func (*GenericSQLDialect) HasDollarQuotedStrings() bool { return false }
+func (*GenericSQLDialect) HasEscapeStrings() bool       { return false }
+func (*GenericSQLDialect) HasDoubleQuotedStrings() bool { return false }
+
+var (
+	_ Dialect       = &GenericSQLDialect{}
+	_ SyntaxDialect = &GenericSQLDialect{}
+)
+
+func isWordStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+func isWordPart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// MySQLDialect quotes identifiers with backticks, comments with # and has
+// @var user and @@var system variables.
+type MySQLDialect struct {
+	GenericSQLDialect
+}
+
+func (*MySQLDialect) IsIdentifierStart(r rune) bool { return isWordStart(r) }
+
+// IsIdentifierPart leaves out $, which MySQL allows in identifiers, as
+// scripts end statements with DELIMITER $$ right after words.
+func (*MySQLDialect) IsIdentifierPart(r rune) bool { return isWordPart(r) }
+
+// IsDelimitedIdentifierStart leaves out ", which quotes strings unless
+// the ANSI_QUOTES mode is set.
+func (*MySQLDialect) IsDelimitedIdentifierStart(r rune) bool { return r == '`' }
+
+func (*MySQLDialect) IsPlaceHolderStart(r rune) bool { return r == '@' }
+func (*MySQLDialect) IsPlaceHolderPart(r rune) bool  { return isWordPart(r) || r == '@' || r == '$' }
+func (*MySQLDialect) HasHashComments() bool          { return true }
+func (*MySQLDialect) HasDoubleQuotedStrings() bool   { return true }
+
+var _ Dialect = &MySQLDialect{}
+
+// PostgreSQLDialect has $1 parameters, $$ quoted strings and E'...' escape
+// strings.
+type PostgreSQLDialect struct {
+	GenericSQLDialect
+}
+
+func (*PostgreSQLDialect) IsIdentifierStart(r rune) bool          { return isWordStart(r) }
+func (*PostgreSQLDialect) IsIdentifierPart(r rune) bool           { return isWordPart(r) || r == '$' }
+func (*PostgreSQLDialect) IsDelimitedIdentifierStart(r rune) bool { return r == '"' }
+func (*PostgreSQLDialect) IsPlaceHolderStart(r rune) bool         { return r == '$' }
+func (*PostgreSQLDialect) IsPlaceHolderPart(r rune) bool          { return r >= '0' && r <= '9' }
+func (*PostgreSQLDialect) HasDollarQuotedStrings() bool           { return true }
+func (*PostgreSQLDialect) HasEscapeStrings() bool                 { return true }
+
+var _ Dialect = &PostgreSQLDialect{}
+
+// MSSQLDialect quotes identifiers with brackets, names temporary tables
+// #name and has @var variables and @@var functions.
+type MSSQLDialect struct {
+	GenericSQLDialect
+}
+
+func (*MSSQLDialect) IsIdentifierStart(r rune) bool { return isWordStart(r) || r == '#' }
+func (*MSSQLDialect) IsIdentifierPart(r rune) bool {
+	return isWordPart(r) || r == '#' || r == '$' || r == '@'
+}
+
+func (*MSSQLDialect) IsDelimitedIdentifierStart(r rune) bool {
+	return r == '[' || r == '"'
+}
+
+func (*MSSQLDialect) IsPlaceHolderStart(r rune) bool { return r == '@' }
+func (*MSSQLDialect) IsPlaceHolderPart(r rune) bool {
+	return isWordPart(r) || r == '@' || r == '#' || r == '$'
+}
+
+var _ Dialect = &MSSQLDialect{}
+
+// OracleDialect has :name and :1 bind variables.
+type OracleDialect struct {
+	GenericSQLDialect
+}
+
+func (*OracleDialect) IsIdentifierStart(r rune) bool          { return unicode.IsLetter(r) }
+func (*OracleDialect) IsIdentifierPart(r rune) bool           { return isWordPart(r) || r == '$' || r == '#' }
+func (*OracleDialect) IsDelimitedIdentifierStart(r rune) bool { return r == '"' }
+func (*OracleDialect) IsPlaceHolderStart(r rune) bool         { return r == ':' }
+func (*OracleDialect) IsPlaceHolderPart(r rune) bool          { return isWordPart(r) }
+
+var _ Dialect = &OracleDialect{}
+
+// SQLiteDialect accepts any of the identifier quotes of other databases
+// and has :name, @name and $name parameters.
+type SQLiteDialect struct {
+	GenericSQLDialect
+}
+
+func (*SQLiteDialect) IsIdentifierStart(r rune) bool { return isWordStart(r) }
+func (*SQLiteDialect) IsIdentifierPart(r rune) bool  { return isWordPart(r) || r == '$' }
+
+func (*SQLiteDialect) IsDelimitedIdentifierStart(r rune) bool {
+	return r == '"' || r == '`' || r == '['
+}
+
+func (*SQLiteDialect) IsPlaceHolderStart(r rune) bool {
+	return r == ':' || r == '@' || r == '$'
+}
+
+func (*SQLiteDialect) IsPlaceHolderPart(r rune) bool { return isWordPart(r) }
+
+var _ Dialect = &SQLiteDialect{}
+
+// ClickHouseDialect quotes identifiers with backticks or double quotes and
+// comments with #.
+type ClickHouseDialect struct {
+	GenericSQLDialect
+}
+
+func (*ClickHouseDialect) IsIdentifierStart(r rune) bool { return isWordStart(r) }
+func (*ClickHouseDialect) IsIdentifierPart(r rune) bool  { return isWordPart(r) }
+
+func (*ClickHouseDialect) IsDelimitedIdentifierStart(r rune) bool {
+	return r == '"' || r == '`'
+}
+
+func (*ClickHouseDialect) IsPlaceHolderStart(r rune) bool { return false }
+func (*ClickHouseDialect) HasHashComments() bool          { return true }
+
+var _ Dialect = &ClickHouseDialect{}
@@ -47,19 +47,14 @@ func (c *Completer) columnCandidates(targetTables []*parseutil.TableInfo, parent
 	switch parent.Type {
 	case ParentTypeNone:
 		for _, table := range targetTables {
-			if table.DatabaseSchema != "" && table.Name != "" {
-				columns, ok := c.DBCache.ColumnDatabase(table.DatabaseSchema, table.Name)
-				if !ok {
-					continue
-				}
-				candidates = append(candidates, generateColumnCandidates(table.Name, columns)...)
-			} else if table.Name != "" {
-				columns, ok := c.DBCache.ColumnDescs(table.Name)
-				if !ok {
-					continue
-				}
-				candidates = append(candidates, generateColumnCandidates(table.Name, columns)...)
+			if table.Name == "" {
+				continue
 			}
+			columns, ok := c.DBCache.ColumnDatabase(table.DatabaseSchema, table.Name)
+			if !ok {
+				continue
+			}
+			candidates = append(candidates, generateColumnCandidates(table.Name, columns)...)
 		}
 	case ParentTypeSchema:
 		// pass
@@ -68,7 +63,10 @@ func (c *Completer) columnCandidates(targetTables []*parseutil.TableInfo, parent
 			if table.Name != parent.Name && table.Alias != parent.Name {
 				continue
 			}
-			columns, ok := c.DBCache.ColumnDescs(table.Name)
+			if parent.Schema != "" && !strings.EqualFold(table.DatabaseSchema, parent.Schema) {
+				continue
+			}
+			columns, ok := c.DBCache.ColumnDatabase(table.DatabaseSchema, table.Name)
 			if !ok {
 				continue
 			}
@@ -113,7 +111,11 @@ func (c *Completer) ReferencedTableCandidates(targetTables []*parseutil.TableInf
 
 	for _, targetTable := range targetTables {
 		includeTables := []*parseutil.TableInfo{}
-		for _, table := range c.DBCache.SortedTables() {
+		tables := c.DBCache.SortedTables()
+		if targetTable.DatabaseSchema != "" {
+			tables, _ = c.DBCache.SortedTablesByDBName(targetTable.DatabaseSchema)
+		}
+		for _, table := range tables {
 			if table == targetTable.Name {
 				includeTables = append(includeTables, targetTable)
 			}
@@ -166,7 +168,7 @@ func (c *Completer) joinCandidates(lastTable *parseutil.TableInfo,
 
 	tMap := make(map[string]*parseutil.TableInfo)
 	for _, t := range targetTables {
-		tMap[t.Name] = t
+		tMap[c.DBCache.TableKey(t.DatabaseSchema, t.Name)] = t
 	}
 	fkMap := make(map[string][][]*database.ForeignKey)
 	if lastTable == nil {
@@ -176,22 +178,24 @@ func (c *Completer) joinCandidates(lastTable *parseutil.TableInfo,
 			}
 		}
 	} else {
-		delete(tMap, lastTable.Name)
+		delete(tMap, c.DBCache.TableKey(lastTable.DatabaseSchema, lastTable.Name))
 		rTab := []*parseutil.TableInfo{lastTable}
 		if !joinOn {
 			rTab = resolveTables(lastTable, c.DBCache)
 		}
 		for _, lt := range rTab {
-			for k, v := range c.DBCache.ForeignKeys[lt.Name] {
+			ltKey := c.DBCache.TableKey(lt.DatabaseSchema, lt.Name)
+			for k, v := range c.DBCache.ForeignKeys[ltKey] {
 				if _, ok := tMap[k]; ok {
-					fkMap[lt.Name] = append(fkMap[lt.Name], v)
+					fkMap[ltKey] = append(fkMap[ltKey], v)
 				}
 			}
 		}
 
 		for _, t := range rTab {
-			if _, ok := tMap[t.Name]; !ok {
-				tMap[t.Name] = t
+			tKey := c.DBCache.TableKey(t.DatabaseSchema, t.Name)
+			if _, ok := tMap[tKey]; !ok {
+				tMap[tKey] = t
 			}
 		}
 	}
@@ -206,8 +210,11 @@ func (c *Completer) joinCandidates(lastTable *parseutil.TableInfo,
 	for k, v := range fkMap {
 		for _, fks := range v {
 			for _, fk := range fks {
-				candidates = append(candidates, generateForeignKeyCandidate(k, tMap, aliases,
-					fk, joinOn, lowercaseKeywords))
+				candidate, ok := generateForeignKeyCandidate(k, tMap, aliases,
+					fk, c.DBCache, joinOn, lowercaseKeywords)
+				if ok {
+					candidates = append(candidates, candidate)
+				}
 			}
 		}
 	}
@@ -215,7 +222,7 @@ func (c *Completer) joinCandidates(lastTable *parseutil.TableInfo,
 }
 
 func resolveTables(t *parseutil.TableInfo, cache *database.DBCache) []*parseutil.TableInfo {
-	if _, ok := cache.ColumnDescs(t.Name); ok {
+	if _, ok := cache.ColumnDatabase(t.DatabaseSchema, t.Name); ok {
 		return []*parseutil.TableInfo{t}
 	}
 	var rv []*parseutil.TableInfo
@@ -250,15 +257,36 @@ func generateForeignKeyCandidate(target string,
 	tMap map[string]*parseutil.TableInfo,
 	aliases map[string]interface{},
 	fk *database.ForeignKey,
-	joinOn, lowercaseKeywords bool) lsp.CompletionItem {
+	dbCache *database.DBCache,
+	joinOn, lowercaseKeywords bool) (lsp.CompletionItem, bool) {
+	// target is a table key, so look up the table itself on the foreign key.
+	var targetTable *database.ColumnBase
+	for _, cur := range (*fk)[0] {
+		if dbCache.TableKey(cur.Schema, cur.Table) == target {
+			targetTable = cur
+			break
+		}
+	}
+	if targetTable == nil {
+		return lsp.CompletionItem{}, false
+	}
+	targetName := targetTable.Table
+	if !strings.EqualFold(targetTable.Schema, dbCache.DefaultSchema()) {
+		targetName = targetTable.Schema + "." + targetTable.Table
+	}
+
 	var tAlias string
 	if joinOn {
-		tAlias = tMap[target].Alias
+		t, ok := tMap[target]
+		if !ok {
+			return lsp.CompletionItem{}, false
+		}
+		tAlias = t.Alias
 		if tAlias == "" {
-			tAlias = tMap[target].Name
+			tAlias = t.Name
 		}
 	} else {
-		tAlias = generateTableAlias(target, aliases)
+		tAlias = generateTableAlias(targetTable.Table, aliases)
 	}
 	builder := []struct {
 		sb    *strings.Builder
@@ -280,7 +308,7 @@ func generateForeignKeyCandidate(target string,
 			onKw = "on"
 		}
 		for _, b := range builder {
-			fmt.Fprintf(b.sb, "%s %s %s ", target, b.alias, onKw)
+			fmt.Fprintf(b.sb, "%s %s %s ", targetName, b.alias, onKw)
 		}
 	}
 	andKw := " AND "
@@ -290,7 +318,7 @@ func generateForeignKeyCandidate(target string,
 	prefix := ""
 	for _, cur := range *fk {
 		tIdx, rIdx := 0, 1
-		if cur[rIdx].Table == target {
+		if dbCache.TableKey(cur[rIdx].Schema, cur[rIdx].Table) == target {
 			tIdx, rIdx = rIdx, tIdx
 		}
 		for _, b := range builder {
@@ -301,7 +329,10 @@ func generateForeignKeyCandidate(target string,
 			b.sb.WriteString(strings.Join([]string{b.alias, cur[tIdx].Name}, "."))
 			b.sb.WriteString(" = ")
 		}
-		rAlias := tMap[cur[rIdx].Table].Alias
+		var rAlias string
+		if t, ok := tMap[dbCache.TableKey(cur[rIdx].Schema, cur[rIdx].Table)]; ok {
+			rAlias = t.Alias
+		}
 		if rAlias == "" {
 			rAlias = cur[rIdx].Table
 		}
@@ -316,7 +347,7 @@ func generateForeignKeyCandidate(target string,
 		Detail:           "Join generator for foreign key",
 		InsertText:       builder[1].sb.String(),
 		InsertTextFormat: lsp.SnippetTextFormat,
-	}
+	}, true
 }
 
 func generateTableCandidates(tables []string, dbCache *database.DBCache) []lsp.CompletionItem {
@@ -353,7 +384,7 @@ func generateTableCandidatesByInfos(tables []*parseutil.TableInfo, dbCache *data
 			Kind:   lsp.ClassCompletion,
 			Detail: detail,
 		}
-		cols, ok := dbCache.ColumnDescs(table.Name)
+		cols, ok := dbCache.ColumnDatabase(table.DatabaseSchema, table.Name)
 		if ok {
 			candidate.Documentation = &lsp.MarkupContent{
 				Kind:  lsp.Markdown,
@@ -388,7 +419,7 @@ func (c *Completer) SubQueryColumnCandidates(infos []*parseutil.SubQueryInfo) []
 		for _, view := range info.Views {
 			for _, col := range view.SubQueryColumns {
 				if col.ColumnName == "*" {
-					tableCols, ok := c.DBCache.ColumnDescs(col.ParentTable.Name)
+					tableCols, ok := c.DBCache.ColumnDatabase(col.ParentTable.DatabaseSchema, col.ParentTable.Name)
 					if !ok {
 						continue
 					}
@@ -405,10 +436,14 @@ func (c *Completer) SubQueryColumnCandidates(infos []*parseutil.SubQueryInfo) []
 						candidates = append(candidates, candidate)
 					}
 				} else {
+					detail := subQueryColumnDetail(info.Name)
+					if col.Expr != nil {
+						detail = withInferredType(detail, database.InferType(col.Expr, col.Tables, c.DBCache))
+					}
 					candidate := lsp.CompletionItem{
 						Label:  col.DisplayName(),
 						Kind:   lsp.FieldCompletion,
-						Detail: subQueryColumnDetail(info.Name),
+						Detail: detail,
 						Documentation: &lsp.MarkupContent{
 							Kind:  lsp.Markdown,
 							Value: database.SubqueryColumnDoc(col.DisplayName(), info.Views, c.DBCache),
@@ -433,6 +468,78 @@ func subQueryColumnDetail(subQueryAliasName string) string {
 	return detail
 }
 
+// withInferredType appends the type inferred for a derived column to the
+// detail of its candidate.
+func withInferredType(detail, typ string) string {
+	if typ == "" {
+		return detail
+	}
+	return detail + ": " + typ
+}
+
+func (c *Completer) cteCandidates(ctes []*parseutil.CTEInfo, parent *completionParent) []lsp.CompletionItem {
+	candidates := []lsp.CompletionItem{}
+	if parent.Type != ParentTypeNone {
+		return candidates
+	}
+	for _, cte := range ctes {
+		candidate := lsp.CompletionItem{
+			Label:  cte.Name,
+			Kind:   lsp.ClassCompletion,
+			Detail: "common table expression",
+			Documentation: &lsp.MarkupContent{
+				Kind:  lsp.Markdown,
+				Value: database.CTEDoc(cte, c.DBCache),
+			},
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// cteColumnCandidates returns the columns of the CTEs read by the statement,
+// or of the one parent names, either directly or by a table alias.
+func (c *Completer) cteColumnCandidates(ctes []*parseutil.CTEInfo, targetTables []*parseutil.TableInfo, parent *completionParent) []lsp.CompletionItem {
+	candidates := []lsp.CompletionItem{}
+	for _, cte := range ctes {
+		referenced := false
+		for _, table := range targetTables {
+			if table.DatabaseSchema != "" || !strings.EqualFold(table.Name, cte.Name) {
+				continue
+			}
+			switch parent.Type {
+			case ParentTypeNone:
+				referenced = true
+			case ParentTypeTable:
+				if parent.Schema == "" && (strings.EqualFold(table.Name, parent.Name) || table.Alias == parent.Name) {
+					referenced = true
+				}
+			}
+		}
+		if !referenced {
+			continue
+		}
+		for _, col := range database.CTEColumnDescs(cte, c.DBCache) {
+			detail := columnDetail(cte.Name)
+			if col.Table == cte.Name {
+				// derived columns belong to the CTE rather than to a table
+				detail = withInferredType(detail, col.Type)
+			}
+			candidate := lsp.CompletionItem{
+				Label:  col.Name,
+				Kind:   lsp.FieldCompletion,
+				Detail: detail,
+				Documentation: &lsp.MarkupContent{
+					Kind:  lsp.Markdown,
+					Value: database.ColumnDoc(cte.Name, col),
+				},
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
 func (c *Completer) SchemaCandidates() []lsp.CompletionItem {
 	candidates := []lsp.CompletionItem{}
 	dbs := c.DBCache.SortedSchemas()
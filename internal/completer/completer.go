@@ -90,7 +90,7 @@ func completionTypeIs(completionTypes []completionType, expect completionType) b
 }
 
 func (c *Completer) Complete(text string, params lsp.CompletionParams, lowercaseKeywords bool) ([]lsp.CompletionItem, error) {
-	parsed, err := parser.Parse(text)
+	parsed, err := parser.ParseFor(text, c.Driver)
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +114,23 @@ func (c *Completer) Complete(text string, params lsp.CompletionParams, lowercase
 	if err != nil {
 		return nil, err
 	}
+	definedCTEs, err := parseutil.ExtractCTEs(parsed, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.DBCache != nil {
+		beforeCursorText := getBeforeCursorText(text, params.Position.Line+1, params.Position.Character)
+		if target, ok := getEnumCompletionTarget(beforeCursorText); ok {
+			items := c.enumCandidates(target, definedTables, params.Position)
+			// Nothing else makes sense inside a string literal, but an
+			// unquoted "col IN (" may still be followed by a subquery.
+			if target.quoted || len(items) > 0 {
+				populateSortText(items)
+				return items, nil
+			}
+		}
+	}
 
 	lastWord := getLastWord(text, params.Position.Line+1, params.Position.Character)
 	withBackQuote := strings.HasPrefix(lastWord, "`")
@@ -123,6 +140,7 @@ func (c *Completer) Complete(text string, params lsp.CompletionParams, lowercase
 	if c.DBCache != nil {
 		if completionTypeIs(ctx.types, CompletionTypeColumn) {
 			candidates := c.columnCandidates(definedTables, ctx.parent)
+			candidates = append(candidates, c.cteColumnCandidates(definedCTEs, definedTables, ctx.parent)...)
 			if withBackQuote {
 				candidates = toQuotedCandidates(candidates)
 			}
@@ -141,6 +159,7 @@ func (c *Completer) Complete(text string, params lsp.CompletionParams, lowercase
 				excl = nil
 			}
 			candidates := c.TableCandidates(ctx.parent, excl)
+			candidates = append(candidates, c.cteCandidates(definedCTEs, ctx.parent)...)
 			if withBackQuote {
 				candidates = toQuotedCandidates(candidates)
 			}
@@ -261,6 +280,8 @@ const (
 type completionParent struct {
 	Type ParentType
 	Name string
+	// Schema qualifies a table parent, as in "schema.table.column".
+	Schema string
 }
 
 var noneParent = &completionParent{Type: ParentTypeNone}
@@ -289,8 +310,9 @@ func getCompletionTypes(nw *parseutil.NodeWalker) *CompletionContext {
 				CompletionTypeView,
 			}
 			p = &completionParent{
-				Type: ParentTypeTable,
-				Name: mi.Parent.String(),
+				Type:   ParentTypeTable,
+				Name:   mi.Parent.String(),
+				Schema: mi.QualifierName(),
 			}
 		} else {
 			t = []completionType{
@@ -316,8 +338,9 @@ func getCompletionTypes(nw *parseutil.NodeWalker) *CompletionContext {
 				CompletionTypeSubQueryColumn,
 			}
 			p = &completionParent{
-				Type: ParentTypeTable,
-				Name: mi.ParentTok.NoQuoteString(),
+				Type:   ParentTypeTable,
+				Name:   mi.ParentTok.NoQuoteString(),
+				Schema: mi.QualifierName(),
 			}
 		} else {
 			t = []completionType{
@@ -362,8 +385,9 @@ func getCompletionTypes(nw *parseutil.NodeWalker) *CompletionContext {
 				CompletionTypeSubQueryColumn,
 			}
 			p = &completionParent{
-				Type: ParentTypeTable,
-				Name: mi.ParentTok.NoQuoteString(),
+				Type:   ParentTypeTable,
+				Name:   mi.ParentTok.NoQuoteString(),
+				Schema: mi.QualifierName(),
 			}
 		} else {
 			t = []completionType{
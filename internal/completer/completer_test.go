@@ -4,7 +4,9 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/sqls-server/sqls/internal/database"
 	"github.com/sqls-server/sqls/internal/lsp"
+	"github.com/sqls-server/sqls/parser/parseutil"
 )
 
 func TestGetBeforeCursorText(t *testing.T) {
@@ -204,3 +206,26 @@ func TestGenerateAlias(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateForeignKeyCandidate(t *testing.T) {
+	dbCache := &database.DBCache{}
+	fk := &database.ForeignKey{
+		{
+			{Schema: "world", Table: "city", Name: "CountryCode"},
+			{Schema: "world", Table: "country", Name: "Code"},
+		},
+	}
+	tMap := map[string]*parseutil.TableInfo{
+		dbCache.TableKey("world", "country"): {DatabaseSchema: "world", Name: "country"},
+	}
+
+	if _, ok := generateForeignKeyCandidate(dbCache.TableKey("world", "city"), tMap, map[string]interface{}{}, fk, dbCache, false, false); !ok {
+		t.Error("no candidate for a table of the foreign key")
+	}
+	if _, ok := generateForeignKeyCandidate(dbCache.TableKey("world", "town"), tMap, map[string]interface{}{}, fk, dbCache, false, false); ok {
+		t.Error("candidate for a table which is not on the foreign key")
+	}
+	if _, ok := generateForeignKeyCandidate(dbCache.TableKey("world", "city"), tMap, map[string]interface{}{}, fk, dbCache, true, false); ok {
+		t.Error("join on candidate for a table which is not in the statement")
+	}
+}
@@ -0,0 +1,101 @@
+package completer
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/sqls-server/sqls/internal/lsp"
+	"github.com/sqls-server/sqls/parser/parseutil"
+)
+
+const columnRefPattern = "((?:[\\w$]+|\"[^\"]+\"|`[^`]+`)(?:\\.(?:[\\w$]+|\"[^\"]+\"|`[^`]+`)){0,2})"
+
+var (
+	// col = 'pre
+	enumComparePattern = regexp.MustCompile(columnRefPattern + `\s*(?:=|<>|!=)\s*'([^'\n]*)$`)
+	// col IN ('a', pre  or  col IN ('a', 'pre
+	enumInPattern  = regexp.MustCompile(columnRefPattern + `\s+(?i:not\s+)?(?i:in)\s*\((?:\s*'(?:[^']|'')*'\s*,)*\s*('?)([^',)\s]*)$`)
+	columnRefParts = regexp.MustCompile("\"[^\"]+\"|`[^`]+`|[^.]+")
+)
+
+type enumCompletionTarget struct {
+	ref    []string
+	prefix string
+	quoted bool
+}
+
+// getEnumCompletionTarget reports whether the cursor is at a value compared
+// against a column, such as after "col = '" or "col IN (".
+func getEnumCompletionTarget(beforeCursorText string) (*enumCompletionTarget, bool) {
+	if m := enumComparePattern.FindStringSubmatch(beforeCursorText); m != nil {
+		return &enumCompletionTarget{
+			ref:    splitColumnRef(m[1]),
+			prefix: m[2],
+			quoted: true,
+		}, true
+	}
+	if m := enumInPattern.FindStringSubmatch(beforeCursorText); m != nil {
+		return &enumCompletionTarget{
+			ref:    splitColumnRef(m[1]),
+			prefix: m[3],
+			quoted: m[2] != "",
+		}, true
+	}
+	return nil, false
+}
+
+func splitColumnRef(ref string) []string {
+	parts := columnRefParts.FindAllString(ref, -1)
+	for i, p := range parts {
+		parts[i] = strings.Trim(p, "\"`")
+	}
+	return parts
+}
+
+func (c *Completer) enumCandidates(target *enumCompletionTarget, tables []*parseutil.TableInfo, pos lsp.Position) []lsp.CompletionItem {
+	candidates := []lsp.CompletionItem{}
+	col, ok := c.DBCache.FindColumn(tables, target.ref...)
+	if !ok {
+		return candidates
+	}
+	values, ok := c.DBCache.EnumValues(col)
+	if !ok {
+		return candidates
+	}
+
+	// Replace what has been typed of the value, which may contain spaces.
+	editRange := lsp.Range{
+		Start: lsp.Position{
+			Line:      pos.Line,
+			Character: pos.Character - len(utf16.Encode([]rune(target.prefix))),
+		},
+		End: pos,
+	}
+	if target.quoted {
+		editRange.Start.Character--
+	}
+	for _, value := range values {
+		if !strings.HasPrefix(strings.ToUpper(value), strings.ToUpper(target.prefix)) {
+			continue
+		}
+		candidate := lsp.CompletionItem{
+			Label:  value,
+			Kind:   lsp.EnumMemberCompletion,
+			Detail: enumDetail(col.Table, col.Name),
+			TextEdit: &lsp.TextEdit{
+				Range:   editRange,
+				NewText: "'" + strings.ReplaceAll(value, "'", "''") + "'",
+			},
+		}
+		if target.quoted {
+			candidate.FilterText = "'" + value
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+func enumDetail(tableName, colName string) string {
+	return "enum value of \"" + tableName + "." + colName + "\""
+}
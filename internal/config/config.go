@@ -17,11 +17,19 @@ var (
 )
 
 var (
-	YamlConfigPath = configFilePath("config.yml")
+	YamlConfigPath  = configFilePath("config.yml")
+	HistoryFilePath = configFilePath("history.jsonl")
 )
 
+// DefaultRowLimit is the number of rows a query result shows when RowLimit
+// is not set.
+const DefaultRowLimit = 1000
+
 type Config struct {
 	LowercaseKeywords bool                 `json:"lowercaseKeywords" yaml:"lowercaseKeywords"`
+	RowLimit          int                  `json:"rowLimit" yaml:"rowLimit"`
+	AutoLimit         bool                 `json:"autoLimit" yaml:"autoLimit"`
+	Variables         map[string]string    `json:"variables" yaml:"variables"`
 	Connections       []*database.DBConfig `json:"connections" yaml:"connections"`
 }
 
@@ -32,6 +40,18 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// MaxRows returns the number of rows a query result shows at a time, or 0
+// when a negative RowLimit disables the limit.
+func (c *Config) MaxRows() int {
+	switch {
+	case c.RowLimit < 0:
+		return 0
+	case c.RowLimit == 0:
+		return DefaultRowLimit
+	}
+	return c.RowLimit
+}
+
 func NewConfig() *Config {
 	cfg := &Config{}
 	cfg.LowercaseKeywords = false
@@ -0,0 +1,350 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sqls-server/sqls/dialect"
+)
+
+// Placeholder is a bind parameter of a query. Positional placeholders, "?"
+// and "$1", are named by their number, others by their name without the
+// prefix. Start and End are byte offsets into the query.
+type Placeholder struct {
+	Text       string
+	Name       string
+	Positional bool
+	Start      int
+	End        int
+}
+
+// ExtractPlaceholders finds the bind parameters of query, skipping string
+// literals, quoted identifiers and comments. "?" is an operator in
+// PostgreSQL, "@name" a variable except in SQL Server and SQLite, and
+// ":name" only a parameter in Oracle and SQLite, so those are only taken as
+// placeholders where they are not. The bodies of routines, triggers and
+// PL/SQL blocks refer to parameters and variables of their own, so they
+// have none.
+func ExtractPlaceholders(driver dialect.DatabaseDriver, query string) []*Placeholder {
+	if isBlock(driver, query) {
+		return nil
+	}
+	questionMark := driver != dialect.DatabaseDriverPostgreSQL
+	atSign := driver == dialect.DatabaseDriverMssql || driver == dialect.DatabaseDriverSQLite3
+	colon := driver == dialect.DatabaseDriverOracle || driver == dialect.DatabaseDriverSQLite3
+	declared := declaredVariables(query)
+
+	var res []*Placeholder
+	positional := 0
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i, c)
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			i = skipLine(query, i)
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipBlockComment(query, i)
+		case c == '?' && questionMark:
+			positional++
+			res = append(res, &Placeholder{Text: "?", Name: strconv.Itoa(positional), Positional: true, Start: i, End: i + 1})
+			i++
+		case c == '$':
+			if end := scanDigits(query, i+1); end > i+1 {
+				res = append(res, &Placeholder{Text: query[i:end], Name: query[i+1 : end], Positional: true, Start: i, End: end})
+				i = end
+			} else if tag, ok := dollarQuoteTag(query, i); ok {
+				if end := strings.Index(query[i+len(tag):], tag); end >= 0 {
+					i += len(tag) + end + len(tag)
+				} else {
+					i = len(query)
+				}
+			} else {
+				i++
+			}
+		case c == ':' && colon:
+			if strings.HasPrefix(query[i:], "::") {
+				i += 2
+				continue
+			}
+			if end := scanName(query, i+1); end > i+1 && !precededByWord(query, i) {
+				res = append(res, &Placeholder{Text: query[i:end], Name: query[i+1 : end], Start: i, End: end})
+				i = end
+				continue
+			}
+			i++
+		case c == '@' && atSign:
+			if strings.HasPrefix(query[i:], "@@") {
+				i = scanName(query, i+2)
+				continue
+			}
+			end := scanName(query, i+1)
+			if end > i+1 && !declared[strings.ToLower(query[i+1:end])] {
+				res = append(res, &Placeholder{Text: query[i:end], Name: query[i+1 : end], Start: i, End: end})
+			}
+			i = max(end, i+1)
+		default:
+			i++
+		}
+	}
+	return res
+}
+
+// routineKeywords are the objects whose definition holds a body of
+// statements.
+var routineKeywords = map[string]bool{
+	"PROCEDURE": true,
+	"PROC":      true,
+	"FUNCTION":  true,
+	"TRIGGER":   true,
+	"PACKAGE":   true,
+	"TYPE":      true,
+}
+
+// isBlock reports whether query defines a routine or trigger, or is a
+// PL/SQL block.
+func isBlock(driver dialect.DatabaseDriver, query string) bool {
+	words := leadingWords(query, 6)
+	if len(words) == 0 {
+		return false
+	}
+	if driver == dialect.DatabaseDriverOracle && (words[0] == "DECLARE" || words[0] == "BEGIN") {
+		return true
+	}
+	if words[0] != "CREATE" && words[0] != "ALTER" {
+		return false
+	}
+	for _, w := range words[1:] {
+		switch w {
+		case "OR", "REPLACE", "ALTER", "EDITIONABLE", "NONEDITIONABLE":
+			continue
+		}
+		return routineKeywords[w]
+	}
+	return false
+}
+
+var declarePattern = regexp.MustCompile(`(?i)\bdeclare\b`)
+
+// declaredVariables returns the variables declared with DECLARE, which are
+// not bind parameters in SQL Server.
+func declaredVariables(query string) map[string]bool {
+	declared := map[string]bool{}
+	for _, loc := range declarePattern.FindAllStringIndex(query, -1) {
+		for _, name := range declaredNames(query[loc[1]:]) {
+			declared[strings.ToLower(name)] = true
+		}
+	}
+	return declared
+}
+
+// declaredNames returns the variables of the DECLARE statement at the start
+// of s, as in "@a int = 1, @b int = 2". The statement ends with a semicolon
+// or with a line which neither ends with nor continues after a comma.
+func declaredNames(s string) []string {
+	var names []string
+	depth := 0
+	expectName := true
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(s, i, c)
+			expectName = false
+			continue
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth > 0:
+		case c == ';':
+			return names
+		case c == '\n' && !continuedLine(s, i):
+			return names
+		case c == ',':
+			expectName = true
+		case c == '@' && expectName:
+			end := scanName(s, i+1)
+			names = append(names, s[i+1:end])
+			expectName = false
+			i = max(end, i+1)
+			continue
+		}
+		if !unicode.IsSpace(rune(c)) && c != ',' {
+			expectName = false
+		}
+		i++
+	}
+	return names
+}
+
+// continuedLine reports whether the line break at i is within a list, next
+// to a comma.
+func continuedLine(s string, i int) bool {
+	before := strings.TrimRightFunc(s[:i], unicode.IsSpace)
+	after := strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+	return strings.HasSuffix(before, ",") || strings.HasPrefix(after, ",")
+}
+
+func skipQuoted(query string, i int, quote byte) int {
+	for j := i + 1; j < len(query); j++ {
+		if query[j] == quote {
+			// A doubled quote is an escaped quote.
+			if j+1 < len(query) && query[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+func skipLine(query string, i int) int {
+	if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+		return i + end + 1
+	}
+	return len(query)
+}
+
+func scanDigits(query string, i int) int {
+	for i < len(query) && query[i] >= '0' && query[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+func scanName(query string, i int) int {
+	start := i
+	for i < len(query) {
+		r := rune(query[i])
+		if r == '_' || unicode.IsLetter(r) || (i > start && unicode.IsDigit(r)) {
+			i++
+			continue
+		}
+		break
+	}
+	return i
+}
+
+func precededByWord(query string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	r := rune(query[i-1])
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// dollarQuoteTag returns the opening tag of a PostgreSQL dollar-quoted
+// string, such as "$$" or "$body$", starting at i.
+func dollarQuoteTag(query string, i int) (string, bool) {
+	end := scanName(query, i+1)
+	if end < len(query) && query[end] == '$' {
+		return query[i : end+1], true
+	}
+	return "", false
+}
+
+// BindQuery rewrites the placeholders of query into the form the driver
+// expects and returns the arguments to bind, taking the value of each
+// placeholder from values by its name, ignoring case.
+func BindQuery(driver dialect.DatabaseDriver, query string, placeholders []*Placeholder, values map[string]interface{}) (string, []interface{}, error) {
+	lookup := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		lookup[strings.ToLower(k)] = v
+	}
+
+	var b strings.Builder
+	var args []interface{}
+	// Index of each parameter among the distinct ones, for drivers which
+	// refer to a parameter more than once.
+	index := map[string]int{}
+	last := 0
+	for _, p := range placeholders {
+		key := strings.ToLower(p.Name)
+		val, ok := lookup[key]
+		if !ok {
+			return "", nil, fmt.Errorf("no value for bind parameter %s", p.Text)
+		}
+		b.WriteString(query[last:p.Start])
+		last = p.End
+
+		switch driver {
+		case dialect.DatabaseDriverPostgreSQL:
+			n, seen := index[key]
+			if !seen {
+				args = append(args, val)
+				n = len(args)
+				index[key] = n
+			}
+			fmt.Fprintf(&b, "$%d", n)
+		case dialect.DatabaseDriverMssql, dialect.DatabaseDriverOracle:
+			name := bindName(p)
+			if _, seen := index[key]; !seen {
+				args = append(args, sql.Named(name, val))
+				index[key] = len(args)
+			}
+			if driver == dialect.DatabaseDriverMssql {
+				b.WriteString("@" + name)
+			} else {
+				b.WriteString(":" + name)
+			}
+		default:
+			args = append(args, val)
+			b.WriteString("?")
+		}
+	}
+	b.WriteString(query[last:])
+	return b.String(), args, nil
+}
+
+// bindName is the name a placeholder is bound by for drivers which take
+// named arguments.
+func bindName(p *Placeholder) string {
+	if p.Positional {
+		return "p" + p.Name
+	}
+	return p.Name
+}
+
+var paramCommentPattern = regexp.MustCompile(`(?m)^[ \t]*--[ \t]*@param[ \t]+[:@$]?(\w+)[ \t]*=[ \t]*(.*?)[ \t]*\r?$`)
+
+// ParamComments reads the values of bind parameters from comments of the
+// form "-- @param name = value" in text. Positional parameters are named by
+// their number, as in "-- @param 1 = 42".
+func ParamComments(text string) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, m := range paramCommentPattern.FindAllStringSubmatch(text, -1) {
+		values[strings.ToLower(m[1])] = ParseParamValue(m[2])
+	}
+	return values
+}
+
+// ParseParamValue reads a parameter value written as a SQL literal: a
+// quoted string, NULL, TRUE or FALSE, or a number. Anything else is taken
+// as a string as is.
+func ParseParamValue(s string) interface{} {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	switch strings.ToUpper(s) {
+	case "NULL":
+		return nil
+	case "TRUE":
+		return true
+	case "FALSE":
+		return false
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
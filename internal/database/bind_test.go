@@ -0,0 +1,200 @@
+package database
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sqls-server/sqls/dialect"
+)
+
+func TestExtractPlaceholders(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver dialect.DatabaseDriver
+		query  string
+		want   []string
+	}{
+		{
+			name:   "question marks",
+			driver: dialect.DatabaseDriverMySQL,
+			query:  "SELECT * FROM city WHERE ID = ? AND Name = '?' AND CountryCode = ? -- ?",
+			want:   []string{"? 1", "? 2"},
+		},
+		{
+			name:   "dollar numbers",
+			driver: dialect.DatabaseDriverPostgreSQL,
+			query:  "SELECT * FROM city WHERE id = $1 AND name <> $2 AND tags ? 'a' OR id = $1",
+			want:   []string{"$1 1", "$2 2", "$1 1"},
+		},
+		{
+			name:   "dollar quoted string",
+			driver: dialect.DatabaseDriverPostgreSQL,
+			query:  "SELECT $body$ $1 $body$, $$ :x $$, $1",
+			want:   []string{"$1 1"},
+		},
+		{
+			name:   "colon names",
+			driver: dialect.DatabaseDriverOracle,
+			query:  "SELECT * FROM city WHERE id = :id AND created::date = :day /* :no */",
+			want:   []string{":id id", ":day day"},
+		},
+		{
+			name:   "at names",
+			driver: dialect.DatabaseDriverMssql,
+			query:  "DECLARE @n int; SELECT @@VERSION, @n, [ID] FROM city WHERE Name = @name",
+			want:   []string{"@name name"},
+		},
+		{
+			name:   "at names are variables in mysql",
+			driver: dialect.DatabaseDriverMySQL,
+			query:  "SELECT @name",
+			want:   nil,
+		},
+		{
+			name:   "colon names are not parameters in mysql",
+			driver: dialect.DatabaseDriverMySQL,
+			query:  "SELECT * FROM city WHERE ID = :id",
+			want:   nil,
+		},
+		{
+			name:   "declare list",
+			driver: dialect.DatabaseDriverMssql,
+			query:  "DECLARE @a int = 1, @b int = 2;\nSELECT @a, @b, @c",
+			want:   []string{"@c c"},
+		},
+		{
+			name:   "declare list over lines",
+			driver: dialect.DatabaseDriverMssql,
+			query:  "DECLARE @a int = 1,\n  @b varchar(10) = 'x, @d'\nSELECT @a, @b, @c",
+			want:   []string{"@c c"},
+		},
+		{
+			name:   "procedure parameters",
+			driver: dialect.DatabaseDriverMssql,
+			query:  "CREATE PROCEDURE dbo.p @id int AS SELECT * FROM city WHERE ID = @id",
+			want:   nil,
+		},
+		{
+			name:   "trigger body",
+			driver: dialect.DatabaseDriverOracle,
+			query:  "-- audit\nCREATE OR REPLACE TRIGGER city_bi BEFORE INSERT ON city FOR EACH ROW BEGIN :NEW.ID := 1; END;",
+			want:   nil,
+		},
+		{
+			name:   "pl/sql block",
+			driver: dialect.DatabaseDriverOracle,
+			query:  "BEGIN UPDATE city SET Name = :name; END;",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range ExtractPlaceholders(tt.driver, tt.query) {
+				if tt.query[p.Start:p.End] != p.Text {
+					t.Errorf("placeholder %q at %d:%d is %q", p.Text, p.Start, p.End, tt.query[p.Start:p.End])
+				}
+				got = append(got, p.Text+" "+p.Name)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unmatch placeholders (- want, + got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	values := map[string]interface{}{"id": int64(1), "Name": "Kabul"}
+	tests := []struct {
+		name      string
+		driver    dialect.DatabaseDriver
+		query     string
+		values    map[string]interface{}
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "mysql",
+			driver:    dialect.DatabaseDriverMySQL,
+			query:     "SELECT * FROM city WHERE ID = ? OR Name = ?",
+			values:    map[string]interface{}{"1": int64(1), "2": "Kabul"},
+			wantQuery: "SELECT * FROM city WHERE ID = ? OR Name = ?",
+			wantArgs:  []interface{}{int64(1), "Kabul"},
+		},
+		{
+			name:      "postgresql",
+			driver:    dialect.DatabaseDriverPostgreSQL,
+			query:     "SELECT * FROM city WHERE id = $2 OR name = $1 OR id = $2",
+			values:    map[string]interface{}{"1": "Kabul", "2": int64(1)},
+			wantQuery: "SELECT * FROM city WHERE id = $1 OR name = $2 OR id = $1",
+			wantArgs:  []interface{}{int64(1), "Kabul"},
+		},
+		{
+			name:      "mssql",
+			values:    values,
+			driver:    dialect.DatabaseDriverMssql,
+			query:     "SELECT * FROM city WHERE ID = @id OR Name = @name",
+			wantQuery: "SELECT * FROM city WHERE ID = @id OR Name = @name",
+			wantArgs:  []interface{}{sql.Named("id", int64(1)), sql.Named("name", "Kabul")},
+		},
+		{
+			name:      "oracle",
+			values:    values,
+			driver:    dialect.DatabaseDriverOracle,
+			query:     "SELECT * FROM city WHERE ID = :id OR Name = :name",
+			wantQuery: "SELECT * FROM city WHERE ID = :id OR Name = :name",
+			wantArgs:  []interface{}{sql.Named("id", int64(1)), sql.Named("name", "Kabul")},
+		},
+		{
+			name:      "oracle positional",
+			values:    map[string]interface{}{"1": "Kabul"},
+			driver:    dialect.DatabaseDriverOracle,
+			query:     "SELECT * FROM city WHERE ID = ?",
+			wantQuery: "SELECT * FROM city WHERE ID = :p1",
+			wantArgs:  []interface{}{sql.Named("p1", "Kabul")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQuery, gotArgs, err := BindQuery(tt.driver, tt.query, ExtractPlaceholders(tt.driver, tt.query), tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotQuery != tt.wantQuery {
+				t.Errorf("query = %q, want %q", gotQuery, tt.wantQuery)
+			}
+			// sql.NamedArg has an unexported field, which cmp cannot compare.
+			if !reflect.DeepEqual(tt.wantArgs, gotArgs) {
+				t.Errorf("args = %#v, want %#v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+
+	query := "SELECT * FROM city WHERE ID = ?"
+	if _, _, err := BindQuery(dialect.DatabaseDriverMySQL, query, ExtractPlaceholders(dialect.DatabaseDriverMySQL, query), nil); err == nil {
+		t.Error("expected error for a missing value")
+	}
+}
+
+func TestParamComments(t *testing.T) {
+	text := `-- @param id = 42
+--@param :name = 'O''Brien'
+-- @param 1 = NULL
+-- @param ratio = 1.5
+-- @param flag = true
+-- @param code = NLD
+SELECT 1`
+	want := map[string]interface{}{
+		"id":    int64(42),
+		"name":  "O'Brien",
+		"1":     nil,
+		"ratio": 1.5,
+		"flag":  true,
+		"code":  "NLD",
+	}
+	if diff := cmp.Diff(want, ParamComments(text)); diff != "" {
+		t.Errorf("unmatch values (- want, + got):\n%s", diff)
+	}
+}
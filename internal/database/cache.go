@@ -4,10 +4,16 @@ import (
 	"context"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/sqls-server/sqls/parser/parseutil"
 )
 
 type DBCacheGenerator struct {
 	repo DBRepository
+	// timeout bounds each Generate call, so that a hung schema query
+	// cannot hold up the worker. 0 means no limit.
+	timeout time.Duration
 }
 
 func NewDBCacheUpdater(repo DBRepository) *DBCacheGenerator {
@@ -16,7 +22,16 @@ func NewDBCacheUpdater(repo DBRepository) *DBCacheGenerator {
 	}
 }
 
+func (u *DBCacheGenerator) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if u.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, u.timeout)
+}
+
 func (u *DBCacheGenerator) GenerateDBCachePrimary(ctx context.Context) (*DBCache, error) {
+	ctx, cancel := u.withTimeout(ctx)
+	defer cancel()
 	var err error
 	dbCache := &DBCache{}
 	dbCache.defaultSchema, err = u.repo.CurrentSchema(ctx)
@@ -49,11 +64,26 @@ func (u *DBCacheGenerator) GenerateDBCachePrimary(ctx context.Context) (*DBCache
 		dbCache.SchemaTables[strings.ToUpper(index)] = element
 	}
 
-	dbCache.ColumnsWithParent, err = u.genColumnCacheCurrent(ctx, dbCache.defaultSchema)
+	dbCache.searchPath, err = u.genSearchPath(ctx, dbCache.defaultSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	dbCache.ColumnsWithParent = make(map[string][]*ColumnDesc)
+	for _, schemaName := range dbCache.searchPath {
+		columns, err := u.genColumnCacheCurrent(ctx, schemaName)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range columns {
+			dbCache.ColumnsWithParent[k] = v
+		}
+	}
+	dbCache.ForeignKeys, err = u.genForeignKeysCache(ctx, []string{dbCache.defaultSchema})
 	if err != nil {
 		return nil, err
 	}
-	dbCache.ForeignKeys, err = u.genForeignKeysCache(ctx, dbCache.defaultSchema)
+	dbCache.EnumTypes, err = u.genEnumTypeCache(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -61,9 +91,55 @@ func (u *DBCacheGenerator) GenerateDBCachePrimary(ctx context.Context) (*DBCache
 }
 
 func (u *DBCacheGenerator) GenerateDBCacheSecondary(ctx context.Context) (map[string][]*ColumnDesc, error) {
+	ctx, cancel := u.withTimeout(ctx)
+	defer cancel()
 	return u.genColumnCacheAll(ctx)
 }
 
+// GenerateForeignKeysCacheAll loads the foreign keys of every schema so that
+// references crossing schema boundaries can be resolved.
+func (u *DBCacheGenerator) GenerateForeignKeysCacheAll(ctx context.Context) (map[string]map[string][]*ForeignKey, error) {
+	ctx, cancel := u.withTimeout(ctx)
+	defer cancel()
+	current, err := u.repo.CurrentSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := u.repo.Schemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schemaNames := []string{current}
+	for _, schema := range schemas {
+		if !strings.EqualFold(schema, current) {
+			schemaNames = append(schemaNames, schema)
+		}
+	}
+	return u.genForeignKeysCache(ctx, schemaNames)
+}
+
+// SearchPathRepository is implemented by repositories whose connection
+// resolves unqualified table names through more than the current schema,
+// such as the PostgreSQL search_path.
+type SearchPathRepository interface {
+	SearchPath(ctx context.Context) ([]string, error)
+}
+
+func (u *DBCacheGenerator) genSearchPath(ctx context.Context, defaultSchema string) ([]string, error) {
+	repo, ok := u.repo.(SearchPathRepository)
+	if !ok {
+		return []string{defaultSchema}, nil
+	}
+	schemas, err := repo.SearchPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return []string{defaultSchema}, nil
+	}
+	return schemas, nil
+}
+
 func (u *DBCacheGenerator) genSchemaCache(ctx context.Context) (map[string]string, error) {
 	dbs, err := u.repo.Schemas(ctx)
 	if err != nil {
@@ -92,36 +168,69 @@ func (u *DBCacheGenerator) genColumnCacheAll(ctx context.Context) (map[string][]
 	return genColumnMap(columnDescs), nil
 }
 
-func (u *DBCacheGenerator) genForeignKeysCache(ctx context.Context, schemaName string) (map[string]map[string][]*ForeignKey, error) {
+func (u *DBCacheGenerator) genForeignKeysCache(ctx context.Context, schemaNames []string) (map[string]map[string][]*ForeignKey, error) {
 	retVal := make(map[string]map[string][]*ForeignKey)
-	fk, err := u.repo.DescribeForeignKeysBySchema(ctx, schemaName)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, cur := range fk {
-		elem := (*cur)[0]
-		refs, ok := retVal[elem[0].Table]
-		if !ok {
-			refs = make(map[string][]*ForeignKey)
+	for _, schemaName := range schemaNames {
+		fk, err := u.repo.DescribeForeignKeysBySchema(ctx, schemaName)
+		if err != nil {
+			return nil, err
 		}
-		refs[elem[1].Table] = append(refs[elem[1].Table], cur)
-		retVal[elem[0].Table] = refs
+		for _, cur := range fk {
+			elem := (*cur)[0]
+			lKey := columnDatabaseKey(elem[0].Schema, elem[0].Table)
+			rKey := columnDatabaseKey(elem[1].Schema, elem[1].Table)
 
-		refs, ok = retVal[elem[1].Table]
-		if !ok {
-			refs = make(map[string][]*ForeignKey)
+			refs, ok := retVal[lKey]
+			if !ok {
+				refs = make(map[string][]*ForeignKey)
+			}
+			refs[rKey] = append(refs[rKey], cur)
+			retVal[lKey] = refs
+
+			refs, ok = retVal[rKey]
+			if !ok {
+				refs = make(map[string][]*ForeignKey)
+			}
+			// A self reference is already registered above.
+			if lKey != rKey {
+				refs[lKey] = append(refs[lKey], cur)
+			}
+			retVal[rKey] = refs
 		}
-		refs[elem[0].Table] = append(refs[elem[0].Table], cur)
-		retVal[elem[1].Table] = refs
 	}
 	return retVal, nil
 }
 
+func (u *DBCacheGenerator) genEnumTypeCache(ctx context.Context) (map[string][]string, error) {
+	retVal := make(map[string][]string)
+	repo, ok := u.repo.(EnumTypeRepository)
+	if !ok {
+		return retVal, nil
+	}
+	enums, err := repo.DescribeEnumTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, enum := range enums {
+		retVal[enumTypeKey(enum.Schema, enum.Name)] = enum.Values
+	}
+	return retVal, nil
+}
+
+// genColumnMap groups columns by table. A column described once for each
+// of its constraints is kept once, as a primary key if any says so.
 func genColumnMap(columnDescs []*ColumnDesc) map[string][]*ColumnDesc {
 	columnMap := map[string][]*ColumnDesc{}
+	seen := map[string]*ColumnDesc{}
 	for _, desc := range columnDescs {
 		key := columnDatabaseKey(desc.Schema, desc.Table)
+		if prev, ok := seen[key+"\t"+desc.Name]; ok {
+			if desc.Key == "YES" {
+				prev.Key = desc.Key
+			}
+			continue
+		}
+		seen[key+"\t"+desc.Name] = desc
 		columnMap[key] = append(columnMap[key], desc)
 	}
 	return columnMap
@@ -129,10 +238,62 @@ func genColumnMap(columnDescs []*ColumnDesc) map[string][]*ColumnDesc {
 
 type DBCache struct {
 	defaultSchema     string
+	searchPath        []string
 	Schemas           map[string]string
 	SchemaTables      map[string][]string
 	ColumnsWithParent map[string][]*ColumnDesc
-	ForeignKeys       map[string]map[string][]*ForeignKey
+	// ForeignKeys maps a table key to the tables it is related to
+	// through foreign keys, keyed in turn by the related table key.
+	// Keys are built with TableKey.
+	ForeignKeys map[string]map[string][]*ForeignKey
+	// EnumTypes maps a named enum type, as "SCHEMA.NAME", to its labels.
+	EnumTypes map[string][]string
+}
+
+func (dc *DBCache) DefaultSchema() string {
+	return dc.defaultSchema
+}
+
+// SearchPath returns the schemas searched for unqualified table names,
+// in order of precedence.
+func (dc *DBCache) SearchPath() []string {
+	if len(dc.searchPath) == 0 {
+		return []string{dc.defaultSchema}
+	}
+	return dc.searchPath
+}
+
+// ResolveSchema returns the schema of the table that an unqualified table
+// name refers to. When no schema on the search path contains the table,
+// the default schema is returned with ok set to false.
+func (dc *DBCache) ResolveSchema(tableName string) (schemaName string, ok bool) {
+	for _, schemaName := range dc.SearchPath() {
+		if dc.hasTable(schemaName, tableName) {
+			return schemaName, true
+		}
+	}
+	return dc.defaultSchema, false
+}
+
+func (dc *DBCache) hasTable(schemaName, tableName string) bool {
+	if _, ok := dc.ColumnsWithParent[columnDatabaseKey(schemaName, tableName)]; ok {
+		return true
+	}
+	for _, tbl := range dc.SchemaTables[strings.ToUpper(schemaName)] {
+		if strings.EqualFold(tbl, tableName) {
+			return true
+		}
+	}
+	return false
+}
+
+// TableKey returns the key identifying a table within the cache. An empty
+// schema name is resolved through the search path.
+func (dc *DBCache) TableKey(schemaName, tableName string) string {
+	if schemaName == "" {
+		schemaName, _ = dc.ResolveSchema(tableName)
+	}
+	return columnDatabaseKey(schemaName, tableName)
 }
 
 func (dc *DBCache) Database(dbName string) (db string, ok bool) {
@@ -155,23 +316,42 @@ func (dc *DBCache) SortedTablesByDBName(dbName string) (tbls []string, ok bool)
 	return
 }
 
+// SortedTables returns the tables visible without a schema qualifier.
 func (dc *DBCache) SortedTables() []string {
-	tbls, _ := dc.SortedTablesByDBName(dc.defaultSchema)
+	seen := map[string]struct{}{}
+	tbls := []string{}
+	for _, schemaName := range dc.SearchPath() {
+		for _, tbl := range dc.SchemaTables[strings.ToUpper(schemaName)] {
+			if _, ok := seen[tbl]; ok {
+				continue
+			}
+			seen[tbl] = struct{}{}
+			tbls = append(tbls, tbl)
+		}
+	}
+	sort.Strings(tbls)
 	return tbls
 }
 
 func (dc *DBCache) ColumnDescs(tableName string) (cols []*ColumnDesc, ok bool) {
-	cols, ok = dc.ColumnsWithParent[columnDatabaseKey(dc.defaultSchema, tableName)]
-	return
+	return dc.ColumnDatabase("", tableName)
 }
 
+// ColumnDatabase returns the columns of tableName in dbName. An empty dbName
+// is resolved through the search path.
 func (dc *DBCache) ColumnDatabase(dbName, tableName string) (cols []*ColumnDesc, ok bool) {
-	cols, ok = dc.ColumnsWithParent[columnDatabaseKey(dbName, tableName)]
+	cols, ok = dc.ColumnsWithParent[dc.TableKey(dbName, tableName)]
 	return
 }
 
 func (dc *DBCache) Column(tableName, colName string) (*ColumnDesc, bool) {
-	cols, ok := dc.ColumnsWithParent[columnDatabaseKey(dc.defaultSchema, tableName)]
+	return dc.ColumnBySchema("", tableName, colName)
+}
+
+// ColumnBySchema returns the column colName of tableName in schemaName. An
+// empty schemaName is resolved through the search path.
+func (dc *DBCache) ColumnBySchema(schemaName, tableName, colName string) (*ColumnDesc, bool) {
+	cols, ok := dc.ColumnDatabase(schemaName, tableName)
 	if !ok {
 		return nil, false
 	}
@@ -183,6 +363,40 @@ func (dc *DBCache) Column(tableName, colName string) (*ColumnDesc, bool) {
 	return nil, false
 }
 
+// FindColumn resolves a column reference, split on dots, against the tables
+// referenced by a statement. The reference may be a bare column name, or be
+// qualified by a table name or alias and optionally a schema.
+func (dc *DBCache) FindColumn(tables []*parseutil.TableInfo, ref ...string) (*ColumnDesc, bool) {
+	switch len(ref) {
+	case 1:
+		for _, table := range tables {
+			if col, ok := dc.ColumnBySchema(table.DatabaseSchema, table.Name, ref[0]); ok {
+				return col, true
+			}
+		}
+	case 2:
+		for _, table := range tables {
+			if table.Alias == ref[0] || (table.Alias == "" && table.Name == ref[0]) {
+				return dc.ColumnBySchema(table.DatabaseSchema, table.Name, ref[1])
+			}
+		}
+		return dc.ColumnBySchema("", ref[0], ref[1])
+	case 3:
+		return dc.ColumnBySchema(ref[0], ref[1], ref[2])
+	}
+	return nil, false
+}
+
+// EnumValues returns the labels allowed for the column when its type is an
+// enum, either inline in the column type or a named enum type.
+func (dc *DBCache) EnumValues(col *ColumnDesc) ([]string, bool) {
+	if values, ok := ParseEnumValues(col.Type); ok {
+		return values, true
+	}
+	values, ok := dc.EnumTypes[strings.ToUpper(col.Type)]
+	return values, ok
+}
+
 func columnDatabaseKey(dbName, tableName string) string {
 	return strings.ToUpper(dbName) + "\t" + strings.ToUpper(tableName)
 }
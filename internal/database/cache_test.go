@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateForeignKeysCacheAll(t *testing.T) {
+	repo := NewMockDBRepository(nil).(*MockDBRepository)
+	crossSchema := &ForeignKey{
+		[2]*ColumnBase{
+			{
+				Schema: "sales",
+				Table:  "orders",
+				Name:   "CityID",
+			},
+			{
+				Schema: "world",
+				Table:  "city",
+				Name:   "ID",
+			},
+		},
+	}
+	repo.MockDatabases = func(ctx context.Context) ([]string, error) {
+		return []string{"sales", "world"}, nil
+	}
+	repo.MockDescribeForeignKeysBySchema = func(ctx context.Context, schemaName string) ([]*ForeignKey, error) {
+		if schemaName == "sales" {
+			return []*ForeignKey{crossSchema}, nil
+		}
+		return nil, nil
+	}
+
+	fks, err := NewDBCacheUpdater(repo).GenerateForeignKeysCacheAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &DBCache{defaultSchema: "world", ForeignKeys: fks}
+
+	tests := []struct {
+		name   string
+		schema string
+		table  string
+		ref    string
+	}{
+		{"owner side", "sales", "orders", cache.TableKey("", "city")},
+		{"referenced side", "", "CITY", cache.TableKey("SALES", "ORDERS")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, ok := cache.ForeignKeys[cache.TableKey(tt.schema, tt.table)]
+			if !ok {
+				t.Fatalf("no foreign keys for %s.%s", tt.schema, tt.table)
+			}
+			if got := refs[tt.ref]; len(got) != 1 || got[0] != crossSchema {
+				t.Errorf("unexpected foreign keys for %q: %v", tt.ref, got)
+			}
+		})
+	}
+}
+
+func TestDBCacheSearchPath(t *testing.T) {
+	cache := &DBCache{
+		defaultSchema: "public",
+		searchPath:    []string{"public", "analytics"},
+		SchemaTables: map[string][]string{
+			"PUBLIC":    {"users"},
+			"ANALYTICS": {"events", "users"},
+		},
+		ColumnsWithParent: map[string][]*ColumnDesc{
+			columnDatabaseKey("public", "users"): {
+				{ColumnBase: ColumnBase{Schema: "public", Table: "users", Name: "id"}},
+			},
+			columnDatabaseKey("analytics", "users"): {
+				{ColumnBase: ColumnBase{Schema: "analytics", Table: "users", Name: "uid"}},
+			},
+			columnDatabaseKey("analytics", "events"): {
+				{ColumnBase: ColumnBase{Schema: "analytics", Table: "events", Name: "user_id"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		schema string
+		table  string
+		column string
+		want   string
+	}{
+		{"unqualified in first schema", "", "users", "id", "public"},
+		{"unqualified shadowed", "", "users", "uid", ""},
+		{"unqualified in later schema", "", "events", "user_id", "analytics"},
+		{"qualified", "analytics", "users", "uid", "analytics"},
+		{"qualified mismatch", "public", "events", "user_id", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col, ok := cache.ColumnBySchema(tt.schema, tt.table, tt.column)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("unexpected column %s.%s", col.Schema, col.Table)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("column %s not found", tt.column)
+			}
+			if col.Schema != tt.want {
+				t.Errorf("got schema %q, want %q", col.Schema, tt.want)
+			}
+		})
+	}
+
+	want := []string{"events", "users"}
+	if got := cache.SortedTables(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortedTables() = %v, want %v", got, want)
+	}
+}
+
+func TestDBCacheGeneratorTimeout(t *testing.T) {
+	repo := NewMockDBRepository(nil).(*MockDBRepository)
+	repo.MockDescribeDatabaseTable = func(ctx context.Context) ([]*ColumnDesc, error) {
+		// A schema query which hangs until it is cancelled.
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	generator := NewDBCacheUpdater(repo)
+	generator.timeout = 10 * time.Millisecond
+	_, err := generator.GenerateDBCacheSecondary(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GenerateDBCacheSecondary() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestGenColumnMapDeduplicates(t *testing.T) {
+	column := func(name, key string) *ColumnDesc {
+		return &ColumnDesc{ColumnBase: ColumnBase{Schema: "dbo", Table: "orders", Name: name}, Key: key}
+	}
+	// id is described once for its unique and once for its primary key
+	// constraint.
+	columnMap := genColumnMap([]*ColumnDesc{
+		column("id", "NO"),
+		column("id", "YES"),
+		column("name", "NO"),
+	})
+	cols := columnMap[columnDatabaseKey("dbo", "orders")]
+	var got [][2]string
+	for _, col := range cols {
+		got = append(got, [2]string{col.Name, col.Key})
+	}
+	want := [][2]string{{"id", "YES"}, {"name", "NO"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("genColumnMap() columns = %v, want %v", got, want)
+	}
+}
@@ -355,16 +355,77 @@ func (*clickhouseSQLDBRepository) DescribeForeignKeysBySchema(ctx context.Contex
 	return nil, nil
 }
 
+func (db *clickhouseSQLDBRepository) Views(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+SELECT name
+FROM   system.tables
+WHERE  database = currentDatabase()
+       AND engine IN ('View', 'MaterializedView', 'LiveView', 'WindowView')
+ORDER  BY name
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *clickhouseSQLDBRepository) Functions(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+SELECT name
+FROM   system.functions
+WHERE  create_query != ''
+ORDER  BY name
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (*clickhouseSQLDBRepository) Sequences(ctx context.Context) ([]string, error) {
+	// clickhouse doesn't support sequences
+	return nil, nil
+}
+
+func (db *clickhouseSQLDBRepository) Indexes(ctx context.Context, tableName string) ([]*Index, error) {
+	// clickhouse only has data skipping indexes, which are built on an expression
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+SELECT name, expr, 0
+FROM   system.data_skipping_indices
+WHERE  database = currentDatabase()
+       AND table = ?
+ORDER  BY name
+`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseIndexes(rows)
+}
+
+func (*clickhouseSQLDBRepository) Triggers(ctx context.Context, tableName string) ([]string, error) {
+	// clickhouse doesn't support triggers
+	return nil, nil
+}
+
 func (*clickhouseSQLDBRepository) Driver() dialect.DatabaseDriver {
 	return dialect.DatabaseDriverClickhouse
 }
 
-func (db *clickhouseSQLDBRepository) Exec(ctx context.Context, query string) (sql.Result, error) {
-	return db.Conn.ExecContext(ctx, query)
+func (db *clickhouseSQLDBRepository) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.Conn.ExecContext(ctx, query, args...)
 }
 
-func (db *clickhouseSQLDBRepository) Query(ctx context.Context, query string) (*sql.Rows, error) {
-	return db.Conn.QueryContext(ctx, query)
+func (db *clickhouseSQLDBRepository) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.Conn.QueryContext(ctx, query, args...)
 }
 
 func (db *clickhouseSQLDBRepository) SchemaTables(ctx context.Context) (map[string][]string, error) {
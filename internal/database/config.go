@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/sqls-server/sqls/dialect"
 	"golang.org/x/crypto/ssh"
@@ -19,18 +20,48 @@ const (
 )
 
 type DBConfig struct {
-	Alias          string                 `json:"alias" yaml:"alias"`
-	Driver         dialect.DatabaseDriver `json:"driver" yaml:"driver"`
-	DataSourceName string                 `json:"dataSourceName" yaml:"dataSourceName"`
-	Proto          Proto                  `json:"proto" yaml:"proto"`
-	User           string                 `json:"user" yaml:"user"`
-	Passwd         string                 `json:"passwd" yaml:"passwd"`
-	Host           string                 `json:"host" yaml:"host"`
-	Port           int                    `json:"port" yaml:"port"`
-	Path           string                 `json:"path" yaml:"path"`
-	DBName         string                 `json:"dbName" yaml:"dbName"`
-	Params         map[string]string      `json:"params" yaml:"params"`
-	SSHCfg         *SSHConfig             `json:"sshConfig" yaml:"sshConfig"`
+	Alias                string                 `json:"alias" yaml:"alias"`
+	Driver               dialect.DatabaseDriver `json:"driver" yaml:"driver"`
+	DataSourceName       string                 `json:"dataSourceName" yaml:"dataSourceName"`
+	Proto                Proto                  `json:"proto" yaml:"proto"`
+	User                 string                 `json:"user" yaml:"user"`
+	Passwd               string                 `json:"passwd" yaml:"passwd"`
+	Host                 string                 `json:"host" yaml:"host"`
+	Port                 int                    `json:"port" yaml:"port"`
+	Path                 string                 `json:"path" yaml:"path"`
+	DBName               string                 `json:"dbName" yaml:"dbName"`
+	Params               map[string]string      `json:"params" yaml:"params"`
+	SSHCfg               *SSHConfig             `json:"sshConfig" yaml:"sshConfig"`
+	Variables            map[string]string      `json:"variables" yaml:"variables"`
+	ReadOnly             bool                   `json:"readOnly" yaml:"readOnly"`
+	QueryTimeout         int                    `json:"queryTimeout" yaml:"queryTimeout"`
+	IntrospectionTimeout int                    `json:"introspectionTimeout" yaml:"introspectionTimeout"`
+}
+
+// DefaultIntrospectionTimeout bounds each pass of the queries which read
+// the schema for completion.
+const DefaultIntrospectionTimeout = time.Minute
+
+// QueryTimeoutDuration returns how long a statement may run, 0 for no limit.
+// QueryTimeout is in seconds, 0 or less for no limit.
+func (c *DBConfig) QueryTimeoutDuration() time.Duration {
+	if c.QueryTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(c.QueryTimeout) * time.Second
+}
+
+// IntrospectionTimeoutDuration returns how long a pass of schema queries
+// may run, 0 for no limit. IntrospectionTimeout is in seconds, 0 for the
+// default and less for no limit.
+func (c *DBConfig) IntrospectionTimeoutDuration() time.Duration {
+	switch {
+	case c.IntrospectionTimeout < 0:
+		return 0
+	case c.IntrospectionTimeout == 0:
+		return DefaultIntrospectionTimeout
+	}
+	return time.Duration(c.IntrospectionTimeout) * time.Second
 }
 
 func (c *DBConfig) Validate() error {
@@ -30,9 +30,16 @@ type DBRepository interface {
 	SchemaTables(ctx context.Context) (map[string][]string, error)
 	DescribeDatabaseTable(ctx context.Context) ([]*ColumnDesc, error)
 	DescribeDatabaseTableBySchema(ctx context.Context, schemaName string) ([]*ColumnDesc, error)
-	Exec(ctx context.Context, query string) (sql.Result, error)
-	Query(ctx context.Context, query string) (*sql.Rows, error)
+	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	DescribeForeignKeysBySchema(ctx context.Context, schemaName string) ([]*ForeignKey, error)
+	// Views, Functions, Sequences, Indexes and Triggers return no names
+	// for the objects a database does not have.
+	Views(ctx context.Context) ([]string, error)
+	Functions(ctx context.Context) ([]string, error)
+	Sequences(ctx context.Context) ([]string, error)
+	Indexes(ctx context.Context, tableName string) ([]*Index, error)
+	Triggers(ctx context.Context, tableName string) ([]string, error)
 }
 
 type DBOption struct {
@@ -48,7 +55,10 @@ type ColumnBase struct {
 
 type ColumnDesc struct {
 	ColumnBase
-	Type    string
+	Type string
+	// Length is the maximum length of a character column, for databases
+	// whose Type leaves it out.
+	Length  sql.NullInt64
 	Null    string
 	Key     string
 	Default sql.NullString
@@ -57,15 +67,36 @@ type ColumnDesc struct {
 
 type ForeignKey [][2]*ColumnBase
 
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
 type fkItemDesc struct {
 	fkID      string
 	schema    string
 	table     string
 	column    string
+	refSchema string
 	refTable  string
 	refColumn string
 }
 
+// IsGenerated tells whether the database computes the values of the
+// column, as for identity and computed columns, which some databases want
+// left out of INSERT statements.
+func (cd *ColumnDesc) IsGenerated() bool {
+	extra := strings.ToUpper(cd.Extra)
+	switch {
+	case extra == "IDENTITY", extra == "COMPUTED":
+		return true
+	case strings.Contains(extra, "VIRTUAL GENERATED"), strings.Contains(extra, "STORED GENERATED"):
+		return true
+	}
+	return false
+}
+
 func (cd *ColumnDesc) OnelineDesc() string {
 	items := []string{}
 	if cd.Type != "" {
@@ -82,6 +113,16 @@ func (cd *ColumnDesc) OnelineDesc() string {
 	return strings.Join(items, " ")
 }
 
+// MaxLength returns the maximum length of a character column, either
+// loaded with it or written in its type as in varchar(n).
+func (cd *ColumnDesc) MaxLength() (int, bool) {
+	if cd.Length.Valid {
+		// -1 stands for varchar(max) in MSSQL
+		return int(cd.Length.Int64), cd.Length.Int64 > 0
+	}
+	return CharLength(cd.Type)
+}
+
 func ColumnDoc(tableName string, colDesc *ColumnDesc) string {
 	buf := new(bytes.Buffer)
 	fmt.Fprintf(buf, "`%s`.`%s` column", tableName, colDesc.Name)
@@ -122,8 +163,13 @@ func SubqueryDoc(name string, views []*parseutil.SubQueryView, dbCache *DBCache)
 	fmt.Fprintln(buf)
 	for _, view := range views {
 		for _, colmun := range view.SubQueryColumns {
+			if colmun.Expr != nil {
+				fmt.Fprint(buf, derivedColumnLine(colmun.DisplayName(), colmun, dbCache))
+				fmt.Fprintln(buf)
+				continue
+			}
 			if colmun.ColumnName == "*" {
-				tableCols, ok := dbCache.ColumnDescs(colmun.ParentTable.Name)
+				tableCols, ok := dbCache.ColumnDatabase(colmun.ParentTable.DatabaseSchema, colmun.ParentTable.Name)
 				if !ok {
 					continue
 				}
@@ -132,7 +178,7 @@ func SubqueryDoc(name string, views []*parseutil.SubQueryView, dbCache *DBCache)
 					fmt.Fprintln(buf)
 				}
 			} else {
-				columnDesc, ok := dbCache.Column(colmun.ParentTable.Name, colmun.ColumnName)
+				columnDesc, ok := dbCache.ColumnBySchema(colmun.ParentTable.DatabaseSchema, colmun.ParentTable.Name, colmun.ColumnName)
 				if !ok {
 					continue
 				}
@@ -152,8 +198,15 @@ func SubqueryColumnDoc(identName string, views []*parseutil.SubQueryView, dbCach
 	fmt.Fprintln(buf)
 	for _, view := range views {
 		for _, colmun := range view.SubQueryColumns {
+			if colmun.Expr != nil {
+				if identName == colmun.AliasName {
+					fmt.Fprint(buf, derivedColumnLine(identName, colmun, dbCache))
+					fmt.Fprintln(buf)
+				}
+				continue
+			}
 			if colmun.ColumnName == "*" {
-				tableCols, ok := dbCache.ColumnDescs(colmun.ParentTable.Name)
+				tableCols, ok := dbCache.ColumnDatabase(colmun.ParentTable.DatabaseSchema, colmun.ParentTable.Name)
 				if !ok {
 					continue
 				}
@@ -168,7 +221,7 @@ func SubqueryColumnDoc(identName string, views []*parseutil.SubQueryView, dbCach
 				if identName != colmun.ColumnName && identName != colmun.AliasName {
 					continue
 				}
-				columnDesc, ok := dbCache.Column(colmun.ParentTable.Name, colmun.ColumnName)
+				columnDesc, ok := dbCache.ColumnBySchema(colmun.ParentTable.DatabaseSchema, colmun.ParentTable.Name, colmun.ColumnName)
 				if !ok {
 					continue
 				}
@@ -180,7 +233,68 @@ func SubqueryColumnDoc(identName string, views []*parseutil.SubQueryView, dbCach
 	return buf.String()
 }
 
-func parseForeignKeys(rows *sql.Rows, schemaName string) ([]*ForeignKey, error) {
+// derivedColumnLine lists a derived column of a subquery with the type
+// of its expression, when it can be inferred.
+func derivedColumnLine(name string, col *parseutil.SubQueryColumn, dbCache *DBCache) string {
+	if typ := InferType(col.Expr, col.Tables, dbCache); typ != "" {
+		return fmt.Sprintf("- %s: `%s`", name, typ)
+	}
+	return fmt.Sprintf("- %s", name)
+}
+
+// CTEColumnDescs returns the columns of a common table expression, each
+// described as the table column it selects when that is known.
+func CTEColumnDescs(cte *parseutil.CTEInfo, dbCache *DBCache) []*ColumnDesc {
+	descs := []*ColumnDesc{}
+	for _, col := range cte.Columns {
+		table := col.ParentTable
+		if col.ColumnName == "*" {
+			if table == nil {
+				continue
+			}
+			if tableCols, ok := dbCache.ColumnDatabase(table.DatabaseSchema, table.Name); ok {
+				descs = append(descs, tableCols...)
+			}
+			continue
+		}
+		desc := &ColumnDesc{ColumnBase: ColumnBase{Table: cte.Name, Name: col.DisplayName()}}
+		if col.Expr != nil {
+			desc.Type = InferType(col.Expr, col.Tables, dbCache)
+		} else if table != nil {
+			if colDesc, ok := dbCache.ColumnBySchema(table.DatabaseSchema, table.Name, col.ColumnName); ok {
+				d := *colDesc
+				d.Name = col.DisplayName()
+				desc = &d
+			}
+		}
+		descs = append(descs, desc)
+	}
+	return descs
+}
+
+func CTEDoc(cte *parseutil.CTEInfo, dbCache *DBCache) string {
+	buf := new(bytes.Buffer)
+	kind := "common table expression"
+	if cte.Recursive {
+		kind = "recursive " + kind
+	}
+	fmt.Fprintf(buf, "# `%s` %s", cte.Name, kind)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf)
+	for _, col := range CTEColumnDescs(cte, dbCache) {
+		fmt.Fprintf(buf, "- %s", col.Name)
+		if desc := col.OnelineDesc(); desc != "" {
+			fmt.Fprintf(buf, ": %s", desc)
+		}
+		fmt.Fprintln(buf)
+	}
+	return buf.String()
+}
+
+// parseForeignKeys reads foreign key rows of the form
+// (constraint, schema, table, column, ref schema, ref table, ref column).
+// The referenced side may live in a different schema than the owner.
+func parseForeignKeys(rows *sql.Rows) ([]*ForeignKey, error) {
 	var retVal []*ForeignKey
 	var prevFk string
 	var cur *ForeignKey
@@ -188,8 +302,10 @@ func parseForeignKeys(rows *sql.Rows, schemaName string) ([]*ForeignKey, error)
 		var fkItem fkItemDesc
 		err := rows.Scan(
 			&fkItem.fkID,
+			&fkItem.schema,
 			&fkItem.table,
 			&fkItem.column,
+			&fkItem.refSchema,
 			&fkItem.refTable,
 			&fkItem.refColumn,
 		)
@@ -197,10 +313,10 @@ func parseForeignKeys(rows *sql.Rows, schemaName string) ([]*ForeignKey, error)
 			return nil, err
 		}
 		var l, r ColumnBase
-		l.Schema = schemaName
+		l.Schema = fkItem.schema
 		l.Table = fkItem.table
 		l.Name = fkItem.column
-		r.Schema = l.Schema
+		r.Schema = fkItem.refSchema
 		r.Table = fkItem.refTable
 		r.Name = fkItem.refColumn
 		if fkItem.fkID != prevFk {
@@ -221,3 +337,47 @@ func parseForeignKeys(rows *sql.Rows, schemaName string) ([]*ForeignKey, error)
 	}
 	return retVal, nil
 }
+
+// parseIndexes reads index rows of the form (index, column, unique), ordered
+// by index and column position. A NULL column, as reported for an expression
+// index, is skipped.
+func parseIndexes(rows *sql.Rows) ([]*Index, error) {
+	var retVal []*Index
+	var cur *Index
+	for rows.Next() {
+		var (
+			name   string
+			column sql.NullString
+			unique bool
+		)
+		if err := rows.Scan(&name, &column, &unique); err != nil {
+			return nil, err
+		}
+		if cur == nil || cur.Name != name {
+			cur = &Index{Name: name, Unique: unique}
+			retVal = append(retVal, cur)
+		}
+		if column.Valid {
+			cur.Columns = append(cur.Columns, column.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+func parseNames(rows *sql.Rows) ([]string, error) {
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
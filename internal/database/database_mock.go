@@ -18,6 +18,12 @@ type MockDBRepository struct {
 	MockExec                          func(context.Context, string) (sql.Result, error)
 	MockQuery                         func(context.Context, string) (*sql.Rows, error)
 	MockDescribeForeignKeysBySchema   func(context.Context, string) ([]*ForeignKey, error)
+	MockViews                         func(context.Context) ([]string, error)
+	MockFunctions                     func(context.Context) ([]string, error)
+	MockSequences                     func(context.Context) ([]string, error)
+	MockIndexes                       func(context.Context, string) ([]*Index, error)
+	MockTriggers                      func(context.Context, string) ([]string, error)
+	MockExplain                       func(context.Context, string) (*Plan, error)
 }
 
 func NewMockDBRepository(_ *sql.DB) DBRepository {
@@ -63,7 +69,25 @@ func NewMockDBRepository(_ *sql.DB) DBRepository {
 			return &sql.Rows{}, nil
 		},
 		MockDescribeForeignKeysBySchema: func(ctx context.Context, schemaName string) ([]*ForeignKey, error) {
-			return foreignKeys, nil
+			var res []*ForeignKey
+			for _, fk := range foreignKeys {
+				if (*fk)[0][0].Schema == schemaName {
+					res = append(res, fk)
+				}
+			}
+			return res, nil
+		},
+		MockViews:     func(ctx context.Context) ([]string, error) { return dummyViews, nil },
+		MockFunctions: func(ctx context.Context) ([]string, error) { return dummyFunctions, nil },
+		MockSequences: func(ctx context.Context) ([]string, error) { return []string{}, nil },
+		MockIndexes: func(ctx context.Context, tableName string) ([]*Index, error) {
+			return dummyIndexes[tableName], nil
+		},
+		MockTriggers: func(ctx context.Context, tableName string) ([]string, error) {
+			return dummyTriggers[tableName], nil
+		},
+		MockExplain: func(ctx context.Context, query string) (*Plan, error) {
+			return ParsePostgreSQLPlan(dummyPlan)
 		},
 	}
 }
@@ -104,11 +128,11 @@ func (m *MockDBRepository) DescribeDatabaseTableBySchema(ctx context.Context, sc
 	return m.MockDescribeDatabaseTableBySchema(ctx, schemaName)
 }
 
-func (m *MockDBRepository) Exec(ctx context.Context, query string) (sql.Result, error) {
+func (m *MockDBRepository) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	return m.MockExec(ctx, query)
 }
 
-func (m *MockDBRepository) Query(ctx context.Context, query string) (*sql.Rows, error) {
+func (m *MockDBRepository) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	return m.MockQuery(ctx, query)
 }
 
@@ -116,6 +140,30 @@ func (m *MockDBRepository) DescribeForeignKeysBySchema(ctx context.Context, sche
 	return m.MockDescribeForeignKeysBySchema(ctx, schemaName)
 }
 
+func (m *MockDBRepository) Views(ctx context.Context) ([]string, error) {
+	return m.MockViews(ctx)
+}
+
+func (m *MockDBRepository) Functions(ctx context.Context) ([]string, error) {
+	return m.MockFunctions(ctx)
+}
+
+func (m *MockDBRepository) Sequences(ctx context.Context) ([]string, error) {
+	return m.MockSequences(ctx)
+}
+
+func (m *MockDBRepository) Indexes(ctx context.Context, tableName string) ([]*Index, error) {
+	return m.MockIndexes(ctx, tableName)
+}
+
+func (m *MockDBRepository) Triggers(ctx context.Context, tableName string) ([]string, error) {
+	return m.MockTriggers(ctx, tableName)
+}
+
+func (m *MockDBRepository) Explain(ctx context.Context, ex Executor, query string) (*Plan, error) {
+	return m.MockExplain(ctx, query)
+}
+
 var dummyDatabases = []string{
 	"information_schema",
 	"mysql",
@@ -135,6 +183,61 @@ var dummyTables = []string{
 	"country",
 	"countrylanguage",
 }
+var dummyViews = []string{
+	"city_population",
+}
+var dummyFunctions = []string{
+	"language_count",
+}
+var dummyIndexes = map[string][]*Index{
+	"city": {
+		{Name: "CountryCode", Columns: []string{"CountryCode"}},
+		{Name: "PRIMARY", Columns: []string{"ID"}, Unique: true},
+	},
+	"countrylanguage": {
+		{Name: "CountryCode", Columns: []string{"CountryCode"}},
+		{Name: "PRIMARY", Columns: []string{"CountryCode", "Language"}, Unique: true},
+	},
+}
+var dummyTriggers = map[string][]string{
+	"city": {"city_before_insert"},
+}
+
+var dummyPlan = `[
+  {
+    "Plan": {
+      "Node Type": "Hash Join",
+      "Join Type": "Inner",
+      "Total Cost": 150.5,
+      "Plan Rows": 4079,
+      "Hash Cond": "(city.countrycode = country.code)",
+      "Plans": [
+        {
+          "Node Type": "Seq Scan",
+          "Relation Name": "city",
+          "Schema": "world",
+          "Total Cost": 120,
+          "Plan Rows": 4079
+        },
+        {
+          "Node Type": "Hash",
+          "Total Cost": 12,
+          "Plan Rows": 239,
+          "Plans": [
+            {
+              "Node Type": "Index Scan",
+              "Relation Name": "country",
+              "Schema": "world",
+              "Index Name": "country_pkey",
+              "Total Cost": 9.5,
+              "Plan Rows": 239
+            }
+          ]
+        }
+      ]
+    }
+  }
+]`
 var dummyCityColumns = []*ColumnDesc{
 	{
 		ColumnBase: ColumnBase{
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CreateTableRepository is implemented by repositories whose database can
+// report the CREATE statement of a table itself.
+type CreateTableRepository interface {
+	ShowCreateTable(ctx context.Context, schemaName, tableName string) (string, error)
+}
+
+// CreateTable returns the CREATE statement of a table. When the database
+// cannot report it, the statement is reconstructed from the cached columns
+// and foreign keys together with the indexes of the table.
+func CreateTable(ctx context.Context, repo DBRepository, dbCache *DBCache, schemaName, tableName string) (string, error) {
+	if r, ok := repo.(CreateTableRepository); ok {
+		return r.ShowCreateTable(ctx, schemaName, tableName)
+	}
+	if dbCache == nil {
+		return "", fmt.Errorf("database cache is not ready")
+	}
+	cols, ok := dbCache.ColumnDatabase(schemaName, tableName)
+	if !ok || len(cols) == 0 {
+		return "", fmt.Errorf("table not found, %q", tableName)
+	}
+	// Use the names as stored, the given ones may differ in case.
+	schemaName, tableName = cols[0].Schema, cols[0].Table
+
+	var fks []*ForeignKey
+	for _, refs := range dbCache.ForeignKeys[dbCache.TableKey(schemaName, tableName)] {
+		for _, fk := range refs {
+			if len(*fk) == 0 {
+				continue
+			}
+			owner := (*fk)[0][0]
+			if strings.EqualFold(owner.Schema, schemaName) && strings.EqualFold(owner.Table, tableName) && !containsForeignKey(fks, fk) {
+				fks = append(fks, fk)
+			}
+		}
+	}
+
+	// Indexes are only looked up in the current schema.
+	var indexes []*Index
+	if strings.EqualFold(schemaName, dbCache.DefaultSchema()) {
+		var err error
+		indexes, err = repo.Indexes(ctx, tableName)
+		if err != nil {
+			return "", err
+		}
+	}
+	return GenerateCreateTable(schemaName, tableName, cols, fks, indexes), nil
+}
+
+// GenerateCreateTable builds a CREATE TABLE statement, followed by a CREATE
+// INDEX statement for each index which is not the primary key.
+func GenerateCreateTable(schemaName, tableName string, cols []*ColumnDesc, fks []*ForeignKey, indexes []*Index) string {
+	name := qualifiedName(schemaName, tableName)
+
+	var defs, pk []string
+	for _, col := range cols {
+		def := col.Name
+		if col.Type != "" {
+			def += " " + col.Type
+		}
+		if col.Null == "NO" {
+			def += " NOT NULL"
+		}
+		if col.Default.Valid {
+			def += " DEFAULT " + col.Default.String
+		}
+		defs = append(defs, def)
+		if isPrimaryKey(col) {
+			pk = append(pk, col.Name)
+		}
+	}
+	if len(pk) > 0 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pk, ", ")))
+	}
+	for _, fk := range fks {
+		var from, to []string
+		for _, pair := range *fk {
+			from = append(from, pair[0].Name)
+			to = append(to, pair[1].Name)
+		}
+		ref := (*fk)[0][1]
+		defs = append(defs, fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+			strings.Join(from, ", "), qualifiedName(ref.Schema, ref.Table), strings.Join(to, ", ")))
+	}
+
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "CREATE TABLE %s (\n", name)
+	fmt.Fprintf(buf, "  %s\n", strings.Join(defs, ",\n  "))
+	fmt.Fprint(buf, ");\n")
+	for _, idx := range indexes {
+		if idx.Unique && equalFold(idx.Columns, pk) {
+			continue
+		}
+		unique := ""
+		if idx.Unique {
+			unique = "UNIQUE "
+		}
+		fmt.Fprintf(buf, "CREATE %sINDEX %s ON %s (%s);\n", unique, idx.Name, name, strings.Join(idx.Columns, ", "))
+	}
+	return buf.String()
+}
+
+func isPrimaryKey(col *ColumnDesc) bool {
+	return col.Key == "YES" || col.Key == "PRI"
+}
+
+func qualifiedName(schemaName, tableName string) string {
+	if schemaName == "" {
+		return tableName
+	}
+	return schemaName + "." + tableName
+}
+
+func containsForeignKey(fks []*ForeignKey, fk *ForeignKey) bool {
+	for _, v := range fks {
+		if v == fk {
+			return true
+		}
+	}
+	return false
+}
+
+func equalFold(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
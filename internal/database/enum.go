@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// EnumType is a named enumerated type, such as a PostgreSQL enum.
+type EnumType struct {
+	Schema string
+	Name   string
+	Values []string
+}
+
+// EnumTypeRepository is implemented by repositories whose database supports
+// named enumerated types. Columns of such a type report "schema.name" as
+// their ColumnDesc.Type.
+type EnumTypeRepository interface {
+	DescribeEnumTypes(ctx context.Context) ([]*EnumType, error)
+}
+
+var (
+	enumTypeWrapperPattern = regexp.MustCompile(`(?i)^(?:nullable|lowcardinality)\s*\((.*)\)$`)
+	enumTypePattern        = regexp.MustCompile(`(?is)^enum(?:8|16)?\s*\((.*)\)$`)
+)
+
+// ParseEnumValues extracts the labels of an inline enum column type such as
+// MySQL "enum('a','b')" or ClickHouse "Enum8('a' = 1, 'b' = 2)".
+func ParseEnumValues(typ string) ([]string, bool) {
+	typ = strings.TrimSpace(typ)
+	for {
+		m := enumTypeWrapperPattern.FindStringSubmatch(typ)
+		if m == nil {
+			break
+		}
+		typ = strings.TrimSpace(m[1])
+	}
+	m := enumTypePattern.FindStringSubmatch(typ)
+	if m == nil {
+		return nil, false
+	}
+
+	var (
+		values  []string
+		label   strings.Builder
+		inQuote bool
+	)
+	body := []rune(m[1])
+	for i := 0; i < len(body); i++ {
+		r := body[i]
+		switch {
+		case !inQuote:
+			if r == '\'' {
+				inQuote = true
+				label.Reset()
+			}
+		case r == '\\' && i+1 < len(body):
+			i++
+			label.WriteRune(body[i])
+		case r == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			i++
+			label.WriteRune('\'')
+		case r == '\'':
+			inQuote = false
+			values = append(values, label.String())
+		default:
+			label.WriteRune(r)
+		}
+	}
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values, true
+}
+
+func enumTypeKey(schemaName, typeName string) string {
+	return strings.ToUpper(schemaName + "." + typeName)
+}
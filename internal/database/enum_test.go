@@ -0,0 +1,34 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEnumValues(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  string
+		want []string
+		ok   bool
+	}{
+		{"mysql", "enum('T','F')", []string{"T", "F"}, true},
+		{"mysql escaped quote", `enum('it''s','don\'t')`, []string{"it's", "don't"}, true},
+		{"mysql comma in label", "enum('a,b','c')", []string{"a,b", "c"}, true},
+		{"clickhouse", "Enum8('a' = 1, 'b' = 2)", []string{"a", "b"}, true},
+		{"clickhouse wrapped", "LowCardinality(Nullable(Enum16('x' = -1)))", []string{"x"}, true},
+		{"not enum", "varchar(10)", nil, false},
+		{"set is not enum", "set('a','b')", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseEnumValues(tt.typ)
+			if ok != tt.ok {
+				t.Fatalf("ParseEnumValues() ok = %v, want %v", ok, tt.ok)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEnumValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
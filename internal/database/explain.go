@@ -0,0 +1,409 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExplainRepository is implemented by repositories which can report the
+// execution plan of a query. The statements asking for the plan run on ex,
+// so that they see the state of the session the query would run in.
+type ExplainRepository interface {
+	Explain(ctx context.Context, ex Executor, query string) (*Plan, error)
+}
+
+// highCostRatio is the share of the total cost above which the work done by
+// a single node is highlighted.
+const highCostRatio = 0.5
+
+// Plan is the execution plan of a query. Raw holds the plan as reported by
+// the database.
+type Plan struct {
+	Roots []*PlanNode `json:"plan"`
+	Raw   string      `json:"raw,omitempty"`
+}
+
+// PlanNode is one operation of an execution plan. Cost is the estimated cost
+// including the children, and Rows the estimated number of rows produced.
+// SeqScan marks a scan which reads the whole table, and HighCost a node which
+// does most of the work of the plan by itself.
+type PlanNode struct {
+	Operation string      `json:"operation"`
+	Object    string      `json:"object,omitempty"`
+	Detail    string      `json:"detail,omitempty"`
+	Cost      *float64    `json:"cost,omitempty"`
+	Rows      *float64    `json:"rows,omitempty"`
+	SeqScan   bool        `json:"seqScan,omitempty"`
+	HighCost  bool        `json:"highCost,omitempty"`
+	Children  []*PlanNode `json:"children,omitempty"`
+}
+
+// Explain returns the execution plan of query run on ex.
+func Explain(ctx context.Context, repo DBRepository, ex Executor, query string) (*Plan, error) {
+	r, ok := repo.(ExplainRepository)
+	if !ok {
+		return nil, fmt.Errorf("explain is not supported for %s", repo.Driver())
+	}
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	if query == "" {
+		return nil, fmt.Errorf("no query to explain")
+	}
+	return r.Explain(ctx, ex, query)
+}
+
+func newPlan(raw string, roots ...*PlanNode) *Plan {
+	var total float64
+	for _, root := range roots {
+		total += totalCost(root)
+	}
+	if total > 0 {
+		for _, root := range roots {
+			markHighCost(root, total)
+		}
+	}
+	return &Plan{Roots: roots, Raw: raw}
+}
+
+// totalCost is the cost of a node, or the sum of its children when the
+// database does not estimate the node itself.
+func totalCost(n *PlanNode) float64 {
+	if n.Cost != nil {
+		return *n.Cost
+	}
+	var sum float64
+	for _, c := range n.Children {
+		sum += totalCost(c)
+	}
+	return sum
+}
+
+func markHighCost(n *PlanNode, total float64) {
+	self := totalCost(n)
+	for _, c := range n.Children {
+		self -= totalCost(c)
+		markHighCost(c, total)
+	}
+	n.HighCost = self >= total*highCostRatio
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func jsonFloat(v interface{}) *float64 {
+	switch v := v.(type) {
+	case float64:
+		return floatPtr(v)
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return floatPtr(f)
+		}
+	}
+	return nil
+}
+
+func jsonString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// ParsePostgreSQLPlan parses the output of EXPLAIN (FORMAT JSON).
+func ParsePostgreSQLPlan(raw string) (*Plan, error) {
+	var out []struct {
+		Plan map[string]interface{} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return nil, fmt.Errorf("cannot parse plan, %w", err)
+	}
+	var roots []*PlanNode
+	for _, o := range out {
+		if o.Plan != nil {
+			roots = append(roots, postgresPlanNode(o.Plan))
+		}
+	}
+	return newPlan(raw, roots...), nil
+}
+
+func postgresPlanNode(m map[string]interface{}) *PlanNode {
+	n := &PlanNode{
+		Operation: jsonString(m["Node Type"]),
+		Cost:      jsonFloat(m["Total Cost"]),
+		Rows:      jsonFloat(m["Plan Rows"]),
+	}
+	if rel := jsonString(m["Relation Name"]); rel != "" {
+		n.Object = rel
+		if schema := jsonString(m["Schema"]); schema != "" {
+			n.Object = schema + "." + rel
+		}
+	}
+	var details []string
+	for _, key := range []string{"Index Name", "Join Type", "Index Cond", "Hash Cond", "Merge Cond", "Filter"} {
+		if v := jsonString(m[key]); v != "" {
+			details = append(details, key+": "+v)
+		}
+	}
+	n.Detail = strings.Join(details, ", ")
+	n.SeqScan = n.Operation == "Seq Scan"
+	if plans, ok := m["Plans"].([]interface{}); ok {
+		for _, p := range plans {
+			if child, ok := p.(map[string]interface{}); ok {
+				n.Children = append(n.Children, postgresPlanNode(child))
+			}
+		}
+	}
+	return n
+}
+
+// ParseMySQLPlan parses the output of EXPLAIN FORMAT=JSON.
+func ParseMySQLPlan(raw string) (*Plan, error) {
+	var out map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return nil, fmt.Errorf("cannot parse plan, %w", err)
+	}
+	return newPlan(raw, mysqlPlanNodes(out)...), nil
+}
+
+// mysqlPlanNodes walks an object of a MySQL plan. Tables and query blocks
+// become nodes of their own, as does every other object or array of objects,
+// named after its key.
+func mysqlPlanNodes(m map[string]interface{}) []*PlanNode {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var nodes []*PlanNode
+	for _, key := range keys {
+		switch v := m[key].(type) {
+		case map[string]interface{}:
+			switch key {
+			case "query_block":
+				n := &PlanNode{Operation: "query block", Children: mysqlPlanNodes(v)}
+				if id := jsonString(v["select_id"]); id != "" {
+					n.Operation += " #" + id
+				}
+				if ci, ok := v["cost_info"].(map[string]interface{}); ok {
+					n.Cost = jsonFloat(ci["query_cost"])
+				}
+				nodes = append(nodes, n)
+			case "table":
+				nodes = append(nodes, mysqlTableNode(v))
+			case "cost_info":
+			default:
+				nodes = append(nodes, &PlanNode{
+					Operation: strings.ReplaceAll(key, "_", " "),
+					Children:  mysqlPlanNodes(v),
+				})
+			}
+		case []interface{}:
+			n := &PlanNode{Operation: strings.ReplaceAll(key, "_", " ")}
+			for _, e := range v {
+				if o, ok := e.(map[string]interface{}); ok {
+					n.Children = append(n.Children, mysqlPlanNodes(o)...)
+				}
+			}
+			if len(n.Children) > 0 {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return nodes
+}
+
+func mysqlTableNode(m map[string]interface{}) *PlanNode {
+	accessType := jsonString(m["access_type"])
+	n := &PlanNode{
+		Operation: "table access",
+		Object:    jsonString(m["table_name"]),
+		Rows:      jsonFloat(m["rows_produced_per_join"]),
+		SeqScan:   accessType == "ALL",
+	}
+	if accessType != "" {
+		n.Operation += " (" + accessType + ")"
+	}
+	if ci, ok := m["cost_info"].(map[string]interface{}); ok {
+		read, eval := jsonFloat(ci["read_cost"]), jsonFloat(ci["eval_cost"])
+		if read != nil && eval != nil {
+			n.Cost = floatPtr(*read + *eval)
+		}
+	}
+	var details []string
+	if key := jsonString(m["key"]); key != "" {
+		details = append(details, "key: "+key)
+	}
+	if cond := jsonString(m["attached_condition"]); cond != "" {
+		details = append(details, "condition: "+cond)
+	}
+	n.Detail = strings.Join(details, ", ")
+
+	sub := map[string]interface{}{}
+	for k, v := range m {
+		if k != "cost_info" {
+			sub[k] = v
+		}
+	}
+	n.Children = mysqlPlanNodes(sub)
+	return n
+}
+
+// ParseMssqlPlan parses the plan reported with SET SHOWPLAN_XML ON.
+func ParseMssqlPlan(raw string) (*Plan, error) {
+	dec := xml.NewDecoder(strings.NewReader(raw))
+	var roots, stack []*PlanNode
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse plan, %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "RelOp":
+				n := mssqlRelOp(t.Attr)
+				if len(stack) == 0 {
+					roots = append(roots, n)
+				} else {
+					parent := stack[len(stack)-1]
+					parent.Children = append(parent.Children, n)
+				}
+				stack = append(stack, n)
+			case "Object":
+				if len(stack) > 0 && stack[len(stack)-1].Object == "" {
+					stack[len(stack)-1].Object = mssqlObjectName(t.Attr)
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "RelOp" && len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	return newPlan(raw, roots...), nil
+}
+
+func mssqlRelOp(attrs []xml.Attr) *PlanNode {
+	n := &PlanNode{}
+	var logicalOp string
+	for _, a := range attrs {
+		switch a.Name.Local {
+		case "PhysicalOp":
+			n.Operation = a.Value
+		case "LogicalOp":
+			logicalOp = a.Value
+		case "EstimatedTotalSubtreeCost":
+			n.Cost = jsonFloat(a.Value)
+		case "EstimateRows":
+			n.Rows = jsonFloat(a.Value)
+		}
+	}
+	if logicalOp != "" && logicalOp != n.Operation {
+		n.Detail = logicalOp
+	}
+	n.SeqScan = n.Operation == "Table Scan" || n.Operation == "Clustered Index Scan"
+	return n
+}
+
+func mssqlObjectName(attrs []xml.Attr) string {
+	var schema, table string
+	for _, a := range attrs {
+		switch a.Name.Local {
+		case "Schema":
+			schema = a.Value
+		case "Table":
+			table = a.Value
+		}
+	}
+	if schema != "" && table != "" {
+		return schema + "." + table
+	}
+	return table
+}
+
+// SQLitePlanRow is a row of EXPLAIN QUERY PLAN.
+type SQLitePlanRow struct {
+	ID     int64
+	Parent int64
+	Detail string
+}
+
+// BuildSQLitePlan arranges the rows of EXPLAIN QUERY PLAN into a tree.
+func BuildSQLitePlan(rows []*SQLitePlanRow) *Plan {
+	var raw strings.Builder
+	nodes := map[int64]*PlanNode{}
+	var roots []*PlanNode
+	for _, r := range rows {
+		fmt.Fprintf(&raw, "%d|%d|%s\n", r.ID, r.Parent, r.Detail)
+		n := &PlanNode{Operation: r.Detail}
+		if fields := strings.Fields(r.Detail); len(fields) >= 2 && (fields[0] == "SCAN" || fields[0] == "SEARCH") {
+			n.Operation = fields[0]
+			n.Object = fields[1]
+			if fields[1] == "TABLE" && len(fields) >= 3 {
+				n.Object = fields[2]
+			}
+			n.Detail = strings.TrimSpace(r.Detail[strings.Index(r.Detail, n.Object)+len(n.Object):])
+			n.SeqScan = fields[0] == "SCAN" && !strings.Contains(r.Detail, " USING ")
+		}
+		nodes[r.ID] = n
+		if parent, ok := nodes[r.Parent]; ok && r.Parent != r.ID {
+			parent.Children = append(parent.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	return newPlan(raw.String(), roots...)
+}
+
+// OraclePlanRow is a row of PLAN_TABLE.
+type OraclePlanRow struct {
+	ID          int64
+	ParentID    sql.NullInt64
+	Operation   string
+	Options     sql.NullString
+	ObjectName  sql.NullString
+	Cost        sql.NullFloat64
+	Cardinality sql.NullFloat64
+}
+
+// BuildOraclePlan arranges the rows of PLAN_TABLE into a tree. raw is the
+// plan as formatted by DBMS_XPLAN.
+func BuildOraclePlan(rows []*OraclePlanRow, raw string) *Plan {
+	nodes := map[int64]*PlanNode{}
+	var roots []*PlanNode
+	for _, r := range rows {
+		n := &PlanNode{
+			Operation: r.Operation,
+			Object:    r.ObjectName.String,
+			Detail:    r.Options.String,
+			SeqScan:   r.Operation == "TABLE ACCESS" && r.Options.String == "FULL",
+		}
+		if r.Cost.Valid {
+			n.Cost = floatPtr(r.Cost.Float64)
+		}
+		if r.Cardinality.Valid {
+			n.Rows = floatPtr(r.Cardinality.Float64)
+		}
+		nodes[r.ID] = n
+		if parent, ok := nodes[r.ParentID.Int64]; ok && r.ParentID.Valid {
+			parent.Children = append(parent.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	return newPlan(raw, roots...)
+}
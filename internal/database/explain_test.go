@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sqls-server/sqls/dialect"
+)
+
+// planSummary flattens a plan to "operation object flags" lines, indented by
+// depth, to keep the expectations readable.
+func planSummary(plan *Plan) []string {
+	var lines []string
+	var walk func(n *PlanNode, indent string)
+	walk = func(n *PlanNode, indent string) {
+		line := indent + n.Operation
+		if n.Object != "" {
+			line += " " + n.Object
+		}
+		if n.SeqScan {
+			line += " seq"
+		}
+		if n.HighCost {
+			line += " high"
+		}
+		lines = append(lines, line)
+		for _, c := range n.Children {
+			walk(c, indent+"  ")
+		}
+	}
+	for _, root := range plan.Roots {
+		walk(root, "")
+	}
+	return lines
+}
+
+func TestParseMySQLPlan(t *testing.T) {
+	raw := `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "1283.25"},
+    "nested_loop": [
+      {
+        "table": {
+          "table_name": "city",
+          "access_type": "ALL",
+          "rows_produced_per_join": 4046,
+          "cost_info": {"read_cost": "10.25", "eval_cost": "404.60", "prefix_cost": "414.85"},
+          "attached_condition": "(world.city.CountryCode is not null)"
+        }
+      },
+      {
+        "table": {
+          "table_name": "country",
+          "access_type": "eq_ref",
+          "key": "PRIMARY",
+          "rows_produced_per_join": 4046,
+          "cost_info": {"read_cost": "463.80", "eval_cost": "404.60", "prefix_cost": "1283.25"}
+        }
+      }
+    ]
+  }
+}`
+	plan, err := ParseMySQLPlan(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"query block #1",
+		"  nested loop",
+		"    table access (ALL) city seq",
+		"    table access (eq_ref) country high",
+	}
+	if diff := cmp.Diff(want, planSummary(plan)); diff != "" {
+		t.Errorf("unmatch plan (- want, + got):\n%s", diff)
+	}
+}
+
+func TestParseMssqlPlan(t *testing.T) {
+	raw := `<ShowPlanXML xmlns="http://schemas.microsoft.com/sqlserver/2004/07/showplan">
+<BatchSequence><Batch><Statements><StmtSimple><QueryPlan>
+<RelOp PhysicalOp="Hash Match" LogicalOp="Inner Join" EstimateRows="4079" EstimatedTotalSubtreeCost="0.2">
+  <Hash>
+    <RelOp PhysicalOp="Clustered Index Scan" LogicalOp="Clustered Index Scan" EstimateRows="239" EstimatedTotalSubtreeCost="0.01">
+      <IndexScan><Object Database="[world]" Schema="[dbo]" Table="[country]" Index="[PK_country]"/></IndexScan>
+    </RelOp>
+    <RelOp PhysicalOp="Table Scan" LogicalOp="Table Scan" EstimateRows="4079" EstimatedTotalSubtreeCost="0.15">
+      <TableScan><Object Database="[world]" Schema="[dbo]" Table="[city]"/></TableScan>
+    </RelOp>
+  </Hash>
+</RelOp>
+</QueryPlan></StmtSimple></Statements></Batch></BatchSequence>
+</ShowPlanXML>`
+	plan, err := ParseMssqlPlan(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Hash Match",
+		"  Clustered Index Scan [dbo].[country] seq",
+		"  Table Scan [dbo].[city] seq high",
+	}
+	if diff := cmp.Diff(want, planSummary(plan)); diff != "" {
+		t.Errorf("unmatch plan (- want, + got):\n%s", diff)
+	}
+	if got := plan.Roots[0].Detail; got != "Inner Join" {
+		t.Errorf("detail = %q, want %q", got, "Inner Join")
+	}
+}
+
+func TestBuildSQLitePlan(t *testing.T) {
+	plan := BuildSQLitePlan([]*SQLitePlanRow{
+		{ID: 3, Parent: 0, Detail: "SCAN city"},
+		{ID: 5, Parent: 0, Detail: "SEARCH country USING INDEX sqlite_autoindex_country_1 (Code=?)"},
+		{ID: 7, Parent: 0, Detail: "CORRELATED SCALAR SUBQUERY 1"},
+		{ID: 9, Parent: 7, Detail: "SCAN TABLE countrylanguage"},
+	})
+	want := []string{
+		"SCAN city seq",
+		"SEARCH country",
+		"CORRELATED SCALAR SUBQUERY 1",
+		"  SCAN countrylanguage seq",
+	}
+	if diff := cmp.Diff(want, planSummary(plan)); diff != "" {
+		t.Errorf("unmatch plan (- want, + got):\n%s", diff)
+	}
+	if got := plan.Roots[1].Detail; got != "USING INDEX sqlite_autoindex_country_1 (Code=?)" {
+		t.Errorf("detail = %q", got)
+	}
+}
+
+func TestBuildOraclePlan(t *testing.T) {
+	plan := BuildOraclePlan([]*OraclePlanRow{
+		{ID: 0, Operation: "SELECT STATEMENT", Cost: sql.NullFloat64{Float64: 20, Valid: true}},
+		{ID: 1, ParentID: sql.NullInt64{Int64: 0, Valid: true}, Operation: "TABLE ACCESS", Options: sql.NullString{String: "FULL", Valid: true}, ObjectName: sql.NullString{String: "CITY", Valid: true}, Cost: sql.NullFloat64{Float64: 19, Valid: true}},
+	}, "Plan hash value: 1")
+	want := []string{
+		"SELECT STATEMENT",
+		"  TABLE ACCESS CITY seq high",
+	}
+	if diff := cmp.Diff(want, planSummary(plan)); diff != "" {
+		t.Errorf("unmatch plan (- want, + got):\n%s", diff)
+	}
+	if plan.Raw != "Plan hash value: 1" {
+		t.Errorf("raw = %q", plan.Raw)
+	}
+}
+
+func TestExplain_session(t *testing.T) {
+	// Every connection to an in-memory database has a database of its own,
+	// so the table can only be explained on the session which created it.
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxIdleConns(0)
+
+	ctx := context.Background()
+	session := NewSession(db, dialect.DatabaseDriverSQLite3)
+	defer session.Close()
+	if _, err := session.Exec(ctx, "CREATE TABLE city (name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := Explain(ctx, NewSQLite3DBRepository(db), session, "SELECT * FROM city;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"SCAN city seq"}, planSummary(plan)); diff != "" {
+		t.Errorf("unmatch plan (- want, + got):\n%s", diff)
+	}
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ConfirmReason tells why query should only run once the user confirms it:
+// it deletes or updates every row of a table, or drops or truncates
+// something. It is empty for other statements.
+func ConfirmReason(query string) string {
+	query = mainStatement(query)
+	words := leadingWords(query, 2)
+	if len(words) == 0 {
+		return ""
+	}
+	switch words[0] {
+	case "DELETE", "UPDATE":
+		if !hasTopLevelWord(query, "WHERE") {
+			return words[0] + " without a WHERE clause"
+		}
+	case "DROP":
+		return strings.Join(words, " ")
+	case "TRUNCATE":
+		return words[0]
+	}
+	return ""
+}
+
+// IsReadOnly reports whether query is known to only read data: a SELECT,
+// which may follow a WITH clause, SHOW, DESCRIBE, VALUES, PRAGMA reading a
+// setting, or EXPLAIN without ANALYZE. Anything else, procedure calls
+// included, may write.
+func IsReadOnly(query string) bool {
+	query = mainStatement(query)
+	words := leadingWords(query, 1)
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "SELECT":
+		// SELECT INTO creates a table or writes a file.
+		return !hasTopLevelWord(query, "INTO")
+	case "SHOW", "DESCRIBE", "DESC", "VALUES", "LIST":
+		return true
+	case "PRAGMA":
+		return !strings.ContainsRune(query, '=')
+	case "EXPLAIN":
+		// EXPLAIN ANALYZE runs the statement.
+		return !analyzePattern.MatchString(query)
+	}
+	return false
+}
+
+var analyzePattern = regexp.MustCompile(`(?i)\banalyze\b`)
+
+// mainStatement returns query from its main statement on, past the common
+// table expressions of a WITH clause. The main statement is the first word
+// following the closing parenthesis of a common table expression, other
+// than the AS following a column list and the SEARCH and CYCLE clauses of
+// PostgreSQL. Other queries are returned as they are.
+func mainStatement(query string) string {
+	if words := leadingWords(query, 1); len(words) == 0 || words[0] != "WITH" {
+		return query
+	}
+	depth := 0
+	afterParen := false
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i, c)
+			afterParen = false
+		case c == '[':
+			i = skipQuoted(query, i, ']')
+			afterParen = false
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			i = skipLine(query, i)
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipBlockComment(query, i)
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth--
+			i++
+			afterParen = depth == 0
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := scanName(query, i)
+			if depth == 0 && afterParen {
+				switch strings.ToUpper(query[i:end]) {
+				case "AS", "SEARCH", "CYCLE":
+				default:
+					return query[i:]
+				}
+			}
+			afterParen = false
+			i = end
+		case unicode.IsSpace(rune(c)):
+			i++
+		default:
+			afterParen = false
+			i++
+		}
+	}
+	return query
+}
+
+// leadingWords returns up to n words query starts with, in upper case,
+// skipping comments.
+func leadingWords(query string, n int) []string {
+	var words []string
+	for i := 0; i < len(query) && len(words) < n; {
+		c := query[i]
+		switch {
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			i = skipLine(query, i)
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipBlockComment(query, i)
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := scanName(query, i)
+			words = append(words, strings.ToUpper(query[i:end]))
+			i = end
+		case unicode.IsSpace(rune(c)):
+			i++
+		default:
+			return words
+		}
+	}
+	return words
+}
+
+// hasTopLevelWord reports whether query has the keyword word outside of
+// parentheses, string literals, quoted identifiers and comments.
+func hasTopLevelWord(query, word string) bool {
+	depth := 0
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i, c)
+		case c == '[':
+			i = skipQuoted(query, i, ']')
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			i = skipLine(query, i)
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipBlockComment(query, i)
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth--
+			i++
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := scanName(query, i)
+			if depth == 0 && strings.EqualFold(query[i:end], word) {
+				return true
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return false
+}
+
+func skipBlockComment(query string, i int) int {
+	if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+		return i + end + 4
+	}
+	return len(query)
+}
@@ -0,0 +1,62 @@
+package database
+
+import "testing"
+
+func TestConfirmReason(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM city", ""},
+		{"DELETE FROM city WHERE ID = 1", ""},
+		{"delete from city", "DELETE without a WHERE clause"},
+		{"-- clean up\n/* all */ DELETE FROM city", "DELETE without a WHERE clause"},
+		{"DELETE FROM city -- WHERE ID = 1", "DELETE without a WHERE clause"},
+		{"UPDATE city SET Name = 'where'", "UPDATE without a WHERE clause"},
+		{"UPDATE city SET Population = (SELECT 1 FROM dual WHERE 1 = 1)", "UPDATE without a WHERE clause"},
+		{"UPDATE city SET Population = 0 WHERE ID IN (SELECT ID FROM town)", ""},
+		{"UPDATE [where] SET Population = 0", "UPDATE without a WHERE clause"},
+		{"DROP TABLE city", "DROP TABLE"},
+		{"truncate city", "TRUNCATE"},
+		{"CREATE TABLE dropped (id int)", ""},
+		{"WITH old AS (SELECT ID FROM city WHERE ID < 10) DELETE FROM city", "DELETE without a WHERE clause"},
+		{"WITH old (id) AS (SELECT ID FROM city) DELETE FROM city WHERE ID IN (SELECT id FROM old)", ""},
+		{"WITH RECURSIVE t AS (SELECT 1), u AS (SELECT 2) UPDATE city SET Name = 'x'", "UPDATE without a WHERE clause"},
+	}
+	for _, tt := range tests {
+		if got := ConfirmReason(tt.query); got != tt.want {
+			t.Errorf("ConfirmReason(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT * FROM city", true},
+		{"-- all\nselect * from city", true},
+		{"WITH big AS (SELECT * FROM city WHERE Population > 1000000) SELECT * FROM big", true},
+		{"WITH old AS (SELECT ID FROM city) DELETE FROM city WHERE ID IN (SELECT ID FROM old)", false},
+		{"WITH t (id) AS (SELECT 1) UPDATE city SET Name = 'x' WHERE ID IN (SELECT id FROM t)", false},
+		{"SELECT * INTO city_copy FROM city", false},
+		{"SHOW TABLES", true},
+		{"DESCRIBE city", true},
+		{"EXPLAIN SELECT * FROM city", true},
+		{"EXPLAIN ANALYZE DELETE FROM city", false},
+		{"EXPLAIN (ANALYZE, BUFFERS) DELETE FROM city", false},
+		{"PRAGMA table_info(city)", true},
+		{"PRAGMA journal_mode = WAL", false},
+		{"EXEC dbo.purge_cities", false},
+		{"EXECUTE dbo.purge_cities", false},
+		{"CALL purge_cities()", false},
+		{"DELETE FROM city WHERE ID = 1", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsReadOnly(tt.query); got != tt.want {
+			t.Errorf("IsReadOnly(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
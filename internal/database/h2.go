@@ -276,14 +276,89 @@ func (db *H2DBRepository) DescribeDatabaseTableBySchema(ctx context.Context, sch
 	return tableInfos, nil
 }
 
-func (db *H2DBRepository) Exec(ctx context.Context, query string) (sql.Result, error) {
-	return db.Conn.ExecContext(ctx, query)
+func (db *H2DBRepository) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.Conn.ExecContext(ctx, query, args...)
 }
 
-func (db *H2DBRepository) Query(ctx context.Context, query string) (*sql.Rows, error) {
-	return db.Conn.QueryContext(ctx, query)
+func (db *H2DBRepository) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.Conn.QueryContext(ctx, query, args...)
 }
 
 func (db *H2DBRepository) DescribeForeignKeysBySchema(ctx context.Context, schemaName string) ([]*ForeignKey, error) {
 	return nil, fmt.Errorf("describe foreign keys is not supported")
 }
+
+func (db *H2DBRepository) Views(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT table_name FROM information_schema.views WHERE table_schema = SCHEMA() ORDER BY table_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *H2DBRepository) Functions(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT alias_name FROM information_schema.function_aliases WHERE alias_schema = SCHEMA() ORDER BY alias_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *H2DBRepository) Sequences(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT sequence_name FROM information_schema.sequences WHERE sequence_schema = SCHEMA() ORDER BY sequence_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *H2DBRepository) Indexes(ctx context.Context, tableName string) ([]*Index, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT
+		index_name,
+		column_name,
+		NOT non_unique
+	FROM
+		information_schema.indexes
+	WHERE
+		table_schema = SCHEMA() AND table_name = ?
+	ORDER BY
+		index_name,
+		ordinal_position
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseIndexes(rows)
+}
+
+func (db *H2DBRepository) Triggers(ctx context.Context, tableName string) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT trigger_name FROM information_schema.triggers WHERE table_schema = SCHEMA() AND table_name = ? ORDER BY trigger_name
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
@@ -0,0 +1,337 @@
+package database
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/parser/parseutil"
+	"github.com/sqls-server/sqls/token"
+)
+
+// functionTypes are the result types of common functions. An empty type
+// is that of the first argument whose type is known.
+var functionTypes = map[string]string{
+	"COUNT":             "bigint",
+	"COUNT_BIG":         "bigint",
+	"ROW_NUMBER":        "bigint",
+	"RANK":              "bigint",
+	"DENSE_RANK":        "bigint",
+	"AVG":               "numeric",
+	"STDDEV":            "double precision",
+	"VARIANCE":          "double precision",
+	"RANDOM":            "double precision",
+	"RAND":              "double precision",
+	"LENGTH":            "integer",
+	"CHAR_LENGTH":       "integer",
+	"CHARACTER_LENGTH":  "integer",
+	"OCTET_LENGTH":      "integer",
+	"LEN":               "integer",
+	"POSITION":          "integer",
+	"INSTR":             "integer",
+	"UPPER":             "text",
+	"LOWER":             "text",
+	"CONCAT":            "text",
+	"CONCAT_WS":         "text",
+	"SUBSTRING":         "text",
+	"SUBSTR":            "text",
+	"TRIM":              "text",
+	"LTRIM":             "text",
+	"RTRIM":             "text",
+	"REPLACE":           "text",
+	"LEFT":              "text",
+	"RIGHT":             "text",
+	"LPAD":              "text",
+	"RPAD":              "text",
+	"GROUP_CONCAT":      "text",
+	"STRING_AGG":        "text",
+	"LISTAGG":           "text",
+	"TO_CHAR":           "text",
+	"NOW":               "timestamp",
+	"CURRENT_TIMESTAMP": "timestamp",
+	"LOCALTIMESTAMP":    "timestamp",
+	"SYSDATE":           "timestamp",
+	"GETDATE":           "datetime",
+	"CURRENT_DATE":      "date",
+	"CURDATE":           "date",
+	"DATE":              "date",
+	"TO_DATE":           "date",
+	"MIN":               "",
+	"MAX":               "",
+	"SUM":               "",
+	"ABS":               "",
+	"ROUND":             "",
+	"FLOOR":             "",
+	"CEIL":              "",
+	"CEILING":           "",
+	"COALESCE":          "",
+	"IFNULL":            "",
+	"ISNULL":            "",
+	"NVL":               "",
+	"NULLIF":            "",
+	"GREATEST":          "",
+	"LEAST":             "",
+}
+
+type typeClass int
+
+const (
+	classUnknown typeClass = iota
+	classInteger
+	classDecimal
+	classFloat
+	classText
+	classBoolean
+	classDate
+	classTimestamp
+)
+
+// classOf tells the kind of values a column type of any database holds.
+func classOf(typ string) typeClass {
+	t := strings.ToLower(strings.TrimSpace(typ))
+	hasPrefix := func(prefixes ...string) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(t, p) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case t == "":
+		return classUnknown
+	case hasPrefix("interval"):
+		return classUnknown
+	case hasPrefix("int", "bigint", "smallint", "tinyint", "mediumint", "serial", "bigserial", "smallserial", "uint"):
+		return classInteger
+	case hasPrefix("decimal", "numeric", "number", "money", "smallmoney", "dec"):
+		return classDecimal
+	case hasPrefix("float", "double", "real", "binary_float", "binary_double"):
+		return classFloat
+	case hasPrefix("char", "varchar", "nchar", "nvarchar", "text", "tinytext", "mediumtext", "longtext", "ntext", "string", "clob", "nclob", "character", "varchar2", "nvarchar2", "citext", "enum"):
+		return classText
+	case hasPrefix("bool", "bit"):
+		return classBoolean
+	case hasPrefix("timestamp", "datetime", "smalldatetime"):
+		return classTimestamp
+	case hasPrefix("date"):
+		return classDate
+	}
+	return classUnknown
+}
+
+func isNumberClass(c typeClass) bool {
+	return c == classInteger || c == classDecimal || c == classFloat
+}
+
+// IsNumberType reports whether columns of type typ hold numbers.
+func IsNumberType(typ string) bool {
+	return isNumberClass(classOf(typ))
+}
+
+var charLengthPattern = regexp.MustCompile(`(?i)^(?:n?(?:var)?char2?|character(?: varying)?)\s*\(\s*(\d+)`)
+
+// CharLength returns the length n of a char(n) or varchar(n) column type.
+func CharLength(typ string) (int, bool) {
+	m := charLengthPattern.FindStringSubmatch(strings.TrimSpace(typ))
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// InferType returns the type of expr, an expression selected by a query
+// reading tables, or "" when it cannot be told. Column types come from
+// dbCache, and those of function results from a catalog of common
+// functions.
+func InferType(expr ast.Node, tables []*parseutil.TableInfo, dbCache *DBCache) string {
+	inf := &typeInference{tables: tables, dbCache: dbCache}
+	return inf.infer(expr)
+}
+
+type typeInference struct {
+	tables  []*parseutil.TableInfo
+	dbCache *DBCache
+}
+
+func (inf *typeInference) infer(node ast.Node) string {
+	switch v := node.(type) {
+	case nil:
+		return ""
+	case *ast.Aliased:
+		return inf.infer(v.RealName)
+	case *ast.Identifier:
+		return inf.column("", v.NoQuoteString())
+	case *ast.MemberIdentifier:
+		if v.ParentIdent == nil || v.ChildIdent == nil {
+			return ""
+		}
+		return inf.column(v.GetParentIdent().NoQuoteString(), v.GetChildIdent().NoQuoteString())
+	case *ast.Operator:
+		return inf.operator(v)
+	case *ast.Comparison:
+		return "boolean"
+	case *ast.FunctionLiteral:
+		return inf.function(v)
+	case *ast.SwitchCase:
+		return inf.switchCase(v)
+	case *ast.Parenthesis:
+		nodes := parseutil.SignificantNodes(v.Inner())
+		if len(nodes) == 1 {
+			return inf.infer(nodes[0])
+		}
+		return ""
+	case ast.Token:
+		return literalType(v.GetToken())
+	case ast.TokenList:
+		nodes := parseutil.SignificantNodes(v)
+		if len(nodes) == 1 {
+			return inf.infer(nodes[0])
+		}
+	}
+	return ""
+}
+
+// column returns the type of a column of the table named or aliased
+// parent, or of any of the tables when parent is empty.
+func (inf *typeInference) column(parent, name string) string {
+	if inf.dbCache == nil {
+		return ""
+	}
+	for _, table := range inf.tables {
+		if parent != "" && table.Alias != parent && (table.Alias != "" || table.Name != parent) {
+			continue
+		}
+		if col, ok := inf.dbCache.ColumnBySchema(table.DatabaseSchema, table.Name, name); ok {
+			return col.Type
+		}
+	}
+	return ""
+}
+
+func literalType(tok *ast.SQLToken) string {
+	switch tok.Kind {
+	case token.Number:
+		if strings.ContainsAny(tok.String(), ".eE") {
+			return "numeric"
+		}
+		return "integer"
+	case token.SingleQuotedString,
+		token.NationalStringLiteral,
+		token.EscapeStringLiteral,
+		token.DollarQuotedString:
+		return "text"
+	case token.SQLKeyword:
+		switch strings.ToUpper(tok.String()) {
+		case "TRUE", "FALSE":
+			return "boolean"
+		case "CURRENT_TIMESTAMP", "LOCALTIMESTAMP", "SYSDATE":
+			return "timestamp"
+		case "CURRENT_DATE":
+			return "date"
+		}
+	}
+	return ""
+}
+
+// operator returns the type of an arithmetic expression: the type of the
+// wider number operand, or of the date moved by a number of days.
+func (inf *typeInference) operator(op *ast.Operator) string {
+	if op.Left == nil || op.Right == nil {
+		nodes := parseutil.SignificantNodes(op)
+		if len(nodes) == 1 {
+			return inf.infer(nodes[0])
+		}
+		return ""
+	}
+	left, right := inf.infer(op.Left), inf.infer(op.Right)
+	lc, rc := classOf(left), classOf(right)
+	switch {
+	case isNumberClass(lc) && isNumberClass(rc):
+		if rc > lc {
+			return right
+		}
+		return left
+	case (lc == classDate || lc == classTimestamp) && rc == classInteger:
+		return left
+	case lc == classText && rc == classText:
+		// string concatenation with +
+		return left
+	}
+	return ""
+}
+
+func (inf *typeInference) function(fn *ast.FunctionLiteral) string {
+	toks := parseutil.SignificantNodes(fn)
+	if len(toks) < 2 {
+		return ""
+	}
+	name := strings.ToUpper(toks[0].String())
+	args, ok := toks[1].(*ast.Parenthesis)
+	if !ok {
+		return ""
+	}
+	if name == "CAST" {
+		return castType(args)
+	}
+	typ, ok := functionTypes[name]
+	if !ok || typ != "" {
+		return typ
+	}
+	for _, arg := range functionArguments(args) {
+		if typ := inf.infer(arg); typ != "" {
+			return typ
+		}
+	}
+	return ""
+}
+
+// castType returns the type written after AS in "CAST(expr AS type)".
+func castType(args *ast.Parenthesis) string {
+	var typ []string
+	as := false
+	for _, node := range parseutil.SignificantNodes(args.Inner()) {
+		if as {
+			if _, ok := node.(*ast.Parenthesis); ok && len(typ) > 0 {
+				// the length of varchar(10)
+				typ[len(typ)-1] += node.String()
+				continue
+			}
+			typ = append(typ, node.String())
+		} else if item, ok := node.(*ast.Item); ok && strings.EqualFold(item.String(), "AS") {
+			as = true
+		}
+	}
+	return strings.Join(typ, " ")
+}
+
+func functionArguments(args *ast.Parenthesis) []ast.Node {
+	nodes := parseutil.SignificantNodes(args.Inner())
+	if len(nodes) == 1 {
+		if list, ok := nodes[0].(*ast.IdentifierList); ok {
+			return list.GetIdentifiers()
+		}
+	}
+	return nodes
+}
+
+// switchCase returns the type of the first THEN or ELSE result whose type
+// is known.
+func (inf *typeInference) switchCase(sc *ast.SwitchCase) string {
+	nodes := parseutil.SignificantNodes(sc)
+	for i := 0; i+1 < len(nodes); i++ {
+		kw := strings.ToUpper(nodes[i].String())
+		if kw != "THEN" && kw != "ELSE" {
+			continue
+		}
+		if typ := inf.infer(nodes[i+1]); typ != "" {
+			return typ
+		}
+	}
+	return ""
+}
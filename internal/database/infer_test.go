@@ -0,0 +1,68 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/parser"
+	"github.com/sqls-server/sqls/parser/parseutil"
+)
+
+func TestInferType(t *testing.T) {
+	cache := &DBCache{
+		defaultSchema: "shop",
+		SchemaTables: map[string][]string{
+			"SHOP": {"orders"},
+		},
+		ColumnsWithParent: map[string][]*ColumnDesc{
+			columnDatabaseKey("shop", "orders"): {
+				{ColumnBase: ColumnBase{Schema: "shop", Table: "orders", Name: "id"}, Type: "int(11)"},
+				{ColumnBase: ColumnBase{Schema: "shop", Table: "orders", Name: "price"}, Type: "decimal(10,2)"},
+				{ColumnBase: ColumnBase{Schema: "shop", Table: "orders", Name: "qty"}, Type: "int(11)"},
+				{ColumnBase: ColumnBase{Schema: "shop", Table: "orders", Name: "note"}, Type: "varchar(255)"},
+				{ColumnBase: ColumnBase{Schema: "shop", Table: "orders", Name: "ordered_on"}, Type: "date"},
+			},
+		},
+	}
+	tables := []*parseutil.TableInfo{{Name: "orders", Alias: "o"}}
+
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{"column", "qty", "int(11)"},
+		{"member column", "o.note", "varchar(255)"},
+		{"unknown column", "missing", ""},
+		{"integer literal", "1", "integer"},
+		{"decimal literal", "1.5", "numeric"},
+		{"string literal", "'a'", "text"},
+		{"count", "count(*) AS n", "bigint"},
+		{"avg", "avg(qty)", "numeric"},
+		{"sum of column", "sum(price)", "decimal(10,2)"},
+		{"coalesce", "coalesce(note, 'none')", "varchar(255)"},
+		{"string function", "upper(note)", "text"},
+		{"cast", "CAST(qty AS text)", "text"},
+		{"cast to a type of several words", "CAST(qty AS double precision)", "double precision"},
+		{"cast to a type with a length", "CAST(qty AS varchar(10))", "varchar(10)"},
+		{"wider operand", "price * qty AS total", "decimal(10,2)"},
+		{"integer operands", "qty + 1", "int(11)"},
+		{"date arithmetic", "ordered_on + 7", "date"},
+		{"parenthesis", "(qty)", "int(11)"},
+		{"case", "CASE WHEN qty > 10 THEN 'bulk' ELSE 'single' END AS bucket", "text"},
+		{"unknown function", "my_func(qty)", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parser.Parse("SELECT " + tt.expr + " FROM orders o")
+			if err != nil {
+				t.Fatalf("error: %+v", err)
+			}
+			stmt := parsed.GetTokens()[0].(ast.TokenList)
+			expr := parseutil.SignificantNodes(stmt)[1]
+			if got := InferType(expr, tables, cache); got != tt.want {
+				t.Errorf("InferType(%q) = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sqls-server/sqls/dialect"
+)
+
+var (
+	selectPattern    = regexp.MustCompile(`(?is)^select\b`)
+	mssqlSelectTop   = regexp.MustCompile(`(?is)^select(\s+(?:all|distinct))?\s+top\b`)
+	mssqlSelectStart = regexp.MustCompile(`(?is)^select(\s+(?:all|distinct))?`)
+)
+
+// LimitQuery restricts a SELECT statement to limit rows using the row
+// limiting syntax of the driver. ok is false when the query is left as is.
+func LimitQuery(driver dialect.DatabaseDriver, query string, limit int) (limited string, ok bool) {
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	if limit <= 0 || !selectPattern.MatchString(query) {
+		return query, false
+	}
+
+	switch driver {
+	case dialect.DatabaseDriverMssql:
+		// ORDER BY is not allowed in a derived table, so add TOP instead.
+		if mssqlSelectTop.MatchString(query) {
+			return query, false
+		}
+		loc := mssqlSelectStart.FindStringIndex(query)
+		return fmt.Sprintf("%s TOP (%d)%s", query[:loc[1]], limit, query[loc[1]:]), true
+	case dialect.DatabaseDriverOracle:
+		return fmt.Sprintf("SELECT * FROM (\n%s\n) WHERE ROWNUM <= %d", query, limit), true
+	default:
+		return fmt.Sprintf("SELECT * FROM (\n%s\n) sqls_limit LIMIT %d", query, limit), true
+	}
+}
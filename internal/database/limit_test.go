@@ -0,0 +1,61 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/sqls-server/sqls/dialect"
+)
+
+func TestLimitQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver dialect.DatabaseDriver
+		query  string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "limit",
+			driver: dialect.DatabaseDriverPostgreSQL,
+			query:  "SELECT * FROM city ORDER BY ID;",
+			want:   "SELECT * FROM (\nSELECT * FROM city ORDER BY ID\n) sqls_limit LIMIT 10",
+			wantOK: true,
+		},
+		{
+			name:   "top",
+			driver: dialect.DatabaseDriverMssql,
+			query:  "select distinct Name from city",
+			want:   "select distinct TOP (10) Name from city",
+			wantOK: true,
+		},
+		{
+			name:   "existing top",
+			driver: dialect.DatabaseDriverMssql,
+			query:  "SELECT TOP 5 Name FROM city",
+			want:   "SELECT TOP 5 Name FROM city",
+			wantOK: false,
+		},
+		{
+			name:   "rownum",
+			driver: dialect.DatabaseDriverOracle,
+			query:  "SELECT Name FROM city",
+			want:   "SELECT * FROM (\nSELECT Name FROM city\n) WHERE ROWNUM <= 10",
+			wantOK: true,
+		},
+		{
+			name:   "not select",
+			driver: dialect.DatabaseDriverMySQL,
+			query:  "SHOW TABLES",
+			want:   "SHOW TABLES",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LimitQuery(tt.driver, tt.query, 10)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("LimitQuery() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Message is output of the server besides results and errors, such as a
+// PostgreSQL NOTICE, a SQL Server PRINT or a MySQL warning.
+type Message struct {
+	Level string `json:"level"`
+	Text  string `json:"text"`
+}
+
+func (m Message) String() string {
+	return m.Level + ": " + m.Text
+}
+
+// MessageCollector gathers the messages of the statements run with a
+// context from WithMessages. Drivers may report messages from their own
+// goroutines, hence the lock.
+type MessageCollector struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (c *MessageCollector) Add(level, text string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, Message{Level: strings.ToUpper(level), Text: text})
+}
+
+// Take returns the messages gathered so far and forgets them.
+func (c *MessageCollector) Take() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	messages := c.messages
+	c.messages = nil
+	return messages
+}
+
+type messagesKey struct{}
+
+// WithMessages returns a context whose statements report the messages of the
+// server to c.
+func WithMessages(ctx context.Context, c *MessageCollector) context.Context {
+	return context.WithValue(ctx, messagesKey{}, c)
+}
+
+func messagesFrom(ctx context.Context) *MessageCollector {
+	c, _ := ctx.Value(messagesKey{}).(*MessageCollector)
+	return c
+}
+
+// messageRoute passes the messages a connection receives outside of any
+// call with a context, such as PostgreSQL notices, to the collector of the
+// statement being run on it.
+type messageRoute struct {
+	mu sync.Mutex
+	c  *MessageCollector
+}
+
+func (r *messageRoute) set(c *MessageCollector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.c = c
+}
+
+func (r *messageRoute) add(level, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.c.Add(level, text)
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/denisenkom/go-mssqldb/msdsn"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMessageCollector(t *testing.T) {
+	c := &MessageCollector{}
+	ctx := WithMessages(context.Background(), c)
+
+	logger := mssqlMessageLogger{}
+	logger.Log(ctx, msdsn.LogMessages, "Changed database context to 'world'.")
+	logger.Log(ctx, msdsn.LogSQL, "SELECT 1")
+	// Messages of statements run without a collector are dropped.
+	logger.Log(context.Background(), msdsn.LogMessages, "lost")
+
+	r := &messageRoute{}
+	r.add("notice", "before any statement")
+	r.set(messagesFrom(ctx))
+	r.add("notice", "relation \"city\" already exists, skipping")
+
+	want := []Message{
+		{Level: "INFO", Text: "Changed database context to 'world'."},
+		{Level: "NOTICE", Text: "relation \"city\" already exists, skipping"},
+	}
+	if diff := cmp.Diff(want, c.Take()); diff != "" {
+		t.Errorf("unmatch messages (- want, + got):\n%s", diff)
+	}
+	if got := c.Take(); len(got) != 0 {
+		t.Errorf("messages were not taken, %v", got)
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"fmt"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 
-	_ "github.com/denisenkom/go-mssqldb"
+	mssqldb "github.com/denisenkom/go-mssqldb"
+	"github.com/denisenkom/go-mssqldb/msdsn"
 	"github.com/jfcote87/sshdb"
 	"github.com/jfcote87/sshdb/mssql"
 	"github.com/sqls-server/sqls/dialect"
@@ -16,6 +19,39 @@ import (
 func init() {
 	RegisterOpen("mssql", mssqlOpen)
 	RegisterFactory("mssql", NewMssqlDBRepository)
+	mssqldb.SetContextLogger(mssqlMessageLogger{})
+}
+
+// mssqlMessageLogger reports the messages of the server, such as PRINT
+// output, to the collector of the statement they belong to.
+type mssqlMessageLogger struct{}
+
+func (mssqlMessageLogger) Log(ctx context.Context, category msdsn.Log, msg string) {
+	if category == msdsn.LogMessages {
+		messagesFrom(ctx).Add("INFO", msg)
+	}
+}
+
+var mssqlLogParamPattern = regexp.MustCompile(`(?i)(^|[;?&])\s*log\s*=`)
+
+// withMssqlMessages turns on the log of server messages, which is how the
+// driver reports them, unless dsn sets the log flags itself.
+func withMssqlMessages(dsn string) string {
+	flags := strconv.Itoa(int(msdsn.LogMessages))
+	if mssqlLogParamPattern.MatchString(dsn) {
+		return dsn
+	}
+	if strings.HasPrefix(dsn, "sqlserver://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		q := u.Query()
+		q.Set("log", flags)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return strings.TrimSuffix(dsn, ";") + ";log=" + flags
 }
 
 func mssqlOpen(dbConnCfg *DBConfig) (*DBConnection, error) {
@@ -26,6 +62,7 @@ func mssqlOpen(dbConnCfg *DBConfig) (*DBConnection, error) {
 	if err != nil {
 		return nil, err
 	}
+	dsn = withMssqlMessages(dsn)
 
 	var tunnel *sshdb.Tunnel
 	if dbConnCfg.SSHCfg != nil {
@@ -227,24 +264,37 @@ func (db *MssqlDBRepository) DescribeDatabaseTable(ctx context.Context) ([]*Colu
 		c.TABLE_NAME,
 		c.COLUMN_NAME,
 		c.DATA_TYPE,
+		c.CHARACTER_MAXIMUM_LENGTH,
 		c.IS_NULLABLE,
-		CASE tc.CONSTRAINT_TYPE
+		CASE pk.CONSTRAINT_TYPE
 			WHEN 'PRIMARY KEY' THEN 'YES'
 			ELSE 'NO'
 		END,
 		c.COLUMN_DEFAULT,
-		''
+		CASE
+			WHEN COLUMNPROPERTY(OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)), c.COLUMN_NAME, 'IsIdentity') = 1 THEN 'IDENTITY'
+			WHEN COLUMNPROPERTY(OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)), c.COLUMN_NAME, 'IsComputed') = 1 THEN 'COMPUTED'
+			ELSE ''
+		END
 	FROM
 		INFORMATION_SCHEMA.COLUMNS c
-	LEFT JOIN
-		INFORMATION_SCHEMA.CONSTRAINT_COLUMN_USAGE ccu
-		ON c.TABLE_NAME = ccu.TABLE_NAME
-		AND c.COLUMN_NAME = ccu.COLUMN_NAME
-	LEFT JOIN INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc ON
-		tc.TABLE_CATALOG = c.TABLE_CATALOG
-		AND tc.TABLE_SCHEMA = c.TABLE_SCHEMA
-		AND tc.TABLE_NAME = c.TABLE_NAME
-		AND tc.CONSTRAINT_NAME = ccu.CONSTRAINT_NAME
+	LEFT JOIN (
+		SELECT
+			ccu.TABLE_SCHEMA,
+			ccu.TABLE_NAME,
+			ccu.COLUMN_NAME,
+			tc.CONSTRAINT_TYPE
+		FROM INFORMATION_SCHEMA.CONSTRAINT_COLUMN_USAGE ccu
+		JOIN INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc ON
+			tc.TABLE_SCHEMA = ccu.TABLE_SCHEMA
+			AND tc.TABLE_NAME = ccu.TABLE_NAME
+			AND tc.CONSTRAINT_NAME = ccu.CONSTRAINT_NAME
+		WHERE
+			tc.CONSTRAINT_TYPE = 'PRIMARY KEY'
+	) AS pk
+		ON c.TABLE_SCHEMA = pk.TABLE_SCHEMA
+		AND c.TABLE_NAME = pk.TABLE_NAME
+		AND c.COLUMN_NAME = pk.COLUMN_NAME
 	ORDER BY
 		c.TABLE_NAME,
 		c.ORDINAL_POSITION
@@ -261,6 +311,7 @@ func (db *MssqlDBRepository) DescribeDatabaseTable(ctx context.Context) ([]*Colu
 			&tableInfo.Table,
 			&tableInfo.Name,
 			&tableInfo.Type,
+			&tableInfo.Length,
 			&tableInfo.Null,
 			&tableInfo.Key,
 			&tableInfo.Default,
@@ -286,24 +337,37 @@ func (db *MssqlDBRepository) DescribeDatabaseTableBySchema(ctx context.Context,
 		c.TABLE_NAME,
 		c.COLUMN_NAME,
 		c.DATA_TYPE,
+		c.CHARACTER_MAXIMUM_LENGTH,
 		c.IS_NULLABLE,
-		CASE tc.CONSTRAINT_TYPE
+		CASE pk.CONSTRAINT_TYPE
 			WHEN 'PRIMARY KEY' THEN 'YES'
 			ELSE 'NO'
 		END,
 		c.COLUMN_DEFAULT,
-		''
+		CASE
+			WHEN COLUMNPROPERTY(OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)), c.COLUMN_NAME, 'IsIdentity') = 1 THEN 'IDENTITY'
+			WHEN COLUMNPROPERTY(OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)), c.COLUMN_NAME, 'IsComputed') = 1 THEN 'COMPUTED'
+			ELSE ''
+		END
 	FROM
 		INFORMATION_SCHEMA.COLUMNS c
-	LEFT JOIN
-		INFORMATION_SCHEMA.CONSTRAINT_COLUMN_USAGE ccu
-		ON c.TABLE_NAME = ccu.TABLE_NAME
-		AND c.COLUMN_NAME = ccu.COLUMN_NAME
-	LEFT JOIN INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc ON
-		tc.TABLE_CATALOG = c.TABLE_CATALOG
-		AND tc.TABLE_SCHEMA = c.TABLE_SCHEMA
-		AND tc.TABLE_NAME = c.TABLE_NAME
-		AND tc.CONSTRAINT_NAME = ccu.CONSTRAINT_NAME
+	LEFT JOIN (
+		SELECT
+			ccu.TABLE_SCHEMA,
+			ccu.TABLE_NAME,
+			ccu.COLUMN_NAME,
+			tc.CONSTRAINT_TYPE
+		FROM INFORMATION_SCHEMA.CONSTRAINT_COLUMN_USAGE ccu
+		JOIN INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc ON
+			tc.TABLE_SCHEMA = ccu.TABLE_SCHEMA
+			AND tc.TABLE_NAME = ccu.TABLE_NAME
+			AND tc.CONSTRAINT_NAME = ccu.CONSTRAINT_NAME
+		WHERE
+			tc.CONSTRAINT_TYPE = 'PRIMARY KEY'
+	) AS pk
+		ON c.TABLE_SCHEMA = pk.TABLE_SCHEMA
+		AND c.TABLE_NAME = pk.TABLE_NAME
+		AND c.COLUMN_NAME = pk.COLUMN_NAME
 	WHERE
 		c.TABLE_SCHEMA = @p1
 	ORDER BY
@@ -322,6 +386,7 @@ func (db *MssqlDBRepository) DescribeDatabaseTableBySchema(ctx context.Context,
 			&tableInfo.Table,
 			&tableInfo.Name,
 			&tableInfo.Type,
+			&tableInfo.Length,
 			&tableInfo.Null,
 			&tableInfo.Key,
 			&tableInfo.Default,
@@ -343,8 +408,10 @@ func (db *MssqlDBRepository) DescribeForeignKeysBySchema(ctx context.Context, sc
 		ctx,
 		`
 		SELECT fk.name,
+		   sch.name,
 		   src_tbl.name,
 		   src_col.name,
+		   dst_sch.name,
 		   dst_tbl.name,
 		   dst_col.name
 	FROM sys.foreign_key_columns fkc
@@ -357,6 +424,8 @@ func (db *MssqlDBRepository) DescribeForeignKeysBySchema(ctx context.Context, sc
 				  ON src_col.column_id = parent_column_id AND src_col.object_id = src_tbl.object_id
 			 JOIN sys.tables dst_tbl
 				  ON dst_tbl.object_id = fkc.referenced_object_id
+			 JOIN sys.schemas dst_sch
+				  ON dst_tbl.schema_id = dst_sch.schema_id
 			 JOIN sys.columns dst_col
 				  ON dst_col.column_id = referenced_column_id AND dst_col.object_id = dst_tbl.object_id
 	where sch.name = @p1
@@ -366,15 +435,88 @@ func (db *MssqlDBRepository) DescribeForeignKeysBySchema(ctx context.Context, sc
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
-	return parseForeignKeys(rows, schemaName)
+	return parseForeignKeys(rows)
+}
+
+func (db *MssqlDBRepository) Views(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT name FROM sys.views WHERE schema_id = SCHEMA_ID() ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *MssqlDBRepository) Functions(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT name
+	FROM sys.objects
+	WHERE schema_id = SCHEMA_ID() AND type IN ('FN', 'IF', 'TF', 'FS', 'FT')
+	ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *MssqlDBRepository) Sequences(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT name FROM sys.sequences WHERE schema_id = SCHEMA_ID() ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
 }
 
-func (db *MssqlDBRepository) Exec(ctx context.Context, query string) (sql.Result, error) {
-	return db.Conn.ExecContext(ctx, query)
+func (db *MssqlDBRepository) Indexes(ctx context.Context, tableName string) ([]*Index, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT i.name, c.name, i.is_unique
+	FROM sys.indexes i
+	JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+	JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+	WHERE i.object_id = OBJECT_ID(@p1) AND i.name IS NOT NULL AND ic.is_included_column = 0
+	ORDER BY i.name, ic.key_ordinal
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseIndexes(rows)
 }
 
-func (db *MssqlDBRepository) Query(ctx context.Context, query string) (*sql.Rows, error) {
-	return db.Conn.QueryContext(ctx, query)
+func (db *MssqlDBRepository) Triggers(ctx context.Context, tableName string) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT name FROM sys.triggers WHERE parent_id = OBJECT_ID(@p1) ORDER BY name
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *MssqlDBRepository) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.Conn.ExecContext(ctx, query, args...)
+}
+
+func (db *MssqlDBRepository) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.Conn.QueryContext(ctx, query, args...)
 }
 
 func genMssqlConfig(connCfg *DBConfig) (string, error) {
@@ -409,3 +551,20 @@ func genMssqlConfig(connCfg *DBConfig) (string, error) {
 
 	return genOptions(q, "", "=", ";", ",", true), nil
 }
+
+func (db *MssqlDBRepository) Explain(ctx context.Context, ex Executor, query string) (*Plan, error) {
+	// SHOWPLAN_XML applies to the session, so keep to one connection.
+	ex, done := pinned(ex, db.Conn, dialect.DatabaseDriverMssql)
+	defer done()
+	if _, err := ex.Exec(ctx, "SET SHOWPLAN_XML ON"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = ex.Exec(context.Background(), "SET SHOWPLAN_XML OFF")
+	}()
+	raw, err := queryString(ctx, ex, query)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMssqlPlan(raw)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/sqls-server/sqls/dialect"
@@ -24,33 +25,37 @@ func init() {
 }
 
 func mysqlOpen(dbConnCfg *DBConfig) (*DBConnection, error) {
-	var (
-		conn    *sql.DB
-		sshConn *ssh.Client
-	)
 	cfg, err := genMysqlConfig(dbConnCfg)
 	if err != nil {
 		return nil, err
 	}
-
 	if dbConnCfg.SSHCfg != nil {
 		// Route the connection through the dialer registered by openMySQLViaSSH.
 		cfg.Net = "mysql+tcp"
-		dbConn, dbSSHConn, err := openMySQLViaSSH(cfg.FormatDSN(), dbConnCfg.SSHCfg)
+	}
+
+	readOnly := dbConnCfg.ReadOnly
+	if readOnly && dbConnCfg.Driver != dialect.DatabaseDriverMySQL {
+		setMySQLParam(cfg, mysqlReadOnlyVariable(dbConnCfg.Driver, ""), "1")
+		readOnly = false
+	}
+	conn, sshConn, err := connectMySQL(cfg, dbConnCfg.SSHCfg)
+	if err != nil {
+		return nil, err
+	}
+	if readOnly {
+		// The generic driver serves any version of MySQL and MariaDB, so the
+		// variable is told by the server, and only new connections get it.
+		var version string
+		err := conn.QueryRowContext(context.Background(), "SELECT VERSION()").Scan(&version)
+		closeMySQL(conn, sshConn)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("cannot read the server version, %w", err)
 		}
-		conn = dbConn
-		sshConn = dbSSHConn
-	} else {
-		dbConn, err := sql.Open("mysql", cfg.FormatDSN())
-		if err != nil {
+		setMySQLParam(cfg, mysqlReadOnlyVariable(dbConnCfg.Driver, version), "1")
+		if conn, sshConn, err = connectMySQL(cfg, dbConnCfg.SSHCfg); err != nil {
 			return nil, err
 		}
-		conn = dbConn
-	}
-	if err := conn.PingContext(context.Background()); err != nil {
-		return nil, fmt.Errorf("cannot ping to database, %w", err)
 	}
 
 	conn.SetMaxIdleConns(DefaultMaxIdleConns)
@@ -63,6 +68,95 @@ func mysqlOpen(dbConnCfg *DBConfig) (*DBConnection, error) {
 	}, nil
 }
 
+// connectMySQL opens the database of cfg, through sshCfg when given, and
+// checks that it can be reached.
+func connectMySQL(cfg *mysql.Config, sshCfg *SSHConfig) (*sql.DB, *ssh.Client, error) {
+	var (
+		conn    *sql.DB
+		sshConn *ssh.Client
+		err     error
+	)
+	if sshCfg != nil {
+		conn, sshConn, err = openMySQLViaSSH(cfg.FormatDSN(), sshCfg)
+	} else {
+		conn, err = sql.Open("mysql", cfg.FormatDSN())
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := conn.PingContext(context.Background()); err != nil {
+		closeMySQL(conn, sshConn)
+		return nil, nil, fmt.Errorf("cannot ping to database, %w", err)
+	}
+	return conn, sshConn, nil
+}
+
+func closeMySQL(conn *sql.DB, sshConn *ssh.Client) {
+	_ = conn.Close()
+	if sshConn != nil {
+		_ = sshConn.Close()
+	}
+}
+
+// mysqlReadOnlyVariable returns the session variable which makes
+// transactions read-only on the server of driver, or for the generic driver
+// on the server of version. It was renamed to transaction_read_only in MySQL
+// 5.7.20 and MariaDB 11.1, and the old name dropped in MySQL 8.0.
+func mysqlReadOnlyVariable(driver dialect.DatabaseDriver, version string) string {
+	switch driver {
+	case dialect.DatabaseDriverMySQL56, dialect.DatabaseDriverMySQL57:
+		return "tx_read_only"
+	case dialect.DatabaseDriverMySQL8:
+		return "transaction_read_only"
+	}
+	renamed := [3]int{5, 7, 20}
+	if isMariaDB(version) {
+		renamed = [3]int{11, 1, 0}
+	}
+	if compareVersion(parseVersion(version), renamed) >= 0 {
+		return "transaction_read_only"
+	}
+	return "tx_read_only"
+}
+
+func isMariaDB(version string) bool {
+	return strings.Contains(strings.ToLower(version), "mariadb")
+}
+
+// parseVersion reads the major, minor and patch numbers at the start of a
+// server version such as "8.0.35-log".
+func parseVersion(version string) [3]int {
+	var v [3]int
+	for i, part := range strings.SplitN(version, ".", 3) {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		v[i], _ = strconv.Atoi(part[:end])
+		if end < len(part) {
+			break
+		}
+	}
+	return v
+}
+
+func compareVersion(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
+
+// setMySQLParam sets a session variable of the connections of cfg.
+func setMySQLParam(cfg *mysql.Config, name, value string) {
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	cfg.Params[name] = value
+}
+
 type MySQLViaSSHDialer struct {
 	client *ssh.Client
 }
@@ -325,8 +419,10 @@ func (db *MySQLDBRepository) DescribeForeignKeysBySchema(ctx context.Context, sc
 		ctx,
 		`
 		select fks.CONSTRAINT_NAME,
+		   fks.CONSTRAINT_SCHEMA,
 		   fks.TABLE_NAME,
 		   kcu.COLUMN_NAME,
+		   kcu.REFERENCED_TABLE_SCHEMA,
 		   fks.REFERENCED_TABLE_NAME,
 		   kcu.REFERENCED_COLUMN_NAME
 	from INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS fks
@@ -342,13 +438,139 @@ func (db *MySQLDBRepository) DescribeForeignKeysBySchema(ctx context.Context, sc
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
-	return parseForeignKeys(rows, schemaName)
+	return parseForeignKeys(rows)
 }
 
-func (db *MySQLDBRepository) Exec(ctx context.Context, query string) (sql.Result, error) {
-	return db.Conn.ExecContext(ctx, query)
+func (db *MySQLDBRepository) Views(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT TABLE_NAME
+	FROM information_schema.VIEWS
+	WHERE TABLE_SCHEMA = DATABASE()
+	ORDER BY TABLE_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
 }
 
-func (db *MySQLDBRepository) Query(ctx context.Context, query string) (*sql.Rows, error) {
-	return db.Conn.QueryContext(ctx, query)
+func (db *MySQLDBRepository) Functions(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT ROUTINE_NAME
+	FROM information_schema.ROUTINES
+	WHERE ROUTINE_SCHEMA = DATABASE() AND ROUTINE_TYPE = 'FUNCTION'
+	ORDER BY ROUTINE_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *MySQLDBRepository) Sequences(ctx context.Context) ([]string, error) {
+	// Only MariaDB has sequences, MySQL returns no rows
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT TABLE_NAME
+	FROM information_schema.TABLES
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'SEQUENCE'
+	ORDER BY TABLE_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *MySQLDBRepository) Indexes(ctx context.Context, tableName string) ([]*Index, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE = 0
+	FROM information_schema.STATISTICS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+	ORDER BY INDEX_NAME, SEQ_IN_INDEX
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseIndexes(rows)
+}
+
+func (db *MySQLDBRepository) Triggers(ctx context.Context, tableName string) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT TRIGGER_NAME
+	FROM information_schema.TRIGGERS
+	WHERE EVENT_OBJECT_SCHEMA = DATABASE() AND EVENT_OBJECT_TABLE = ?
+	ORDER BY TRIGGER_NAME
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *MySQLDBRepository) ShowCreateTable(ctx context.Context, schemaName, tableName string) (string, error) {
+	name := quoteMySQLIdentifier(tableName)
+	if schemaName != "" {
+		name = quoteMySQLIdentifier(schemaName) + "." + name
+	}
+	var table, ddl string
+	if err := db.Conn.QueryRowContext(ctx, "SHOW CREATE TABLE "+name).Scan(&table, &ddl); err != nil {
+		return "", err
+	}
+	return ddl + ";\n", nil
+}
+
+func quoteMySQLIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (db *MySQLDBRepository) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.Conn.ExecContext(ctx, query, args...)
+}
+
+func (db *MySQLDBRepository) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.Conn.QueryContext(ctx, query, args...)
+}
+
+func (db *MySQLDBRepository) Explain(ctx context.Context, ex Executor, query string) (*Plan, error) {
+	raw, err := queryString(ctx, ex, "EXPLAIN FORMAT=JSON "+query)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMySQLPlan(raw)
+}
+
+// readMySQLWarnings reads the warnings, notes and errors of the last
+// statement run on ex.
+func readMySQLWarnings(ctx context.Context, ex sessionExecer, c *MessageCollector) error {
+	rows, err := ex.QueryContext(ctx, "SHOW WARNINGS")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			level, message string
+			code           int
+		)
+		if err := rows.Scan(&level, &code, &message); err != nil {
+			return err
+		}
+		c.Add(level, message)
+	}
+	return rows.Err()
 }
@@ -2,6 +2,8 @@ package database
 
 import (
 	"testing"
+
+	"github.com/sqls-server/sqls/dialect"
 )
 
 func Test_genMysqlConfig(t *testing.T) {
@@ -55,3 +57,28 @@ func Test_genMysqlConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_mysqlReadOnlyVariable(t *testing.T) {
+	tests := []struct {
+		driver  dialect.DatabaseDriver
+		version string
+		want    string
+	}{
+		{dialect.DatabaseDriverMySQL56, "", "tx_read_only"},
+		{dialect.DatabaseDriverMySQL57, "", "tx_read_only"},
+		{dialect.DatabaseDriverMySQL8, "", "transaction_read_only"},
+		{dialect.DatabaseDriverMySQL, "5.6.51-log", "tx_read_only"},
+		{dialect.DatabaseDriverMySQL, "5.7.19", "tx_read_only"},
+		{dialect.DatabaseDriverMySQL, "5.7.20", "transaction_read_only"},
+		{dialect.DatabaseDriverMySQL, "8.0.35", "transaction_read_only"},
+		{dialect.DatabaseDriverMySQL, "10.11.6-MariaDB-0+deb12u1", "tx_read_only"},
+		{dialect.DatabaseDriverMySQL, "11.1.2-MariaDB", "transaction_read_only"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.driver)+" "+tt.version, func(t *testing.T) {
+			if got := mysqlReadOnlyVariable(tt.driver, tt.version); got != tt.want {
+				t.Errorf("mysqlReadOnlyVariable(%q, %q) = %q, want %q", tt.driver, tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"database/sql"
 	"log"
 	"strconv"
+	"strings"
 
-	_ "github.com/godror/godror"
+	"github.com/godror/godror"
 	"github.com/sqls-server/sqls/dialect"
 )
 
@@ -164,6 +165,7 @@ OWNER,
 TABLE_NAME,
 COLUMN_NAME,
 DATA_TYPE,
+CHAR_LENGTH,
 NULLABLE,
 '',
 DATA_DEFAULT,
@@ -182,6 +184,7 @@ FROM SYS.ALL_TAB_COLUMNS
 			&tableInfo.Table,
 			&tableInfo.Name,
 			&tableInfo.Type,
+			&tableInfo.Length,
 			&tableInfo.Null,
 			&tableInfo.Key,
 			&tableInfo.Default,
@@ -207,6 +210,7 @@ func (db *OracleDBRepository) DescribeDatabaseTableBySchema(ctx context.Context,
 		TABLE_NAME,
 		COLUMN_NAME,
 		DATA_TYPE,
+		CHAR_LENGTH,
 		CASE NULLABLE
 		WHEN 'Y' THEN 'YES'
 		ELSE 'NO'
@@ -230,6 +234,7 @@ func (db *OracleDBRepository) DescribeDatabaseTableBySchema(ctx context.Context,
 			&tableInfo.Table,
 			&tableInfo.Name,
 			&tableInfo.Type,
+			&tableInfo.Length,
 			&tableInfo.Null,
 			&tableInfo.Key,
 			&tableInfo.Default,
@@ -251,8 +256,10 @@ func (db *OracleDBRepository) DescribeForeignKeysBySchema(ctx context.Context, s
 		ctx,
 		`
 		SELECT a.CONSTRAINT_NAME,
+		   a.OWNER,
 		   a.TABLE_NAME,
 		   a.COLUMN_NAME,
+		   b.OWNER,
 		   b.TABLE_NAME,
 		   b.COLUMN_NAME
 	FROM ALL_CONS_COLUMNS a
@@ -260,7 +267,8 @@ func (db *OracleDBRepository) DescribeForeignKeysBySchema(ctx context.Context, s
 		AND a.CONSTRAINT_NAME = c.CONSTRAINT_NAME
 			 JOIN ALL_CONSTRAINTS c_pk ON c.R_OWNER = c_pk.OWNER
 		AND c.R_CONSTRAINT_NAME = c_pk.CONSTRAINT_NAME
-			 JOIN ALL_CONS_COLUMNS b ON b.CONSTRAINT_NAME = c_pk.CONSTRAINT_NAME
+			 JOIN ALL_CONS_COLUMNS b ON b.OWNER = c_pk.OWNER
+		AND b.CONSTRAINT_NAME = c_pk.CONSTRAINT_NAME
 		AND b.POSITION = a.POSITION
 	WHERE c.constraint_type = 'R'
 	  AND a.OWNER = :1
@@ -270,13 +278,163 @@ func (db *OracleDBRepository) DescribeForeignKeysBySchema(ctx context.Context, s
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
-	return parseForeignKeys(rows, schemaName)
+	return parseForeignKeys(rows)
 }
 
-func (db *OracleDBRepository) Exec(ctx context.Context, query string) (sql.Result, error) {
-	return db.Conn.ExecContext(ctx, query)
+func (db *OracleDBRepository) Views(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT VIEW_NAME
+	FROM ALL_VIEWS
+	WHERE OWNER = SYS_CONTEXT('USERENV','CURRENT_SCHEMA')
+	ORDER BY VIEW_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *OracleDBRepository) Functions(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT OBJECT_NAME
+	FROM ALL_OBJECTS
+	WHERE OWNER = SYS_CONTEXT('USERENV','CURRENT_SCHEMA') AND OBJECT_TYPE = 'FUNCTION'
+	ORDER BY OBJECT_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *OracleDBRepository) Sequences(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT SEQUENCE_NAME
+	FROM ALL_SEQUENCES
+	WHERE SEQUENCE_OWNER = SYS_CONTEXT('USERENV','CURRENT_SCHEMA')
+	ORDER BY SEQUENCE_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *OracleDBRepository) Indexes(ctx context.Context, tableName string) ([]*Index, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT i.INDEX_NAME,
+	       c.COLUMN_NAME,
+	       CASE i.UNIQUENESS WHEN 'UNIQUE' THEN 1 ELSE 0 END
+	FROM ALL_INDEXES i
+	JOIN ALL_IND_COLUMNS c ON c.INDEX_OWNER = i.OWNER AND c.INDEX_NAME = i.INDEX_NAME
+	WHERE i.TABLE_OWNER = SYS_CONTEXT('USERENV','CURRENT_SCHEMA') AND i.TABLE_NAME = :1
+	ORDER BY i.INDEX_NAME, c.COLUMN_POSITION
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseIndexes(rows)
+}
+
+func (db *OracleDBRepository) Triggers(ctx context.Context, tableName string) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT TRIGGER_NAME
+	FROM ALL_TRIGGERS
+	WHERE TABLE_OWNER = SYS_CONTEXT('USERENV','CURRENT_SCHEMA') AND TABLE_NAME = :1
+	ORDER BY TRIGGER_NAME
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *OracleDBRepository) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.Conn.ExecContext(ctx, query, args...)
+}
+
+func (db *OracleDBRepository) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.Conn.QueryContext(ctx, query, args...)
+}
+
+func (db *OracleDBRepository) Explain(ctx context.Context, ex Executor, query string) (*Plan, error) {
+	// PLAN_TABLE is a temporary table, so keep to one session.
+	ex, done := pinned(ex, db.Conn, dialect.DatabaseDriverOracle)
+	defer done()
+
+	const statementID = "sqls_explain"
+	if _, err := ex.Exec(ctx, "EXPLAIN PLAN SET STATEMENT_ID = '"+statementID+"' FOR "+query); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = ex.Exec(context.Background(), "DELETE FROM PLAN_TABLE WHERE STATEMENT_ID = :1", statementID)
+	}()
+
+	rows, err := ex.Query(ctx, `
+	SELECT ID, PARENT_ID, OPERATION, OPTIONS, OBJECT_NAME, COST, CARDINALITY
+	FROM PLAN_TABLE
+	WHERE STATEMENT_ID = :1
+	ORDER BY ID
+	`, statementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var planRows []*OraclePlanRow
+	for rows.Next() {
+		var r OraclePlanRow
+		if err := rows.Scan(&r.ID, &r.ParentID, &r.Operation, &r.Options, &r.ObjectName, &r.Cost, &r.Cardinality); err != nil {
+			return nil, err
+		}
+		planRows = append(planRows, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	xplan, err := ex.Query(ctx, "SELECT PLAN_TABLE_OUTPUT FROM TABLE(DBMS_XPLAN.DISPLAY('PLAN_TABLE', :1, 'TYPICAL'))", statementID)
+	if err != nil {
+		return nil, err
+	}
+	defer xplan.Close()
+	lines, err := parseNames(xplan)
+	if err != nil {
+		return nil, err
+	}
+	return BuildOraclePlan(planRows, strings.Join(lines, "\n")), nil
 }
 
-func (db *OracleDBRepository) Query(ctx context.Context, query string) (*sql.Rows, error) {
-	return db.Conn.QueryContext(ctx, query)
+// enableOracleOutput makes the session buffer DBMS_OUTPUT for
+// readOracleOutput.
+func enableOracleOutput(ctx context.Context, conn *sql.Conn) error {
+	return godror.EnableDbmsOutput(ctx, conn)
+}
+
+// readOracleOutput reads the lines of DBMS_OUTPUT buffered by the session.
+func readOracleOutput(ctx context.Context, ex sessionExecer, c *MessageCollector) error {
+	var b strings.Builder
+	if err := godror.ReadDbmsOutput(ctx, &b, ex); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		if line != "" {
+			c.Add("OUTPUT", line)
+		}
+	}
+	return nil
 }
@@ -9,7 +9,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/sqls-server/sqls/dialect"
@@ -30,20 +32,27 @@ func postgreSQLOpen(dbConnCfg *DBConfig) (*DBConnection, error) {
 	if err != nil {
 		return nil, err
 	}
+	conf, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	conf.OnNotice = postgreSQLNotice
+	if dbConnCfg.ReadOnly {
+		conf.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+	if timeout := dbConnCfg.QueryTimeoutDuration(); timeout > 0 {
+		conf.RuntimeParams["statement_timeout"] = strconv.FormatInt(timeout.Milliseconds(), 10)
+	}
 
 	if dbConnCfg.SSHCfg != nil {
-		dbConn, dbSSHConn, err := openPostgreSQLViaSSH(dsn, dbConnCfg.SSHCfg)
+		dbConn, dbSSHConn, err := openPostgreSQLViaSSH(conf, dbConnCfg.SSHCfg)
 		if err != nil {
 			return nil, err
 		}
 		conn = dbConn
 		sshConn = dbSSHConn
 	} else {
-		dbConn, err := sql.Open("pgx", dsn)
-		if err != nil {
-			return nil, err
-		}
-		conn = dbConn
+		conn = stdlib.OpenDB(*conf)
 	}
 	if err = conn.PingContext(context.Background()); err != nil {
 		return nil, err
@@ -58,7 +67,7 @@ func postgreSQLOpen(dbConnCfg *DBConfig) (*DBConnection, error) {
 	}, nil
 }
 
-func openPostgreSQLViaSSH(dsn string, sshCfg *SSHConfig) (*sql.DB, *ssh.Client, error) {
+func openPostgreSQLViaSSH(conf *pgx.ConnConfig, sshCfg *SSHConfig) (*sql.DB, *ssh.Client, error) {
 	sshConfig, err := sshCfg.ClientConfig()
 	if err != nil {
 		return nil, nil, err
@@ -68,10 +77,6 @@ func openPostgreSQLViaSSH(dsn string, sshCfg *SSHConfig) (*sql.DB, *ssh.Client,
 		return nil, nil, fmt.Errorf("cannot ssh dial, %w", err)
 	}
 
-	conf, err := pgx.ParseConfig(dsn)
-	if err != nil {
-		return nil, nil, err
-	}
 	conf.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
 		return sshConn.Dial(network, addr)
 	}
@@ -81,6 +86,35 @@ func openPostgreSQLViaSSH(dsn string, sshCfg *SSHConfig) (*sql.DB, *ssh.Client,
 	return conn, sshConn, nil
 }
 
+// postgreSQLNoticeRoutes holds the message route of each pinned connection,
+// as notices are not reported with the context of the statement.
+var postgreSQLNoticeRoutes sync.Map
+
+func postgreSQLNotice(pgConn *pgconn.PgConn, n *pgconn.Notice) {
+	if r, ok := postgreSQLNoticeRoutes.Load(pgConn); ok {
+		r.(*messageRoute).add(n.Severity, n.Message)
+	}
+}
+
+// routePostgreSQLNotices passes the notices of conn to r until the returned
+// function is called.
+func routePostgreSQLNotices(conn *sql.Conn, r *messageRoute) (func(), error) {
+	var pgConn *pgconn.PgConn
+	err := conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected postgresql connection %T", driverConn)
+		}
+		pgConn = c.Conn().PgConn()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	postgreSQLNoticeRoutes.Store(pgConn, r)
+	return func() { postgreSQLNoticeRoutes.Delete(pgConn) }, nil
+}
+
 type PostgreSQLDBRepository struct {
 	Conn *sql.DB
 }
@@ -162,6 +196,32 @@ func (db *PostgreSQLDBRepository) Schemas(ctx context.Context) ([]string, error)
 	return databases, nil
 }
 
+// SearchPath returns the existing schemas of the session search_path, with
+// "$user" already expanded by the server.
+func (db *PostgreSQLDBRepository) SearchPath(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT unnest(current_schemas(false))
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	schemas := []string{}
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
 func (db *PostgreSQLDBRepository) SchemaTables(ctx context.Context) (map[string][]string, error) {
 	rows, err := db.Conn.QueryContext(
 		ctx,
@@ -238,7 +298,11 @@ func (db *PostgreSQLDBRepository) DescribeDatabaseTable(ctx context.Context) ([]
 		c.table_schema,
 		c.table_name,
 		c.column_name,
-		c.data_type,
+		CASE c.data_type
+			WHEN 'USER-DEFINED' THEN c.udt_schema || '.' || c.udt_name
+			ELSE c.data_type
+		END,
+		c.character_maximum_length,
 		c.is_nullable,
 		CASE t.constraint_type
 			WHEN 'PRIMARY KEY' THEN 'YES'
@@ -281,6 +345,7 @@ func (db *PostgreSQLDBRepository) DescribeDatabaseTable(ctx context.Context) ([]
 			&tableInfo.Table,
 			&tableInfo.Name,
 			&tableInfo.Type,
+			&tableInfo.Length,
 			&tableInfo.Null,
 			&tableInfo.Key,
 			&tableInfo.Default,
@@ -305,7 +370,11 @@ func (db *PostgreSQLDBRepository) DescribeDatabaseTableBySchema(ctx context.Cont
 		c.table_schema,
 		c.table_name,
 		c.column_name,
-		c.data_type,
+		CASE c.data_type
+			WHEN 'USER-DEFINED' THEN c.udt_schema || '.' || c.udt_name
+			ELSE c.data_type
+		END,
+		c.character_maximum_length,
 		c.is_nullable,
 		CASE t.constraint_type
 			WHEN 'PRIMARY KEY' THEN 'YES'
@@ -351,6 +420,7 @@ func (db *PostgreSQLDBRepository) DescribeDatabaseTableBySchema(ctx context.Cont
 			&tableInfo.Table,
 			&tableInfo.Name,
 			&tableInfo.Type,
+			&tableInfo.Length,
 			&tableInfo.Null,
 			&tableInfo.Key,
 			&tableInfo.Default,
@@ -367,13 +437,55 @@ func (db *PostgreSQLDBRepository) DescribeDatabaseTableBySchema(ctx context.Cont
 	return tableInfos, nil
 }
 
+// DescribeEnumTypes returns the labels of every enum type in sort order.
+func (db *PostgreSQLDBRepository) DescribeEnumTypes(ctx context.Context) ([]*EnumType, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT
+		n.nspname,
+		t.typname,
+		e.enumlabel
+	FROM pg_type t
+	JOIN pg_enum e ON t.oid = e.enumtypid
+	JOIN pg_namespace n ON n.oid = t.typnamespace
+	ORDER BY
+		n.nspname,
+		t.typname,
+		e.enumsortorder
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	enums := []*EnumType{}
+	var cur *EnumType
+	for rows.Next() {
+		var schema, name, label string
+		if err := rows.Scan(&schema, &name, &label); err != nil {
+			return nil, err
+		}
+		if cur == nil || cur.Schema != schema || cur.Name != name {
+			cur = &EnumType{Schema: schema, Name: name}
+			enums = append(enums, cur)
+		}
+		cur.Values = append(cur.Values, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return enums, nil
+}
+
 func (db *PostgreSQLDBRepository) DescribeForeignKeysBySchema(ctx context.Context, schemaName string) ([]*ForeignKey, error) {
 	rows, err := db.Conn.QueryContext(
 		ctx,
 		`
 	select kcu.CONSTRAINT_NAME,
+       kcu.TABLE_SCHEMA,
        kcu.TABLE_NAME,
        kcu.COLUMN_NAME,
+       rel_kcu.TABLE_SCHEMA,
        rel_kcu.TABLE_NAME,
        rel_kcu.COLUMN_NAME
 	from INFORMATION_SCHEMA.TABLE_CONSTRAINTS tco
@@ -396,15 +508,103 @@ func (db *PostgreSQLDBRepository) DescribeForeignKeysBySchema(ctx context.Contex
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
-	return parseForeignKeys(rows, schemaName)
+	return parseForeignKeys(rows)
+}
+
+func (db *PostgreSQLDBRepository) Views(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT c.relname
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = current_schema() AND c.relkind IN ('v', 'm')
+	ORDER BY c.relname
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *PostgreSQLDBRepository) Functions(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT DISTINCT routine_name
+	FROM information_schema.routines
+	WHERE routine_schema = current_schema() AND routine_type = 'FUNCTION'
+	ORDER BY routine_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *PostgreSQLDBRepository) Sequences(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT sequence_name
+	FROM information_schema.sequences
+	WHERE sequence_schema = current_schema()
+	ORDER BY sequence_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
 }
 
-func (db *PostgreSQLDBRepository) Exec(ctx context.Context, query string) (sql.Result, error) {
-	return db.Conn.ExecContext(ctx, query)
+func (db *PostgreSQLDBRepository) Indexes(ctx context.Context, tableName string) ([]*Index, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT i.relname, a.attname, ix.indisunique
+	FROM pg_index ix
+	JOIN pg_class t ON t.oid = ix.indrelid
+	JOIN pg_class i ON i.oid = ix.indexrelid
+	JOIN pg_namespace n ON n.oid = t.relnamespace
+	CROSS JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
+	LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+	WHERE n.nspname = current_schema() AND t.relname = $1
+	ORDER BY i.relname, k.ord
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseIndexes(rows)
 }
 
-func (db *PostgreSQLDBRepository) Query(ctx context.Context, query string) (*sql.Rows, error) {
-	return db.Conn.QueryContext(ctx, query)
+func (db *PostgreSQLDBRepository) Triggers(ctx context.Context, tableName string) ([]string, error) {
+	rows, err := db.Conn.QueryContext(
+		ctx,
+		`
+	SELECT t.tgname
+	FROM pg_trigger t
+	JOIN pg_class c ON c.oid = t.tgrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = current_schema() AND c.relname = $1 AND NOT t.tgisinternal
+	ORDER BY t.tgname
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *PostgreSQLDBRepository) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.Conn.ExecContext(ctx, query, args...)
+}
+
+func (db *PostgreSQLDBRepository) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.Conn.QueryContext(ctx, query, args...)
 }
 
 func genPostgresConfig(connCfg *DBConfig) (string, error) {
@@ -500,3 +700,11 @@ func genOptions(q url.Values, joiner, assign, sep, valSep string, skipWhenEmpty
 
 	return ""
 }
+
+func (db *PostgreSQLDBRepository) Explain(ctx context.Context, ex Executor, query string) (*Plan, error) {
+	raw, err := queryString(ctx, ex, "EXPLAIN (FORMAT JSON) "+query)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostgreSQLPlan(raw)
+}
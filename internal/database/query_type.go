@@ -18,7 +18,9 @@ var queryMap = map[string]bool{
 	"VALUES":   true, // compute a set of rows
 	"LIST":     true, //  list permissions, roles, users [cassandra]
 
-	"EXEC": true, // execute a stored procedure that returns rows (not postgres)
+	"EXEC":    true, // execute a stored procedure that returns rows (not postgres)
+	"EXECUTE": true, // execute a stored procedure or prepared statement that may return rows
+	"CALL":    true, // call a procedure that may return result sets
 }
 
 // execMap is the map of SQL prefixes to execute.
@@ -174,7 +176,6 @@ var execMap = map[string]bool{
 	"DROP USER":                        true, // remove a database role
 	"DROP VIEW":                        true, // remove a view
 	"END":                              true, // commit the current transaction
-	"EXECUTE":                          true, // execute a prepared statement
 	"GRANT":                            true, // define access privileges
 	"IMPORT FOREIGN SCHEMA":            true, // import table definitions from a foreign server
 	"INSERT":                           true, // create new rows in a table
@@ -77,6 +77,20 @@ func TestQueryExecType(t *testing.T) {
 			wantPrefix:   "DELETE",
 			wantExecType: false,
 		},
+		{
+			name:         "call",
+			prefix:       "call city_stats();",
+			sqlstr:       "",
+			wantPrefix:   "CALL",
+			wantExecType: true,
+		},
+		{
+			name:         "execute",
+			prefix:       "EXECUTE dbo.city_stats",
+			sqlstr:       "",
+			wantPrefix:   "EXECUTE",
+			wantExecType: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -27,34 +27,6 @@ func Columns(rows *sql.Rows) ([]string, error) {
 	return cols, nil
 }
 
-func ScanRows(rows *sql.Rows, columnLength int) ([][]string, error) {
-	stringRows := [][]string{}
-	for rows.Next() {
-		// scan to []interface{}
-		rowBuffer := make([]interface{}, columnLength)
-		for i := range rowBuffer {
-			rowBuffer[i] = new(interface{})
-		}
-		if err := rows.Scan(rowBuffer...); err != nil {
-			return nil, err
-		}
-
-		stringRow := make([]string, columnLength)
-		for i, buf := range rowBuffer {
-			val, err := sqlValToString(buf)
-			if err != nil {
-				return nil, err
-			}
-			stringRow[i] = val
-		}
-		stringRows = append(stringRows, stringRow)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return stringRows, nil
-}
-
 func sqlValToString(pointer interface{}) (string, error) {
 	res := ""
 	if pointer == nil {
@@ -100,3 +72,187 @@ func sqlValToString(pointer interface{}) (string, error) {
 
 	return res, nil
 }
+
+// Result is a scanned result set. Its values keep the type they were scanned
+// as, with []byte converted to string and pointers dereferenced. A NULL is
+// kept as nil, distinct from an empty string.
+type Result struct {
+	Columns []*ResultColumn
+	Rows    [][]interface{}
+}
+
+// ResultColumn describes a column of a Result. Metadata the driver does not
+// report is left nil.
+type ResultColumn struct {
+	Name         string
+	DatabaseType string
+	Nullable     *bool
+	Length       *int64
+	Precision    *int64
+	Scale        *int64
+	// ScanType is the Go type the driver scans the column into, if known.
+	ScanType reflect.Type
+}
+
+func newResultColumn(name string, colType *sql.ColumnType) *ResultColumn {
+	col := &ResultColumn{Name: name}
+	if colType == nil {
+		return col
+	}
+	col.DatabaseType = colType.DatabaseTypeName()
+	col.ScanType = colType.ScanType()
+	if nullable, ok := colType.Nullable(); ok {
+		col.Nullable = &nullable
+	}
+	if length, ok := colType.Length(); ok {
+		col.Length = &length
+	}
+	if precision, scale, ok := colType.DecimalSize(); ok {
+		col.Precision = &precision
+		col.Scale = &scale
+	}
+	return col
+}
+
+// ResultReader scans a result set a page at a time. It does not close rows.
+type ResultReader struct {
+	rows     *sql.Rows
+	columns  []*ResultColumn
+	pending  []interface{}
+	firstRow time.Time
+}
+
+func NewResultReader(rows *sql.Rows) (*ResultReader, error) {
+	columns, err := resultColumns(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultReader{rows: rows, columns: columns}, nil
+}
+
+func resultColumns(rows *sql.Rows) ([]*ResultColumn, error) {
+	names, err := Columns(rows)
+	if err != nil {
+		return nil, err
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get query column types, %w", err)
+	}
+	columns := make([]*ResultColumn, len(names))
+	for i, name := range names {
+		var colType *sql.ColumnType
+		if i < len(colTypes) {
+			colType = colTypes[i]
+		}
+		columns[i] = newResultColumn(name, colType)
+	}
+	return columns, nil
+}
+
+// NextResultSet moves to the next result set of a batch or procedure call,
+// dropping the rows left in the current one, and reports whether there is
+// one.
+func (r *ResultReader) NextResultSet() (bool, error) {
+	if !r.rows.NextResultSet() {
+		return false, r.rows.Err()
+	}
+	columns, err := resultColumns(r.rows)
+	if err != nil {
+		return false, err
+	}
+	r.columns = columns
+	r.pending = nil
+	r.firstRow = time.Time{}
+	return true, nil
+}
+
+func (r *ResultReader) Columns() []*ResultColumn {
+	return r.columns
+}
+
+// FirstRowAt returns when the first row was read, or the zero time if no row
+// has been read yet.
+func (r *ResultReader) FirstRowAt() time.Time {
+	return r.firstRow
+}
+
+// Next scans up to limit rows, or all remaining rows when limit is not
+// positive. more reports whether rows remain after the returned ones.
+func (r *ResultReader) Next(limit int) (res *Result, more bool, err error) {
+	res = &Result{
+		Columns: r.columns,
+		Rows:    [][]interface{}{},
+	}
+	if r.pending != nil {
+		res.Rows = append(res.Rows, r.pending)
+		r.pending = nil
+	}
+	for limit <= 0 || len(res.Rows) < limit {
+		row, ok, err := r.scan()
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return res, false, nil
+		}
+		res.Rows = append(res.Rows, row)
+	}
+
+	// Read ahead one row to tell whether there are more.
+	row, ok, err := r.scan()
+	if err != nil {
+		return nil, false, err
+	}
+	r.pending = row
+	return res, ok, nil
+}
+
+func (r *ResultReader) scan() ([]interface{}, bool, error) {
+	if !r.rows.Next() {
+		return nil, false, r.rows.Err()
+	}
+	if r.firstRow.IsZero() {
+		r.firstRow = time.Now()
+	}
+	rowBuffer := make([]interface{}, len(r.columns))
+	for i := range rowBuffer {
+		rowBuffer[i] = new(interface{})
+	}
+	if err := r.rows.Scan(rowBuffer...); err != nil {
+		return nil, false, err
+	}
+
+	row := make([]interface{}, len(r.columns))
+	for i, buf := range rowBuffer {
+		row[i] = normalizeValue(*buf.(*interface{}))
+	}
+	return row, true, nil
+}
+
+func normalizeValue(val interface{}) interface{} {
+	reflectVal := reflect.ValueOf(val)
+	if reflectVal.Kind() == reflect.Pointer {
+		if reflectVal.IsNil() {
+			return nil
+		}
+		val = reflectVal.Elem().Interface()
+	}
+
+	switch v := val.(type) {
+	case nil, string, bool, time.Time, map[string]interface{}, []interface{},
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// ValueString formats a value of a Result as text.
+func ValueString(val interface{}) (string, error) {
+	return sqlValToString(&val)
+}
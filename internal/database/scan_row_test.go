@@ -6,11 +6,12 @@ import (
 	"database/sql/driver"
 	"errors"
 	"io"
+	"reflect"
 	"sync"
 	"testing"
 )
 
-func TestScanRows_returnsRowsErr(t *testing.T) {
+func TestResultReader_returnsRowsErr(t *testing.T) {
 	db := openScanRowsTestDB(t)
 	defer db.Close()
 
@@ -20,14 +21,14 @@ func TestScanRows_returnsRowsErr(t *testing.T) {
 	}
 	defer rows.Close()
 
-	columns, err := Columns(rows)
+	reader, err := NewResultReader(rows)
 	if err != nil {
-		t.Fatalf("Columns() error = %v", err)
+		t.Fatalf("NewResultReader() error = %v", err)
 	}
 
-	_, err = ScanRows(rows, len(columns))
+	_, _, err = reader.Next(0)
 	if !errors.Is(err, errScanRowsTest) {
-		t.Fatalf("ScanRows() error = %v, want %v", err, errScanRowsTest)
+		t.Fatalf("Next() error = %v, want %v", err, errScanRowsTest)
 	}
 }
 
@@ -139,3 +140,267 @@ func (r *scanRowsTestRows) Next(dest []driver.Value) error {
 		return io.EOF
 	}
 }
+
+func TestResultReader_columns(t *testing.T) {
+	registerScanResultTestDriverOnce.Do(func() {
+		sql.Register("scan_result_test", scanResultTestDriver{})
+	})
+	db, err := sql.Open("scan_result_test", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id, name FROM t")
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	defer rows.Close()
+
+	reader, err := NewResultReader(rows)
+	if err != nil {
+		t.Fatalf("NewResultReader() error = %v", err)
+	}
+	res, _, err := reader.Next(0)
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+
+	id, name := res.Columns[0], res.Columns[1]
+	if id.DatabaseType != "DECIMAL" || id.Precision == nil || *id.Precision != 10 || id.Scale == nil || *id.Scale != 2 {
+		t.Errorf("unexpected id column %+v", id)
+	}
+	if id.Length != nil {
+		t.Errorf("expected no length for id, got %d", *id.Length)
+	}
+	if name.DatabaseType != "VARCHAR" || name.Length == nil || *name.Length != 255 || name.Nullable == nil || !*name.Nullable {
+		t.Errorf("unexpected name column %+v", name)
+	}
+
+	want := [][]interface{}{
+		{"1.50", nil},
+		{"2.00", ""},
+	}
+	if !reflect.DeepEqual(res.Rows, want) {
+		t.Errorf("Next() rows = %#v, want %#v", res.Rows, want)
+	}
+}
+
+func TestResultReader_Next(t *testing.T) {
+	registerScanResultTestDriverOnce.Do(func() {
+		sql.Register("scan_result_test", scanResultTestDriver{})
+	})
+	db, err := sql.Open("scan_result_test", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id, name FROM t")
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	defer rows.Close()
+
+	reader, err := NewResultReader(rows)
+	if err != nil {
+		t.Fatalf("NewResultReader() error = %v", err)
+	}
+	if !reader.FirstRowAt().IsZero() {
+		t.Errorf("FirstRowAt() = %v before reading, want zero", reader.FirstRowAt())
+	}
+	for i, want := range []struct {
+		id   interface{}
+		more bool
+	}{
+		{"1.50", true},
+		{"2.00", false},
+	} {
+		page, more, err := reader.Next(1)
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if len(page.Rows) != 1 || page.Rows[0][0] != want.id || more != want.more {
+			t.Errorf("page %d = %v, more %v, want id %v, more %v", i, page.Rows, more, want.id, want.more)
+		}
+		if reader.FirstRowAt().IsZero() {
+			t.Errorf("FirstRowAt() is zero after page %d", i)
+		}
+	}
+}
+
+var registerScanResultTestDriverOnce sync.Once
+
+type scanResultTestDriver struct{}
+
+func (scanResultTestDriver) Open(string) (driver.Conn, error) {
+	return scanResultTestConn{}, nil
+}
+
+type scanResultTestConn struct {
+	scanRowsTestConn
+}
+
+func (scanResultTestConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &scanResultTestRows{}, nil
+}
+
+type scanResultTestRows struct {
+	calls int
+}
+
+func (r *scanResultTestRows) Columns() []string {
+	return []string{"id", "name"}
+}
+
+func (r *scanResultTestRows) Close() error {
+	return nil
+}
+
+func (r *scanResultTestRows) Next(dest []driver.Value) error {
+	switch r.calls {
+	case 0:
+		dest[0], dest[1] = []byte("1.50"), nil
+	case 1:
+		dest[0], dest[1] = []byte("2.00"), ""
+	default:
+		return io.EOF
+	}
+	r.calls++
+	return nil
+}
+
+func (r *scanResultTestRows) ColumnTypeDatabaseTypeName(index int) string {
+	return []string{"DECIMAL", "VARCHAR"}[index]
+}
+
+func (r *scanResultTestRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return index == 1, true
+}
+
+func (r *scanResultTestRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if index == 1 {
+		return 255, true
+	}
+	return 0, false
+}
+
+func (r *scanResultTestRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if index == 0 {
+		return 10, 2, true
+	}
+	return 0, 0, false
+}
+
+func TestResultReader_NextResultSet(t *testing.T) {
+	registerMultiResultTestDriverOnce.Do(func() {
+		sql.Register("multi_result_test", multiResultTestDriver{})
+	})
+	db, err := sql.Open("multi_result_test", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(context.Background(), "CALL city_stats()")
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	defer rows.Close()
+
+	reader, err := NewResultReader(rows)
+	if err != nil {
+		t.Fatalf("NewResultReader() error = %v", err)
+	}
+	var got [][]string
+	for {
+		// Read one row of each set, the rest is dropped.
+		page, _, err := reader.Next(1)
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		set := []string{}
+		for _, col := range page.Columns {
+			set = append(set, col.Name)
+		}
+		for _, row := range page.Rows {
+			set = append(set, row[0].(string))
+		}
+		got = append(got, set)
+		ok, err := reader.NextResultSet()
+		if err != nil {
+			t.Fatalf("NextResultSet() error = %v", err)
+		}
+		if !ok {
+			break
+		}
+	}
+	want := [][]string{
+		{"name", "Kabul"},
+		{"country", "count", "AFG"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("result sets = %v, want %v", got, want)
+	}
+}
+
+var registerMultiResultTestDriverOnce sync.Once
+
+type multiResultTestDriver struct{}
+
+func (multiResultTestDriver) Open(string) (driver.Conn, error) {
+	return multiResultTestConn{}, nil
+}
+
+type multiResultTestConn struct {
+	scanRowsTestConn
+}
+
+func (multiResultTestConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &multiResultTestRows{sets: []multiResultTestSet{
+		{columns: []string{"name"}, rows: [][]driver.Value{{"Kabul"}, {"Qandahar"}}},
+		{columns: []string{"country", "count"}, rows: [][]driver.Value{{"AFG", int64(4)}}},
+	}}, nil
+}
+
+type multiResultTestSet struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+type multiResultTestRows struct {
+	sets []multiResultTestSet
+	set  int
+	row  int
+}
+
+func (r *multiResultTestRows) Columns() []string {
+	return r.sets[r.set].columns
+}
+
+func (r *multiResultTestRows) Close() error {
+	return nil
+}
+
+func (r *multiResultTestRows) Next(dest []driver.Value) error {
+	rows := r.sets[r.set].rows
+	if r.row >= len(rows) {
+		return io.EOF
+	}
+	copy(dest, rows[r.row])
+	r.row++
+	return nil
+}
+
+func (r *multiResultTestRows) HasNextResultSet() bool {
+	return r.set+1 < len(r.sets)
+}
+
+func (r *multiResultTestRows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.set++
+	r.row = 0
+	return nil
+}
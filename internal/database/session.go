@@ -0,0 +1,312 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sqls-server/sqls/dialect"
+)
+
+var (
+	ErrNoTransaction    = errors.New("no transaction is in progress")
+	ErrInTransaction    = errors.New("a transaction is already in progress")
+	ErrSessionNotPinned = errors.New("the connection does not support sessions")
+)
+
+// Executor runs statements. Both DBRepository and Session implement it.
+type Executor interface {
+	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Session runs statements on a single connection taken from the pool, so
+// that session state such as SET, USE, temporary tables and transactions
+// carries over from one execution to the next. The connection is taken on
+// first use and given back by Close.
+//
+// Rows returned by Query hold the connection, so they must be closed before
+// the next statement is run.
+//
+// Messages of the server are reported to the collector of the context of
+// each statement, see WithMessages. Those which are read with a statement of
+// their own, MySQL warnings and Oracle DBMS_OUTPUT, are read after Exec, and
+// after the rows of Query are closed by ReadMessages.
+type Session struct {
+	db      *sql.DB
+	driver  dialect.DatabaseDriver
+	conn    *sql.Conn
+	tx      *sql.Tx
+	txStart time.Time
+
+	// timeout is the statement timeout set on the connection, if timeoutSet.
+	timeout    time.Duration
+	timeoutSet bool
+	// noTimeout tells that the server has no statement timeout, as MariaDB
+	// behind the generic mysql driver.
+	noTimeout bool
+
+	messages *messageRoute
+	unroute  func()
+}
+
+func NewSession(db *sql.DB, driver dialect.DatabaseDriver) *Session {
+	return &Session{db: db, driver: driver, messages: &messageRoute{}}
+}
+
+// pinned returns ex when it is a session, or else a session of its own on
+// db for statements which must share a connection. done closes it.
+func pinned(ex Executor, db *sql.DB, driver dialect.DatabaseDriver) (Executor, func()) {
+	if _, ok := ex.(*Session); ok {
+		return ex, func() {}
+	}
+	s := NewSession(db, driver)
+	return s, func() { _ = s.Close() }
+}
+
+// queryString returns the single value of the single row of query.
+func queryString(ctx context.Context, ex Executor, query string) (string, error) {
+	rows, err := ex.Query(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return "", sql.ErrNoRows
+	}
+	var s string
+	if err := rows.Scan(&s); err != nil {
+		return "", err
+	}
+	return s, rows.Close()
+}
+func (s *Session) connect(ctx context.Context) (*sql.Conn, error) {
+	if s.db == nil {
+		return nil, ErrSessionNotPinned
+	}
+	if s.conn == nil {
+		conn, err := s.db.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.conn = conn
+		s.watchMessages(ctx)
+	}
+	return s.conn, nil
+}
+
+// watchMessages sets up the connection to report the messages of the server.
+// Sessions work without them, so failures are only logged.
+func (s *Session) watchMessages(ctx context.Context) {
+	var err error
+	switch s.driver {
+	case dialect.DatabaseDriverPostgreSQL:
+		s.unroute, err = routePostgreSQLNotices(s.conn, s.messages)
+	case dialect.DatabaseDriverOracle:
+		err = enableOracleOutput(ctx, s.conn)
+	}
+	if err != nil {
+		log.Println("watch server messages", err)
+	}
+}
+
+// readMessages reads the messages of the statement just run which the
+// server only returns when asked for.
+func (s *Session) readMessages(ctx context.Context, ex sessionExecer) {
+	c := messagesFrom(ctx)
+	if c == nil {
+		return
+	}
+	var err error
+	switch s.driver {
+	case dialect.DatabaseDriverMySQL, dialect.DatabaseDriverMySQL8, dialect.DatabaseDriverMySQL57, dialect.DatabaseDriverMySQL56:
+		err = readMySQLWarnings(ctx, ex, c)
+	case dialect.DatabaseDriverOracle:
+		err = readOracleOutput(ctx, ex, c)
+	}
+	if err != nil {
+		log.Println("read server messages", err)
+	}
+}
+
+// ReadMessages reads the messages of the last query, which the server only
+// returns when asked for once the rows of the query are closed.
+func (s *Session) ReadMessages(ctx context.Context) {
+	if s.conn == nil {
+		return
+	}
+	var ex sessionExecer = s.conn
+	if s.tx != nil {
+		ex = s.tx
+	}
+	s.readMessages(ctx, ex)
+}
+
+// sessionExecer is what statements of a session run on, the connection or
+// its transaction.
+type sessionExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+func (s *Session) execer(ctx context.Context) (sessionExecer, error) {
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.messages.set(messagesFrom(ctx))
+	if s.tx != nil {
+		return s.tx, nil
+	}
+	return conn, nil
+}
+
+// check drops a connection which went bad, along with its transaction, so
+// that the next statement starts a new session.
+func (s *Session) check(err error) error {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		_ = s.Close()
+	}
+	return err
+}
+
+func (s *Session) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ex, err := s.execer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ex.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, s.check(err)
+	}
+	s.readMessages(ctx, ex)
+	return res, nil
+}
+
+func (s *Session) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ex, err := s.execer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := ex.QueryContext(ctx, query, args...)
+	return rows, s.check(err)
+}
+
+// SetTimeout makes the server cancel the following statements once timeout
+// has passed, 0 for no limit, on the databases which can: PostgreSQL, and
+// MySQL from 5.7 on, where it bounds SELECT statements only. MariaDB has no
+// such variable.
+func (s *Session) SetTimeout(ctx context.Context, timeout time.Duration) error {
+	var query string
+	switch s.driver {
+	case dialect.DatabaseDriverPostgreSQL:
+		query = fmt.Sprintf("SET statement_timeout = %d", timeout.Milliseconds())
+	case dialect.DatabaseDriverMySQL, dialect.DatabaseDriverMySQL8, dialect.DatabaseDriverMySQL57:
+		query = fmt.Sprintf("SET SESSION max_execution_time = %d", timeout.Milliseconds())
+	default:
+		return nil
+	}
+	if s.noTimeout || s.conn != nil && s.timeoutSet && s.timeout == timeout {
+		return nil
+	}
+	if s.driver == dialect.DatabaseDriverMySQL && !s.timeoutSet {
+		version, err := queryString(ctx, s, "SELECT VERSION()")
+		if err != nil {
+			return err
+		}
+		if isMariaDB(version) {
+			s.noTimeout = true
+			return nil
+		}
+	}
+	ex, err := s.execer(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := ex.ExecContext(ctx, query); err != nil {
+		return s.check(err)
+	}
+	s.timeout, s.timeoutSet = timeout, true
+	return nil
+}
+
+// Begin starts a transaction which the following statements run in until
+// Commit or Rollback.
+func (s *Session) Begin(ctx context.Context) error {
+	if s.tx != nil {
+		return ErrInTransaction
+	}
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	// The transaction must outlive the request which started it.
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return s.check(err)
+	}
+	s.tx = tx
+	s.txStart = time.Now()
+	return nil
+}
+
+func (s *Session) Commit() error {
+	if s.tx == nil {
+		return ErrNoTransaction
+	}
+	err := s.tx.Commit()
+	s.tx = nil
+	return s.check(err)
+}
+
+func (s *Session) Rollback() error {
+	if s.tx == nil {
+		return ErrNoTransaction
+	}
+	err := s.tx.Rollback()
+	s.tx = nil
+	// PostgreSQL rolls back the settings made in the transaction too.
+	s.timeoutSet = false
+	return s.check(err)
+}
+
+// InTransaction reports whether a transaction is in progress, and since when.
+func (s *Session) InTransaction() (bool, time.Time) {
+	if s == nil || s.tx == nil {
+		return false, time.Time{}
+	}
+	return true, s.txStart
+}
+
+// Close rolls back a transaction in progress and gives the connection back
+// to the pool.
+func (s *Session) Close() error {
+	if s == nil {
+		return nil
+	}
+	var err error
+	if s.tx != nil {
+		err = s.tx.Rollback()
+		s.tx = nil
+	}
+	if s.unroute != nil {
+		s.unroute()
+		s.unroute = nil
+	}
+	if s.conn != nil {
+		if cerr := s.conn.Close(); err == nil && !errors.Is(cerr, sql.ErrConnDone) {
+			err = cerr
+		}
+		s.conn = nil
+	}
+	s.timeoutSet = false
+	return err
+}
@@ -0,0 +1,248 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/sqls-server/sqls/dialect"
+)
+
+func TestSession(t *testing.T) {
+	// Every connection to an in-memory database has a database of its own,
+	// so the table is only visible if the session keeps to one connection.
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxIdleConns(0)
+
+	ctx := context.Background()
+	session := NewSession(db, dialect.DatabaseDriverSQLite3)
+	defer session.Close()
+
+	if _, err := session.Exec(ctx, "CREATE TABLE city (name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	count := func() int {
+		t.Helper()
+		rows, err := session.Query(ctx, "SELECT COUNT(*) FROM city")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var n int
+		for rows.Next() {
+			if err := rows.Scan(&n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return n
+	}
+
+	if err := session.Begin(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Begin(ctx); !errors.Is(err, ErrInTransaction) {
+		t.Errorf("Begin() in transaction error = %v, want %v", err, ErrInTransaction)
+	}
+	if _, err := session.Exec(ctx, "INSERT INTO city VALUES ('Kabul')"); err != nil {
+		t.Fatal(err)
+	}
+	if inTx, _ := session.InTransaction(); !inTx {
+		t.Error("InTransaction() = false, want true")
+	}
+	if err := session.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := count(); got != 0 {
+		t.Errorf("count after rollback = %d, want 0", got)
+	}
+
+	if err := session.Begin(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.Exec(ctx, "INSERT INTO city VALUES ('Kabul')"); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := count(); got != 1 {
+		t.Errorf("count after commit = %d, want 1", got)
+	}
+
+	if err := session.Commit(); !errors.Is(err, ErrNoTransaction) {
+		t.Errorf("Commit() without transaction error = %v, want %v", err, ErrNoTransaction)
+	}
+}
+
+func TestSession_ReadMessages(t *testing.T) {
+	registerWarningsTestDriverOnce.Do(func() {
+		sql.Register("warnings_test", warningsTestDriver{})
+	})
+	db, err := sql.Open("warnings_test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	session := NewSession(db, dialect.DatabaseDriverMySQL)
+	defer session.Close()
+	messages := &MessageCollector{}
+	ctx := WithMessages(context.Background(), messages)
+
+	rows, err := session.Query(ctx, "SELECT CAST('1x' AS SIGNED)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	session.ReadMessages(ctx)
+
+	want := []Message{{Level: "WARNING", Text: "Truncated incorrect INTEGER value: '1x'"}}
+	if diff := cmp.Diff(want, messages.Take()); diff != "" {
+		t.Errorf("unmatch messages (- want, + got):\n%s", diff)
+	}
+}
+
+var registerWarningsTestDriverOnce sync.Once
+
+// warningsTestDriver answers SHOW WARNINGS with a warning and any other
+// query with a single value.
+type warningsTestDriver struct{}
+
+func (warningsTestDriver) Open(string) (driver.Conn, error) {
+	return warningsTestConn{}, nil
+}
+
+type warningsTestConn struct {
+	scanRowsTestConn
+}
+
+func (warningsTestConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if query == "SHOW WARNINGS" {
+		return &multiResultTestRows{sets: []multiResultTestSet{
+			{columns: []string{"Level", "Code", "Message"}, rows: [][]driver.Value{{"Warning", int64(1292), "Truncated incorrect INTEGER value: '1x'"}}},
+		}}, nil
+	}
+	return &multiResultTestRows{sets: []multiResultTestSet{
+		{columns: []string{"value"}, rows: [][]driver.Value{{int64(1)}}},
+	}}, nil
+}
+
+func TestSession_SetTimeout(t *testing.T) {
+	registerExecLogTestDriverOnce.Do(func() {
+		sql.Register("exec_log_test", execLogTestDriver{})
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		driver  dialect.DatabaseDriver
+		version string
+		want    []string
+	}{
+		{
+			driver: dialect.DatabaseDriverPostgreSQL,
+			want:   []string{"SET statement_timeout = 5000", "SET statement_timeout = 0"},
+		},
+		{
+			driver: dialect.DatabaseDriverMySQL8,
+			want:   []string{"SET SESSION max_execution_time = 5000", "SET SESSION max_execution_time = 0"},
+		},
+		{
+			driver:  dialect.DatabaseDriverMySQL,
+			version: "8.0.35",
+			want:    []string{"SET SESSION max_execution_time = 5000", "SET SESSION max_execution_time = 0"},
+		},
+		{
+			driver:  dialect.DatabaseDriverMySQL,
+			version: "10.11.6-MariaDB",
+			want:    nil,
+		},
+		{
+			driver: dialect.DatabaseDriverMssql,
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.driver)+" "+tt.version, func(t *testing.T) {
+			db, err := sql.Open("exec_log_test", tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			execLog.take()
+			session := NewSession(db, tt.driver)
+			defer session.Close()
+			// The timeout is only set again when it changes.
+			for _, timeout := range []time.Duration{5 * time.Second, 5 * time.Second, 0} {
+				if err := session.SetTimeout(ctx, timeout); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(tt.want, execLog.take()); diff != "" {
+				t.Errorf("unmatch statements (- want, + got):\n%s", diff)
+			}
+		})
+	}
+}
+
+var (
+	registerExecLogTestDriverOnce sync.Once
+	execLog                       = &execLogTest{}
+)
+
+type execLogTest struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (l *execLogTest) add(query string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queries = append(l.queries, query)
+}
+
+func (l *execLogTest) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	queries := l.queries
+	l.queries = nil
+	return queries
+}
+
+// execLogTestDriver records the statements run with Exec. Its server
+// version is the data source name.
+type execLogTestDriver struct{}
+
+func (execLogTestDriver) Open(version string) (driver.Conn, error) {
+	return execLogTestConn{version: version}, nil
+}
+
+type execLogTestConn struct {
+	scanRowsTestConn
+	version string
+}
+
+func (c execLogTestConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if query != "SELECT VERSION()" {
+		return nil, errors.New("not implemented")
+	}
+	return &multiResultTestRows{sets: []multiResultTestSet{
+		{columns: []string{"VERSION()"}, rows: [][]driver.Value{{c.version}}},
+	}}, nil
+}
+
+func (execLogTestConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	execLog.add(query)
+	return driver.RowsAffected(0), nil
+}
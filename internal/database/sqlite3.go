@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/sqls-server/sqls/dialect"
@@ -15,7 +16,11 @@ func init() {
 }
 
 func sqlite3Open(connCfg *DBConfig) (*DBConnection, error) {
-	conn, err := sql.Open("sqlite3", connCfg.DataSourceName)
+	dsn := connCfg.DataSourceName
+	if connCfg.ReadOnly {
+		dsn = withSQLite3QueryOnly(dsn)
+	}
+	conn, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -26,6 +31,14 @@ func sqlite3Open(connCfg *DBConfig) (*DBConnection, error) {
 	}, nil
 }
 
+// withSQLite3QueryOnly sets PRAGMA query_only on the connections of dsn.
+func withSQLite3QueryOnly(dsn string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&_query_only=1"
+	}
+	return dsn + "?_query_only=1"
+}
+
 type SQLite3DBRepository struct {
 	Conn *sql.DB
 }
@@ -158,26 +171,139 @@ func (db *SQLite3DBRepository) DescribeForeignKeysBySchema(ctx context.Context,
 		ctx,
 		`
 	SELECT m.name || p."id",
+       ?,
        m.name,
        p."from",
+       ?,
        p."table",
        p."to"
 	FROM sqlite_master m
 			 JOIN pragma_foreign_key_list(m.name) p ON m.name != p."table"
 	WHERE m.type = 'table'
 	ORDER BY 1, p."seq"
-		`)
+		`, schemaName, schemaName)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
-	return parseForeignKeys(rows, schemaName)
+	return parseForeignKeys(rows)
+}
+
+func (db *SQLite3DBRepository) Views(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(ctx, `
+	SELECT
+	  name
+	FROM
+	  sqlite_master
+	WHERE
+	  type = 'view'
+	ORDER BY
+	  name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (*SQLite3DBRepository) Functions(ctx context.Context) ([]string, error) {
+	// sqlite doesn't support stored functions
+	return nil, nil
+}
+
+func (*SQLite3DBRepository) Sequences(ctx context.Context) ([]string, error) {
+	// sqlite doesn't support sequences
+	return nil, nil
+}
+
+func (db *SQLite3DBRepository) Indexes(ctx context.Context, tableName string) ([]*Index, error) {
+	rows, err := db.Conn.QueryContext(ctx, `
+	SELECT
+	  il.name,
+	  ii.name,
+	  il."unique"
+	FROM
+	  pragma_index_list(?) il
+	  JOIN pragma_index_info(il.name) ii
+	ORDER BY
+	  il.name, ii.seqno
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseIndexes(rows)
+}
+
+func (db *SQLite3DBRepository) Triggers(ctx context.Context, tableName string) ([]string, error) {
+	rows, err := db.Conn.QueryContext(ctx, `
+	SELECT
+	  name
+	FROM
+	  sqlite_master
+	WHERE
+	  type = 'trigger' AND tbl_name = ?
+	ORDER BY
+	  name
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
 }
 
-func (db *SQLite3DBRepository) Exec(ctx context.Context, query string) (sql.Result, error) {
-	return db.Conn.ExecContext(ctx, query)
+func (db *SQLite3DBRepository) ShowCreateTable(ctx context.Context, _, tableName string) (string, error) {
+	rows, err := db.Conn.QueryContext(ctx, `
+	SELECT
+	  sql
+	FROM
+	  sqlite_master
+	WHERE
+	  tbl_name = ? AND sql IS NOT NULL
+	ORDER BY
+	  type = 'table' DESC, type, name
+	`, tableName)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	stmts, err := parseNames(rows)
+	if err != nil {
+		return "", err
+	}
+	if len(stmts) == 0 {
+		return "", fmt.Errorf("table not found, %q", tableName)
+	}
+	return strings.Join(stmts, ";\n") + ";\n", nil
+}
+
+func (db *SQLite3DBRepository) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.Conn.ExecContext(ctx, query, args...)
 }
 
-func (db *SQLite3DBRepository) Query(ctx context.Context, query string) (*sql.Rows, error) {
-	return db.Conn.QueryContext(ctx, query)
+func (db *SQLite3DBRepository) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.Conn.QueryContext(ctx, query, args...)
+}
+
+func (db *SQLite3DBRepository) Explain(ctx context.Context, ex Executor, query string) (*Plan, error) {
+	rows, err := ex.Query(ctx, "EXPLAIN QUERY PLAN "+query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var planRows []*SQLitePlanRow
+	for rows.Next() {
+		var r SQLitePlanRow
+		var notUsed sql.NullInt64
+		if err := rows.Scan(&r.ID, &r.Parent, &notUsed, &r.Detail); err != nil {
+			return nil, err
+		}
+		planRows = append(planRows, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return BuildSQLitePlan(planRows), nil
 }
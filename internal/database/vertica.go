@@ -251,14 +251,51 @@ func (db *VerticaDBRepository) DescribeDatabaseTableBySchema(ctx context.Context
 	return tableInfos, nil
 }
 
-func (db *VerticaDBRepository) Exec(ctx context.Context, query string) (sql.Result, error) {
-	return db.Conn.ExecContext(ctx, query)
+func (db *VerticaDBRepository) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.Conn.ExecContext(ctx, query, args...)
 }
 
-func (db *VerticaDBRepository) Query(ctx context.Context, query string) (*sql.Rows, error) {
-	return db.Conn.QueryContext(ctx, query)
+func (db *VerticaDBRepository) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.Conn.QueryContext(ctx, query, args...)
 }
 
 func (db *VerticaDBRepository) DescribeForeignKeysBySchema(ctx context.Context, schemaName string) ([]*ForeignKey, error) {
 	return nil, fmt.Errorf("describe foreign keys is not supported")
 }
+
+func (db *VerticaDBRepository) Views(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(ctx, "SELECT table_name FROM v_catalog.views WHERE table_schema = CURRENT_SCHEMA() ORDER BY 1")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *VerticaDBRepository) Functions(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(ctx, "SELECT DISTINCT function_name FROM v_catalog.user_functions WHERE schema_name = CURRENT_SCHEMA() ORDER BY 1")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *VerticaDBRepository) Sequences(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(ctx, "SELECT sequence_name FROM v_catalog.sequences WHERE sequence_schema = CURRENT_SCHEMA() ORDER BY 1")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseNames(rows)
+}
+
+func (db *VerticaDBRepository) Indexes(ctx context.Context, tableName string) ([]*Index, error) {
+	// vertica doesn't have indexes, tables are stored as projections
+	return nil, nil
+}
+
+func (db *VerticaDBRepository) Triggers(ctx context.Context, tableName string) ([]string, error) {
+	// vertica doesn't support triggers
+	return nil, nil
+}
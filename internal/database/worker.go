@@ -4,11 +4,14 @@ import (
 	"context"
 	"log"
 	"sync"
+	"time"
 )
 
 type Worker struct {
 	dbRepo  DBRepository
 	dbCache *DBCache
+	// timeout bounds each pass of schema queries.
+	timeout time.Duration
 
 	done   chan struct{}
 	update chan struct{}
@@ -46,6 +49,16 @@ func (w *Worker) setColumnCache(col map[string][]*ColumnDesc) {
 	}
 }
 
+func (w *Worker) setForeignKeysCache(fks map[string]map[string][]*ForeignKey) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.dbCache != nil {
+		newCache := *w.dbCache
+		newCache.ForeignKeys = fks
+		w.dbCache = &newCache
+	}
+}
+
 func (w *Worker) Start() {
 	go func() {
 		log.Println("db worker: start")
@@ -55,13 +68,19 @@ func (w *Worker) Start() {
 				log.Println("db worker: done")
 				return
 			case <-w.update:
-				generator := NewDBCacheUpdater(w.dbRepo)
+				generator := w.generator()
 				col, err := generator.GenerateDBCacheSecondary(context.Background())
 				if err != nil {
 					log.Println(err)
 					continue
 				}
 				w.setColumnCache(col)
+				fks, err := generator.GenerateForeignKeysCacheAll(context.Background())
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				w.setForeignKeysCache(fks)
 				log.Println("db worker: Update db cache secondary complete")
 			}
 		}
@@ -72,8 +91,11 @@ func (w *Worker) Stop() {
 	close(w.done)
 }
 
-func (w *Worker) ReCache(ctx context.Context, repo DBRepository) error {
+// ReCache reads the schema of repo, each pass of schema queries bounded by
+// timeout unless it is 0.
+func (w *Worker) ReCache(ctx context.Context, repo DBRepository, timeout time.Duration) error {
 	w.dbRepo = repo
+	w.timeout = timeout
 	if err := w.updateAllCache(ctx); err != nil {
 		return err
 	}
@@ -81,8 +103,14 @@ func (w *Worker) ReCache(ctx context.Context, repo DBRepository) error {
 	return nil
 }
 
-func (w *Worker) updateAllCache(ctx context.Context) error {
+func (w *Worker) generator() *DBCacheGenerator {
 	generator := NewDBCacheUpdater(w.dbRepo)
+	generator.timeout = w.timeout
+	return generator
+}
+
+func (w *Worker) updateAllCache(ctx context.Context) error {
+	generator := w.generator()
 	cache, err := generator.GenerateDBCachePrimary(ctx)
 	if err != nil {
 		return err
@@ -35,7 +35,7 @@ func TestEval(t *testing.T) {
 
 	for _, tt := range testcases {
 		t.Run(tt.name, func(t *testing.T) {
-			actual, _ := Format(tt.input, tt.params, tt.config)
+			actual, _ := Format(tt.input, tt.params, tt.config, "")
 			if actual[0].NewText != tt.expected {
 				t.Errorf("expected: %s, got %s", tt.expected, actual[0].NewText)
 			}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+// maxParamHistory is the number of recent values of a bind parameter which
+// are offered when prompting for it.
+const maxParamHistory = 5
+
+// bindParamStore keeps the values picked for bind parameters. Prompts are
+// answered outside of the request which raised them, hence the lock.
+type bindParamStore struct {
+	mu sync.Mutex
+	// picked holds the values picked in prompts, by file and name.
+	picked map[string]map[string]interface{}
+	// history holds the recently bound values of each name as SQL literals,
+	// most recent first.
+	history   map[string][]string
+	prompting bool
+}
+
+func newBindParamStore() *bindParamStore {
+	return &bindParamStore{
+		picked:  map[string]map[string]interface{}{},
+		history: map[string][]string{},
+	}
+}
+
+func (b *bindParamStore) pickedValues(uri string) map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	values := map[string]interface{}{}
+	for k, v := range b.picked[uri] {
+		values[k] = v
+	}
+	return values
+}
+
+func (b *bindParamStore) pick(uri, name string, val interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.picked[uri] == nil {
+		b.picked[uri] = map[string]interface{}{}
+	}
+	b.picked[uri][name] = val
+}
+
+func (b *bindParamStore) forget(uri string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.picked, uri)
+}
+
+func (b *bindParamStore) remember(name string, val interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lit := paramLiteral(val)
+	recent := []string{lit}
+	for _, v := range b.history[name] {
+		if v != lit && len(recent) < maxParamHistory {
+			recent = append(recent, v)
+		}
+	}
+	b.history[name] = recent
+}
+
+func (b *bindParamStore) recent(name string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string{}, b.history[name]...)
+}
+
+// paramLiteral writes a value the way ParseParamValue reads it.
+func paramLiteral(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(val)
+}
+
+// boundQuery is a statement ready to run. Text is the statement as written
+// and Query the statement with its placeholders rewritten for the driver.
+type boundQuery struct {
+	Text  string
+	Query string
+	Args  []interface{}
+}
+
+// bindQuery binds the placeholders of query. Values given with the command
+// take precedence over "-- @param" comments of the file, which take
+// precedence over values picked in prompts. When values are missing, the
+// user is prompted for them and the query is not run.
+func (s *Server) bindQuery(ctx context.Context, conn *jsonrpc2.Conn, uri, query string, given map[string]interface{}) (*boundQuery, error) {
+	repo, err := s.newDBRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	driver := repo.Driver()
+	placeholders := database.ExtractPlaceholders(driver, query)
+	if len(placeholders) == 0 {
+		return &boundQuery{Text: query, Query: query}, nil
+	}
+
+	values := s.bindParams.pickedValues(uri)
+	if f, ok := s.files[uri]; ok {
+		for k, v := range database.ParamComments(f.Text) {
+			values[k] = v
+		}
+	}
+	for k, v := range given {
+		values[strings.ToLower(k)] = v
+	}
+
+	var missing []*database.Placeholder
+	seen := map[string]bool{}
+	for _, p := range placeholders {
+		name := strings.ToLower(p.Name)
+		if _, ok := values[name]; !ok && !seen[name] {
+			missing = append(missing, p)
+		}
+		seen[name] = true
+	}
+	if len(missing) > 0 {
+		texts := make([]string, len(missing))
+		for i, p := range missing {
+			texts[i] = p.Text
+		}
+		hint := "add \"-- @param name = value\" comments"
+		if conn != nil && s.canPrompt {
+			hint = "pick them in the prompt or " + hint
+			s.promptParams(conn, uri, missing)
+		}
+		return nil, fmt.Errorf("no values for bind parameters %s, %s and run again", strings.Join(texts, ", "), hint)
+	}
+
+	for name := range seen {
+		s.bindParams.remember(name, values[name])
+	}
+	bound, args, err := database.BindQuery(driver, query, placeholders, values)
+	if err != nil {
+		return nil, err
+	}
+	return &boundQuery{Text: query, Query: bound, Args: args}, nil
+}
+
+// promptParams asks the client for the missing values in the background, as
+// the client cannot answer while the request which needs them is handled.
+// The values offered are the recent ones of each parameter and NULL.
+func (s *Server) promptParams(conn *jsonrpc2.Conn, uri string, missing []*database.Placeholder) {
+	s.bindParams.mu.Lock()
+	if s.bindParams.prompting {
+		s.bindParams.mu.Unlock()
+		return
+	}
+	s.bindParams.prompting = true
+	s.bindParams.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.bindParams.mu.Lock()
+			s.bindParams.prompting = false
+			s.bindParams.mu.Unlock()
+		}()
+		for _, p := range missing {
+			name := strings.ToLower(p.Name)
+			var actions []lsp.MessageActionItem
+			for _, v := range s.bindParams.recent(name) {
+				actions = append(actions, lsp.MessageActionItem{Title: v})
+			}
+			if !containsAction(actions, "NULL") {
+				actions = append(actions, lsp.MessageActionItem{Title: "NULL"})
+			}
+			params := &lsp.ShowMessageRequestParams{
+				Type:    lsp.Info,
+				Message: fmt.Sprintf("Value for bind parameter %s", p.Text),
+				Actions: actions,
+			}
+			var item *lsp.MessageActionItem
+			if err := conn.Call(context.Background(), "window/showMessageRequest", params, &item); err != nil {
+				log.Println("prompt bind parameter", err)
+				return
+			}
+			if item == nil {
+				return
+			}
+			s.bindParams.pick(uri, name, database.ParseParamValue(item.Title))
+		}
+	}()
+}
+
+func containsAction(actions []lsp.MessageActionItem, title string) bool {
+	for _, a := range actions {
+		if a.Title == title {
+			return true
+		}
+	}
+	return false
+}
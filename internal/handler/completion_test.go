@@ -848,6 +848,148 @@ var subQueryCase = []completionTestCase{
 		},
 	},
 }
+var cteCase = []completionTestCase{
+	{
+		name:  "cte names as tables",
+		input: "WITH big_city AS (SELECT ID, Name FROM city WHERE Population > 1000000) SELECT * FROM ",
+		line:  0,
+		col:   86,
+		want: []string{
+			"big_city",
+			"city",
+			"country",
+		},
+	},
+	{
+		name:  "cte parent columns",
+		input: "WITH big_city AS (SELECT ID, Name AS city_name FROM city) SELECT big_city. FROM big_city",
+		line:  0,
+		col:   74,
+		want: []string{
+			"ID",
+			"city_name",
+		},
+		bad: []string{
+			"CountryCode",
+		},
+	},
+	{
+		name:  "cte aliased parent columns",
+		input: "WITH big_city AS (SELECT ID, Name FROM city) SELECT b. FROM big_city b",
+		line:  0,
+		col:   54,
+		want: []string{
+			"ID",
+			"Name",
+		},
+	},
+	{
+		name:  "cte name in another case",
+		input: "WITH Totals AS (SELECT ID, Name FROM city) SELECT totals. FROM totals",
+		line:  0,
+		col:   57,
+		want: []string{
+			"ID",
+			"Name",
+		},
+	},
+	{
+		name:  "cte column list",
+		input: "WITH c(city_id, city_name) AS (SELECT ID, Name FROM city) SELECT c. FROM c",
+		line:  0,
+		col:   67,
+		want: []string{
+			"city_id",
+			"city_name",
+		},
+		bad: []string{
+			"ID",
+		},
+	},
+	{
+		name:  "recursive cte columns",
+		input: "WITH RECURSIVE r AS (SELECT 1 AS n UNION ALL SELECT n + 1 FROM r WHERE n < 10) SELECT  FROM r",
+		line:  0,
+		col:   86,
+		want: []string{
+			"n",
+		},
+	},
+	{
+		name:  "cte asterisk columns",
+		input: "WITH c AS (SELECT * FROM city) SELECT c. FROM c",
+		line:  0,
+		col:   40,
+		want: []string{
+			"ID",
+			"Name",
+			"CountryCode",
+			"District",
+			"Population",
+		},
+	},
+}
+
+var enumValueCase = []completionTestCase{
+	{
+		name:  "enum values after comparison quote",
+		input: "select * from country where Continent = '",
+		line:  0,
+		col:   41,
+		want: []string{
+			"Asia",
+			"Europe",
+			"North America",
+			"Africa",
+			"Oceania",
+			"Antarctica",
+			"South America",
+		},
+		bad: []string{
+			"Name",
+			"SELECT",
+		},
+	},
+	{
+		name:  "filtered enum values of aliased column",
+		input: "select * from country c where c.Continent = 'A",
+		line:  0,
+		col:   46,
+		want: []string{
+			"Asia",
+			"Africa",
+			"Antarctica",
+		},
+		bad: []string{
+			"Europe",
+		},
+	},
+	{
+		name:  "enum values in IN list",
+		input: "select * from countrylanguage where IsOfficial in ('T', ",
+		line:  0,
+		col:   56,
+		want: []string{
+			"T",
+			"F",
+		},
+		bad: []string{
+			"Language",
+		},
+	},
+	{
+		name:  "no values for non enum column",
+		input: "select * from city where Name = '",
+		line:  0,
+		col:   33,
+		want:  []string{},
+		bad: []string{
+			"ID",
+			"Name",
+		},
+	},
+}
+
 var joinClauseCase = []completionTestCase{
 	{
 		name:  "join tables",
@@ -1095,6 +1237,8 @@ func TestCompleteMain(t *testing.T) {
 		"col name":        colNameCase,
 		"case value":      caseValueCase,
 		"subquery":        subQueryCase,
+		"cte":             cteCase,
+		"enum value":      enumValueCase,
 	}
 
 	for k, v := range testcaseMap {
@@ -1195,6 +1339,7 @@ func TestCompleteNoneDBConnection(t *testing.T) {
 		"col name":        colNameCase,
 		"case value":      caseValueCase,
 		"subquery":        subQueryCase,
+		"cte":             cteCase,
 	}
 
 	for k, v := range testcaseMap {
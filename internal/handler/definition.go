@@ -8,6 +8,7 @@ import (
 	"github.com/sourcegraph/jsonrpc2"
 	"github.com/sqls-server/sqls/ast"
 	"github.com/sqls-server/sqls/ast/astutil"
+	"github.com/sqls-server/sqls/dialect"
 	"github.com/sqls-server/sqls/internal/database"
 	"github.com/sqls-server/sqls/internal/lsp"
 	"github.com/sqls-server/sqls/parser"
@@ -30,15 +31,19 @@ func (s *Server) handleDefinition(ctx context.Context, conn *jsonrpc2.Conn, req
 		return nil, fmt.Errorf("document not found: %s", params.TextDocument.URI)
 	}
 
-	return definition(params.TextDocument.URI, f.Text, params, s.worker.Cache())
+	res, err := definition(params.TextDocument.URI, f.Text, s.driver(), params, s.worker.Cache())
+	if err != nil || len(res) > 0 {
+		return res, err
+	}
+	return s.tableDefinition(ctx, f.Text, params)
 }
 
-func definition(url, text string, params lsp.DefinitionParams, dbCache *database.DBCache) (lsp.Definition, error) {
+func definition(url, text string, driver dialect.DatabaseDriver, params lsp.DefinitionParams, dbCache *database.DBCache) (lsp.Definition, error) {
 	pos := token.Pos{
 		Line: params.Position.Line,
 		Col:  params.Position.Character + 1,
 	}
-	parsed, err := parser.Parse(text)
+	parsed, err := parser.ParseFor(text, driver)
 	if err != nil {
 		return nil, err
 	}
@@ -52,13 +57,8 @@ func definition(url, text string, params lsp.DefinitionParams, dbCache *database
 		return nil, nil
 	}
 
-	aliases := parseutil.ExtractAliased(parsed)
-	if len(aliases) == 0 {
-		return nil, nil
-	}
-
 	var define ast.Node
-	for _, v := range aliases {
+	for _, v := range parseutil.ExtractAliased(parsed) {
 		alias, _ := v.(*ast.Aliased)
 		if alias.AliasedName.String() == currentVariable.String() {
 			define = alias.AliasedName
@@ -66,6 +66,16 @@ func definition(url, text string, params lsp.DefinitionParams, dbCache *database
 		}
 	}
 
+	if ident, ok := currentVariable.(*ast.Identifier); ok && define == nil {
+		ctes, err := parseutil.ExtractCTEs(parsed, pos)
+		if err != nil {
+			return nil, err
+		}
+		if cte, ok := parseutil.FindCTE(ctes, ident.NoQuoteString()); ok {
+			define = cte.Ident
+		}
+	}
+
 	if define == nil {
 		return nil, nil
 	}
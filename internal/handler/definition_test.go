@@ -84,6 +84,98 @@ var definitionTestCases = []struct {
 			},
 		},
 	},
+	{
+		name:  "cte",
+		input: "WITH big_city AS (SELECT ID, Name FROM city) SELECT big_city.Name FROM big_city",
+		pos: lsp.Position{
+			Line:      0,
+			Character: 75,
+		},
+		want: []lsp.Location{
+			{
+				URI: testFileURI,
+				Range: lsp.Range{
+					Start: lsp.Position{
+						Line:      0,
+						Character: 5,
+					},
+					End: lsp.Position{
+						Line:      0,
+						Character: 13,
+					},
+				},
+			},
+		},
+	},
+	{
+		name:  "cte member parent",
+		input: "WITH big_city AS (SELECT ID, Name FROM city) SELECT big_city.Name FROM big_city",
+		pos: lsp.Position{
+			Line:      0,
+			Character: 53,
+		},
+		want: []lsp.Location{
+			{
+				URI: testFileURI,
+				Range: lsp.Range{
+					Start: lsp.Position{
+						Line:      0,
+						Character: 5,
+					},
+					End: lsp.Position{
+						Line:      0,
+						Character: 13,
+					},
+				},
+			},
+		},
+	},
+	{
+		name:  "cte name in another case",
+		input: "WITH Big_City AS (SELECT ID, Name FROM city) SELECT big_city.Name FROM big_city",
+		pos: lsp.Position{
+			Line:      0,
+			Character: 75,
+		},
+		want: []lsp.Location{
+			{
+				URI: testFileURI,
+				Range: lsp.Range{
+					Start: lsp.Position{
+						Line:      0,
+						Character: 5,
+					},
+					End: lsp.Position{
+						Line:      0,
+						Character: 13,
+					},
+				},
+			},
+		},
+	},
+	{
+		name:  "table",
+		input: "SELECT ID, Name FROM city",
+		pos: lsp.Position{
+			Line:      0,
+			Character: 22,
+		},
+		want: []lsp.Location{
+			{
+				URI: "sqls:/ddl/world.city.sql",
+				Range: lsp.Range{
+					Start: lsp.Position{
+						Line:      0,
+						Character: 19,
+					},
+					End: lsp.Position{
+						Line:      0,
+						Character: 23,
+					},
+				},
+			},
+		},
+	},
 }
 
 func TestDefinition(t *testing.T) {
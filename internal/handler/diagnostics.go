@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/dialect"
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+	"github.com/sqls-server/sqls/parser"
+	"github.com/sqls-server/sqls/parser/parseutil"
+	"github.com/sqls-server/sqls/token"
+)
+
+const diagnosticSource = "sqls"
+
+func (s *Server) publishDiagnostics(ctx context.Context, conn *jsonrpc2.Conn, uri string) {
+	f, ok := s.files[uri]
+	if !ok {
+		return
+	}
+	diagnostics, err := diagnose(f.Text, s.driver(), s.worker.Cache())
+	if err != nil {
+		log.Println("diagnose:", err)
+		return
+	}
+	params := lsp.PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	}
+	if err := conn.Notify(ctx, "textDocument/publishDiagnostics", params); err != nil {
+		log.Println("publish diagnostics:", err)
+	}
+}
+
+func diagnose(text string, driver dialect.DatabaseDriver, dbCache *database.DBCache) ([]lsp.Diagnostic, error) {
+	diagnostics := []lsp.Diagnostic{}
+	if dbCache == nil {
+		return diagnostics, nil
+	}
+	parsed, err := parser.ParseFor(text, driver)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics = append(diagnostics, enumDiagnostics(parsed, dbCache)...)
+	diagnostics = append(diagnostics, insertDiagnostics(parsed, dbCache)...)
+	return diagnostics, nil
+}
+
+// enumDiagnostics reports string literals compared to an enum column that are
+// not one of its labels, as in "col = 'x'" or "col IN ('x', 'y')".
+func enumDiagnostics(parsed ast.TokenList, dbCache *database.DBCache) []lsp.Diagnostic {
+	diagnostics := []lsp.Diagnostic{}
+	check := func(colNode ast.Node, literals []*ast.Item) {
+		ref := columnRef(colNode)
+		if ref == nil || len(literals) == 0 {
+			return
+		}
+		tables, err := parseutil.ExtractTable(parsed, colNode.Pos())
+		if err != nil {
+			return
+		}
+		col, ok := dbCache.FindColumn(tables, ref...)
+		if !ok {
+			return
+		}
+		values, ok := dbCache.EnumValues(col)
+		if !ok {
+			return
+		}
+		for _, lit := range literals {
+			label := literalText(lit)
+			// MySQL compares enum labels case-insensitively, so only
+			// report labels which cannot match under any collation.
+			if containsFold(values, label) {
+				continue
+			}
+			diagnostics = append(diagnostics, lsp.Diagnostic{
+				Range:    nodeRange(lit),
+				Severity: lsp.SeverityWarning,
+				Source:   strPtr(diagnosticSource),
+				Message:  fmt.Sprintf("'%s' is not a valid value of enum column %s.%s", label, col.Table, col.Name),
+			})
+		}
+	}
+
+	walkTokenLists(parsed, func(list ast.TokenList) {
+		toks := list.GetTokens()
+		for i, node := range toks {
+			if cmp, ok := node.(*ast.Comparison); ok {
+				if lit, ok := stringLiteral(cmp.Right); ok {
+					check(cmp.Left, []*ast.Item{lit})
+				} else if lit, ok := stringLiteral(cmp.Left); ok {
+					check(cmp.Right, []*ast.Item{lit})
+				}
+				continue
+			}
+			if columnRef(node) == nil {
+				continue
+			}
+			// col [NOT] IN (...)
+			rest := nonWhitespace(toks[i+1:])
+			if len(rest) > 0 && isKeyword(rest[0], "NOT") {
+				rest = rest[1:]
+			}
+			if len(rest) < 2 || !isKeyword(rest[0], "IN") {
+				continue
+			}
+			paren, ok := rest[1].(*ast.Parenthesis)
+			if !ok {
+				continue
+			}
+			check(node, inListLiterals(paren))
+		}
+	})
+	return diagnostics
+}
+
+// insertDiagnostics reports rows of INSERT statements whose number of
+// values differs from that of the columns, and literals which do not fit
+// the column they are inserted into.
+func insertDiagnostics(parsed ast.TokenList, dbCache *database.DBCache) []lsp.Diagnostic {
+	diagnostics := []lsp.Diagnostic{}
+	for _, node := range parsed.GetTokens() {
+		stmt, ok := node.(*ast.Statement)
+		if !ok {
+			continue
+		}
+		toks := nonWhitespace(stmt.GetTokens())
+		if len(toks) == 0 || !strings.HasPrefix(strings.ToUpper(toks[0].String()), "INSERT") {
+			continue
+		}
+		insert, err := parseutil.ExtractInsert(parsed, toks[0].End())
+		if err != nil {
+			continue
+		}
+		cols, ok := insertColumns(insert, dbCache)
+		if !ok {
+			continue
+		}
+		for _, row := range insert.Rows {
+			values, ok := parseutil.ListItems(row)
+			if !ok || len(values) == 0 {
+				continue
+			}
+			diagnostics = append(diagnostics, rowDiagnostics(row, values, cols)...)
+		}
+	}
+	return diagnostics
+}
+
+// insertColumns returns the columns an INSERT statement fills, in order. It
+// returns false when the table or one of the listed columns is unknown, or
+// when without a column list the table has generated columns, which some
+// databases want left out of the values.
+func insertColumns(insert *parseutil.Insert, dbCache *database.DBCache) ([]*database.ColumnDesc, bool) {
+	table := insert.GetTable()
+	if table == nil {
+		return nil, false
+	}
+	tableCols, ok := dbCache.ColumnDatabase(table.DatabaseSchema, table.Name)
+	if !ok {
+		return nil, false
+	}
+	if insert.ColumnList == nil {
+		for _, col := range tableCols {
+			if col.IsGenerated() {
+				return nil, false
+			}
+		}
+		return tableCols, true
+	}
+	items, ok := parseutil.ListItems(insert.ColumnList)
+	if !ok {
+		return nil, false
+	}
+	cols := []*database.ColumnDesc{}
+	for _, item := range items {
+		ident, ok := item.(*ast.Identifier)
+		if !ok {
+			return nil, false
+		}
+		col, ok := dbCache.ColumnBySchema(table.DatabaseSchema, table.Name, ident.NoQuoteString())
+		if !ok {
+			return nil, false
+		}
+		cols = append(cols, col)
+	}
+	return cols, true
+}
+
+func rowDiagnostics(row *ast.Parenthesis, values []ast.Node, cols []*database.ColumnDesc) []lsp.Diagnostic {
+	diagnostics := []lsp.Diagnostic{}
+	if len(values) < len(cols) {
+		diagnostics = append(diagnostics, lsp.Diagnostic{
+			Range:    nodeRange(row),
+			Severity: lsp.SeverityError,
+			Source:   strPtr(diagnosticSource),
+			Message:  fmt.Sprintf("%d values for %d columns", len(values), len(cols)),
+		})
+	}
+	for i, value := range values {
+		if i >= len(cols) {
+			diagnostics = append(diagnostics, lsp.Diagnostic{
+				Range:    nodeRange(value),
+				Severity: lsp.SeverityError,
+				Source:   strPtr(diagnosticSource),
+				Message:  fmt.Sprintf("%d values for %d columns", len(values), len(cols)),
+			})
+			continue
+		}
+		if msg := literalMismatch(value, cols[i]); msg != "" {
+			diagnostics = append(diagnostics, lsp.Diagnostic{
+				Range:    nodeRange(value),
+				Severity: lsp.SeverityWarning,
+				Source:   strPtr(diagnosticSource),
+				Message:  msg,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// literalMismatch tells why a literal value cannot be stored in col, or
+// returns "" when it can or when the value is not a literal.
+func literalMismatch(value ast.Node, col *database.ColumnDesc) string {
+	if isKeyword(value, "NULL") {
+		if col.Null == "NO" && !col.Default.Valid && !strings.Contains(strings.ToLower(col.Extra), "auto_increment") {
+			return fmt.Sprintf("NULL into NOT NULL column %s.%s", col.Table, col.Name)
+		}
+		return ""
+	}
+	lit, ok := stringLiteral(value)
+	if !ok {
+		return ""
+	}
+	s := literalText(lit)
+	if database.IsNumberType(col.Type) {
+		// numeric strings are converted to numbers
+		if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+			return fmt.Sprintf("string %s into %s column %s.%s", lit.String(), col.Type, col.Table, col.Name)
+		}
+		return ""
+	}
+	// The length of a string with backslash escapes depends on the database.
+	if n, ok := col.MaxLength(); ok && !strings.Contains(s, `\`) {
+		if l := utf8.RuneCountInString(s); l > n {
+			return fmt.Sprintf("string of length %d into %s column %s.%s", l, col.Type, col.Table, col.Name)
+		}
+	}
+	return ""
+}
+
+func walkTokenLists(list ast.TokenList, fn func(ast.TokenList)) {
+	fn(list)
+	for _, node := range list.GetTokens() {
+		if child, ok := node.(ast.TokenList); ok {
+			walkTokenLists(child, fn)
+		}
+	}
+}
+
+func columnRef(node ast.Node) []string {
+	switch v := node.(type) {
+	case *ast.Identifier:
+		return []string{v.NoQuoteString()}
+	case *ast.MemberIdentifier:
+		if v.ParentTok == nil || v.ChildTok == nil {
+			return nil
+		}
+		ref := []string{v.ParentTok.NoQuoteString(), v.ChildTok.NoQuoteString()}
+		if v.QualifierTok != nil {
+			ref = append([]string{v.QualifierName()}, ref...)
+		}
+		return ref
+	}
+	return nil
+}
+
+func stringLiteral(node ast.Node) (*ast.Item, bool) {
+	item, ok := node.(*ast.Item)
+	if !ok || !item.Tok.MatchKind(token.SingleQuotedString) {
+		return nil, false
+	}
+	s := item.String()
+	// Ignore a literal which is still being typed.
+	if len(s) < 2 || s[len(s)-1] != s[0] {
+		return nil, false
+	}
+	return item, true
+}
+
+// literalText returns the text of a string literal without its quotes,
+// which are single or, in MySQL, double ones.
+func literalText(lit *ast.Item) string {
+	s := lit.String()
+	q := s[:1]
+	return strings.ReplaceAll(s[1:len(s)-1], q+q, q)
+}
+
+func inListLiterals(paren *ast.Parenthesis) []*ast.Item {
+	var literals []*ast.Item
+	for _, node := range paren.Inner().GetTokens() {
+		nodes := []ast.Node{node}
+		if list, ok := node.(*ast.IdentifierList); ok {
+			nodes = list.GetTokens()
+		}
+		for _, n := range nodes {
+			if lit, ok := stringLiteral(n); ok {
+				literals = append(literals, lit)
+			}
+		}
+	}
+	return literals
+}
+
+func nonWhitespace(nodes []ast.Node) []ast.Node {
+	var rv []ast.Node
+	for _, node := range nodes {
+		if tok, ok := node.(ast.Token); ok && tok.GetToken().MatchKind(token.Whitespace) {
+			continue
+		}
+		rv = append(rv, node)
+	}
+	return rv
+}
+
+func isKeyword(node ast.Node, keyword string) bool {
+	tok, ok := node.(ast.Token)
+	return ok && tok.GetToken().MatchSQLKeyword(keyword)
+}
+
+func nodeRange(node ast.Node) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{
+			Line:      node.Pos().Line,
+			Character: node.Pos().Col,
+		},
+		End: lsp.Position{
+			Line:      node.End().Line,
+			Character: node.End().Col,
+		},
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func strPtr(s string) *string {
+	return &s
+}
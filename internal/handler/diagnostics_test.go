@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/dialect"
+	"github.com/sqls-server/sqls/internal/config"
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+	"github.com/sqls-server/sqls/parser"
+	"github.com/sqls-server/sqls/parser/parseutil"
+	"github.com/sqls-server/sqls/token"
+)
+
+func TestDiagnoseEnum(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	cfg := &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: "mock"},
+		},
+	}
+	tx.addWorkspaceConfig(t, cfg)
+
+	tests := []struct {
+		name  string
+		input string
+		want  []lsp.Range
+	}{
+		{
+			name:  "valid label",
+			input: "SELECT * FROM country WHERE Continent = 'Asia'",
+			want:  []lsp.Range{},
+		},
+		{
+			name:  "invalid label",
+			input: "SELECT * FROM country WHERE Continent = 'Mars'",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 40}, End: lsp.Position{Line: 0, Character: 46}},
+			},
+		},
+		{
+			name:  "invalid label of aliased column on left",
+			input: "SELECT * FROM country c WHERE 'Asya' = c.Continent",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 30}, End: lsp.Position{Line: 0, Character: 36}},
+			},
+		},
+		{
+			name:  "invalid label in IN list",
+			input: "SELECT * FROM countrylanguage WHERE IsOfficial IN ('T', 'X')",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 56}, End: lsp.Position{Line: 0, Character: 59}},
+			},
+		},
+		{
+			name:  "not enum column",
+			input: "SELECT * FROM city WHERE Name = 'Mars'",
+			want:  []lsp.Range{},
+		},
+		{
+			name:  "unterminated literal",
+			input: "SELECT * FROM country WHERE Continent = 'Ma",
+			want:  []lsp.Range{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics, err := diagnose(tt.input, "", tx.server.worker.Cache())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []lsp.Range{}
+			for _, d := range diagnostics {
+				got = append(got, d.Range)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unmatch diagnostic ranges (- want, + got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiagnoseInsert(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	cfg := &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: "mock"},
+		},
+	}
+	tx.addWorkspaceConfig(t, cfg)
+
+	tests := []struct {
+		name   string
+		driver dialect.DatabaseDriver
+		input  string
+		want   []lsp.Range
+	}{
+		{
+			name:  "valid row",
+			input: "INSERT INTO city (ID, Name, CountryCode, District, Population) VALUES (1, 'Tokyo', 'JPN', 'Tokyo-to', 100)",
+			want:  []lsp.Range{},
+		},
+		{
+			name:  "fewer values",
+			input: "INSERT INTO city (ID, Name) VALUES (1)",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 35}, End: lsp.Position{Line: 0, Character: 38}},
+			},
+		},
+		{
+			name:  "more values",
+			input: "INSERT INTO city (ID, Name) VALUES (1, 'a', 'b')",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 44}, End: lsp.Position{Line: 0, Character: 47}},
+			},
+		},
+		{
+			name:  "row without column list",
+			input: "INSERT INTO city VALUES (1, 'a', 'JPN', 'b', 2), (2, 'c')",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 49}, End: lsp.Position{Line: 0, Character: 57}},
+			},
+		},
+		{
+			name:  "string into integer column",
+			input: "INSERT INTO city (ID, Population) VALUES ('12', 'many')",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 48}, End: lsp.Position{Line: 0, Character: 54}},
+			},
+		},
+		{
+			name:  "string over char length",
+			input: "INSERT INTO city (Name, CountryCode) VALUES ('Tokyo', 'JAPAN')",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 54}, End: lsp.Position{Line: 0, Character: 61}},
+			},
+		},
+		{
+			name:   "mysql double quoted string",
+			driver: dialect.DatabaseDriverMySQL,
+			input:  `INSERT INTO city (Name, CountryCode) VALUES ("Tokyo", "JAPAN")`,
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 54}, End: lsp.Position{Line: 0, Character: 61}},
+			},
+		},
+		{
+			name:  "null into not null column",
+			input: "INSERT INTO city (ID, Name) VALUES (NULL, NULL)",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 42}, End: lsp.Position{Line: 0, Character: 46}},
+			},
+		},
+		{
+			name:  "without spaces",
+			input: "INSERT INTO city(ID, Name) VALUES(1),(2, 'a')",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 0, Character: 33}, End: lsp.Position{Line: 0, Character: 36}},
+			},
+		},
+		{
+			name:  "unknown column",
+			input: "INSERT INTO city (ID, Nme) VALUES (1)",
+			want:  []lsp.Range{},
+		},
+		{
+			name:  "second statement",
+			input: "INSERT INTO city (ID) VALUES (1);\nINSERT INTO city (ID) VALUES (1, 2)",
+			want: []lsp.Range{
+				{Start: lsp.Position{Line: 1, Character: 33}, End: lsp.Position{Line: 1, Character: 34}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics, err := diagnose(tt.input, tt.driver, tx.server.worker.Cache())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []lsp.Range{}
+			for _, d := range diagnostics {
+				got = append(got, d.Range)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unmatch diagnostic ranges (- want, + got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_insertColumns(t *testing.T) {
+	repo := database.NewMockDBRepository(nil).(*database.MockDBRepository)
+	repo.MockDescribeDatabaseTableBySchema = func(ctx context.Context, schemaName string) ([]*database.ColumnDesc, error) {
+		column := func(name, extra string) *database.ColumnDesc {
+			return &database.ColumnDesc{
+				ColumnBase: database.ColumnBase{Schema: "world", Table: "orders", Name: name},
+				Type:       "int",
+				Extra:      extra,
+			}
+		}
+		return []*database.ColumnDesc{
+			column("id", "IDENTITY"),
+			column("amount", ""),
+			column("total", "COMPUTED"),
+		}, nil
+	}
+	dbCache, err := database.NewDBCacheUpdater(repo).GenerateDBCachePrimary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+		ok    bool
+	}{
+		{"generated columns left out", "INSERT INTO orders VALUES (1)", nil, false},
+		{"column list", "INSERT INTO orders (amount) VALUES (1)", []string{"amount"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parser.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			insert, err := parseutil.ExtractInsert(parsed, token.Pos{Line: 0, Col: 6})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cols, ok := insertColumns(insert, dbCache)
+			if ok != tt.ok {
+				t.Fatalf("insertColumns() ok = %v, want %v", ok, tt.ok)
+			}
+			var got []string
+			for _, col := range cols {
+				got = append(got, col.Name)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unmatch columns (- want, + got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_literalMismatch(t *testing.T) {
+	length := func(n int64) sql.NullInt64 { return sql.NullInt64{Int64: n, Valid: true} }
+	tests := []struct {
+		name  string
+		value string
+		col   *database.ColumnDesc
+		want  string
+	}{
+		{
+			name:  "postgresql varchar",
+			value: "'JAPAN'",
+			col: &database.ColumnDesc{
+				ColumnBase: database.ColumnBase{Table: "city", Name: "countrycode"},
+				Type:       "character varying",
+				Length:     length(3),
+			},
+			want: "string of length 5 into character varying column city.countrycode",
+		},
+		{
+			name:  "postgresql text",
+			value: "'JAPAN'",
+			col: &database.ColumnDesc{
+				ColumnBase: database.ColumnBase{Table: "city", Name: "name"},
+				Type:       "text",
+			},
+			want: "",
+		},
+		{
+			name:  "mssql nvarchar(max)",
+			value: "'JAPAN'",
+			col: &database.ColumnDesc{
+				ColumnBase: database.ColumnBase{Table: "city", Name: "name"},
+				Type:       "nvarchar",
+				Length:     length(-1),
+			},
+			want: "",
+		},
+		{
+			name:  "oracle varchar2",
+			value: "'JAPAN'",
+			col: &database.ColumnDesc{
+				ColumnBase: database.ColumnBase{Table: "CITY", Name: "COUNTRYCODE"},
+				Type:       "VARCHAR2",
+				Length:     length(5),
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parser.Parse(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value := parseutil.SignificantNodes(parsed.GetTokens()[0].(ast.TokenList))[0]
+			if got := literalMismatch(value, tt.col); got != tt.want {
+				t.Errorf("literalMismatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
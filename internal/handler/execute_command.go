@@ -7,27 +7,48 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
-	"github.com/olekukonko/tablewriter"
-	"github.com/olekukonko/tablewriter/tw"
 	"github.com/sourcegraph/jsonrpc2"
 	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/dialect"
+	"github.com/sqls-server/sqls/internal/config"
 	"github.com/sqls-server/sqls/internal/database"
 	"github.com/sqls-server/sqls/internal/lsp"
 	"github.com/sqls-server/sqls/parser"
+	"github.com/sqls-server/sqls/parser/parseutil"
+	"github.com/sqls-server/sqls/token"
 )
 
 const (
-	CommandExecuteQuery     = "executeQuery"
-	CommandShowDatabases    = "showDatabases"
-	CommandShowSchemas      = "showSchemas"
-	CommandShowConnections  = "showConnections"
-	CommandSwitchDatabase   = "switchDatabase"
-	CommandSwitchConnection = "switchConnections"
-	CommandShowTables       = "showTables"
+	CommandExecuteQuery       = "executeQuery"
+	CommandExecuteQueryToFile = "executeQueryToFile"
+	CommandExplainQuery       = "explainQuery"
+	CommandShowDatabases      = "showDatabases"
+	CommandShowSchemas        = "showSchemas"
+	CommandShowConnections    = "showConnections"
+	CommandSwitchDatabase     = "switchDatabase"
+	CommandSwitchConnection   = "switchConnections"
+	CommandShowTables         = "showTables"
+	CommandShowViews          = "showViews"
+	CommandShowFunctions      = "showFunctions"
+	CommandShowIndexes        = "showIndexes"
+	CommandShowTriggers       = "showTriggers"
+	CommandShowSequences      = "showSequences"
+	CommandShowCreateTable    = "showCreateTable"
+	CommandNextPage           = "nextPage"
+	CommandCloseResultSet     = "closeResultSet"
+	CommandBeginTransaction   = "beginTransaction"
+	CommandCommit             = "commit"
+	CommandRollback           = "rollback"
+	CommandShowStatus         = "showStatus"
+	CommandShowHistory        = "showHistory"
+	CommandSearchHistory      = "searchHistory"
+	CommandRerunHistory       = "rerunHistory"
 )
 
 func (s *Server) handleTextDocumentCodeAction(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
@@ -46,6 +67,26 @@ func (s *Server) handleTextDocumentCodeAction(ctx context.Context, conn *jsonrpc
 			Command:   CommandExecuteQuery,
 			Arguments: []interface{}{params.TextDocument.URI},
 		},
+		{
+			Title:     "Execute Statement",
+			Command:   CommandExecuteQuery,
+			Arguments: []interface{}{params.TextDocument.URI, map[string]interface{}{"position": params.Range.Start}},
+		},
+		{
+			Title:     "Execute To End",
+			Command:   CommandExecuteQuery,
+			Arguments: []interface{}{params.TextDocument.URI, map[string]interface{}{"position": params.Range.Start, "toEnd": true}},
+		},
+		{
+			Title:     "Execute Query To File",
+			Command:   CommandExecuteQueryToFile,
+			Arguments: []interface{}{params.TextDocument.URI},
+		},
+		{
+			Title:     "Explain Query",
+			Command:   CommandExplainQuery,
+			Arguments: []interface{}{params.TextDocument.URI},
+		},
 		{
 			Title:     "Show Databases",
 			Command:   CommandShowDatabases,
@@ -76,10 +117,96 @@ func (s *Server) handleTextDocumentCodeAction(ctx context.Context, conn *jsonrpc
 			Command:   CommandShowTables,
 			Arguments: []interface{}{},
 		},
+		{
+			Title:     "Show Views",
+			Command:   CommandShowViews,
+			Arguments: []interface{}{},
+		},
+		{
+			Title:     "Show Functions",
+			Command:   CommandShowFunctions,
+			Arguments: []interface{}{},
+		},
+		{
+			Title:     "Show Sequences",
+			Command:   CommandShowSequences,
+			Arguments: []interface{}{},
+		},
+		{
+			Title:     "Begin Transaction",
+			Command:   CommandBeginTransaction,
+			Arguments: []interface{}{},
+		},
+		{
+			Title:     "Commit",
+			Command:   CommandCommit,
+			Arguments: []interface{}{},
+		},
+		{
+			Title:     "Rollback",
+			Command:   CommandRollback,
+			Arguments: []interface{}{},
+		},
+		{
+			Title:     "Show Status",
+			Command:   CommandShowStatus,
+			Arguments: []interface{}{},
+		},
+		{
+			Title:     "Show History",
+			Command:   CommandShowHistory,
+			Arguments: []interface{}{},
+		},
 	}
+	commands = append(commands, s.tableCodeActions(params)...)
 	return commands, nil
 }
 
+// tableCodeActions returns the commands about the table whose name is at the
+// start of the range, which take its name as their argument.
+func (s *Server) tableCodeActions(params lsp.CodeActionParams) []lsp.Command {
+	f, ok := s.files[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	parsed, err := parser.ParseFor(f.Text, s.driver())
+	if err != nil {
+		return nil
+	}
+	pos := token.Pos{
+		Line: params.Range.Start.Line,
+		Col:  params.Range.Start.Character + 1,
+	}
+	table, ok := focusedTable(parsed, pos)
+	if !ok {
+		return nil
+	}
+	return []lsp.Command{
+		{
+			Title:     "Show Indexes",
+			Command:   CommandShowIndexes,
+			Arguments: []interface{}{table.Name},
+		},
+		{
+			Title:     "Show Triggers",
+			Command:   CommandShowTriggers,
+			Arguments: []interface{}{table.Name},
+		},
+		{
+			Title:     "Show Create Table",
+			Command:   CommandShowCreateTable,
+			Arguments: []interface{}{qualifiedTableName(table)},
+		},
+	}
+}
+
+func qualifiedTableName(table *parseutil.TableInfo) string {
+	if table.DatabaseSchema == "" {
+		return table.Name
+	}
+	return table.DatabaseSchema + "." + table.Name
+}
+
 func (s *Server) handleWorkspaceExecuteCommand(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
 	if req.Params == nil {
 		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
@@ -92,7 +219,11 @@ func (s *Server) handleWorkspaceExecuteCommand(ctx context.Context, conn *jsonrp
 
 	switch params.Command {
 	case CommandExecuteQuery:
-		return s.executeQuery(ctx, params)
+		return s.executeQuery(ctx, conn, params)
+	case CommandExecuteQueryToFile:
+		return s.executeQueryToFile(ctx, conn, params)
+	case CommandExplainQuery:
+		return s.explainQuery(ctx, params)
 	case CommandShowDatabases:
 		return s.showDatabases(ctx, params)
 	case CommandShowSchemas:
@@ -105,11 +236,41 @@ func (s *Server) handleWorkspaceExecuteCommand(ctx context.Context, conn *jsonrp
 		return s.switchConnections(ctx, params)
 	case CommandShowTables:
 		return s.showTables(ctx, params)
+	case CommandShowViews:
+		return s.showViews(ctx, params)
+	case CommandShowFunctions:
+		return s.showFunctions(ctx, params)
+	case CommandShowIndexes:
+		return s.showIndexes(ctx, params)
+	case CommandShowTriggers:
+		return s.showTriggers(ctx, params)
+	case CommandShowSequences:
+		return s.showSequences(ctx, params)
+	case CommandShowCreateTable:
+		return s.showCreateTable(ctx, conn, params)
+	case CommandNextPage:
+		return s.nextPage(ctx, params)
+	case CommandCloseResultSet:
+		return s.closeResultSetCommand(ctx, params)
+	case CommandBeginTransaction:
+		return s.beginTransaction(ctx, params)
+	case CommandCommit:
+		return s.commit(ctx, params)
+	case CommandRollback:
+		return s.rollback(ctx, params)
+	case CommandShowStatus:
+		return s.showStatus(ctx, params)
+	case CommandShowHistory:
+		return s.showHistory(ctx, params)
+	case CommandSearchHistory:
+		return s.searchHistory(ctx, params)
+	case CommandRerunHistory:
+		return s.rerunHistory(ctx, conn, params)
 	}
 	return nil, fmt.Errorf("unsupported command: %v", params.Command)
 }
 
-func (s *Server) executeQuery(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+func (s *Server) executeQuery(ctx context.Context, conn *jsonrpc2.Conn, params lsp.ExecuteCommandParams) (result interface{}, err error) {
 	// parse execute command arguments
 	if s.dbConn == nil {
 		return nil, errors.New("database connection is not open")
@@ -126,57 +287,355 @@ func (s *Server) executeQuery(ctx context.Context, params lsp.ExecuteCommandPara
 		return nil, fmt.Errorf("document not found, %q", uri)
 	}
 
-	showVertical := false
-	if len(params.Arguments) > 1 {
-		showVerticalFlag, ok := params.Arguments[1].(string)
-		if ok {
-			if showVerticalFlag == "-show-vertical" {
-				showVertical = true
-			}
-		}
+	opts, err := parseExecuteQueryOptions(params.Arguments[1:])
+	if err != nil {
+		return nil, err
 	}
 
 	// extract target query
-	text := f.Text
-	if params.Range != nil {
-		text = extractRangeText(
-			text,
-			params.Range.Start.Line,
-			params.Range.Start.Character,
-			params.Range.End.Line,
-			params.Range.End.Character,
-		)
-	}
-	stmts, err := getStatements(text)
+	var text string
+	if cursor := opts.cursor(params.Range); cursor != nil {
+		text, err = s.statementsFrom(uri, f.Text, *cursor, opts.ToEnd)
+	} else {
+		text, err = s.scriptText(uri, f.Text, params.Range)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stmts, err := getStatements(text, s.driver())
 	if err != nil {
 		return nil, err
 	}
+	return s.runStatements(ctx, conn, uri, batchQueries(stmts), opts)
+}
 
-	// execute statements
-	buf := new(bytes.Buffer)
+// batchQueries returns the queries sending stmts, one for each statement
+// unless the database takes all statements up to a GO line at once.
+func batchQueries(stmts []*ast.Statement) []string {
+	var (
+		queries []string
+		batch   strings.Builder
+	)
 	for _, stmt := range stmts {
-		query := strings.TrimSpace(stmt.String())
-		if query == "" {
+		batch.WriteString(stmt.Query)
+		if !stmt.EndsBatch {
 			continue
 		}
+		if query := strings.TrimSpace(batch.String()); query != "" {
+			queries = append(queries, query)
+		}
+		batch.Reset()
+	}
+	if query := strings.TrimSpace(batch.String()); query != "" {
+		queries = append(queries, query)
+	}
+	return queries
+}
 
+// runStatements runs the statements of an execution in order, recording
+// each in the history, and renders their results. uri is the file the
+// statements come from, if any.
+func (s *Server) runStatements(ctx context.Context, conn *jsonrpc2.Conn, uri string, queries []string, opts *executeQueryOptions) (string, error) {
+	formatter, err := newResultFormatter(opts.Format)
+	if err != nil {
+		return "", err
+	}
+	if err := s.checkStatements(queries, opts.Confirm); err != nil {
+		return "", err
+	}
+	// Bind all statements first, so that none runs when the values of a
+	// later one are missing.
+	bound := make([]*boundQuery, len(queries))
+	for i, query := range queries {
+		b, err := s.bindQuery(ctx, conn, uri, query, paramValues(opts.Params))
+		if err != nil {
+			s.recordHistory(query, time.Now(), nil, err)
+			return "", err
+		}
+		bound[i] = b
+	}
+	cfg := s.getConfig()
+	limit := opts.maxRows(cfg)
+	timeout := opts.queryTimeout(s.curDBCfg)
+	results := []*statementResult{}
+	for i, query := range queries {
+		start := time.Now()
+		if err := s.setStatementTimeout(ctx, timeout); err != nil {
+			s.recordHistory(query, start, nil, err)
+			return "", err
+		}
+		messages := &database.MessageCollector{}
+		sctx, stop := withQueryTimeout(database.WithMessages(ctx, messages), timeout)
+		var res []*statementResult
 		if _, isQuery := database.QueryExecType(query, ""); isQuery {
-			res, err := s.query(ctx, query, showVertical)
-			if err != nil {
-				return nil, err
-			}
-			fmt.Fprintln(buf, res)
+			// Only the last result set can be kept open, as the following
+			// statements may need its connection.
+			res, err = s.query(sctx, bound[i], limit, cfg.AutoLimit, i == len(queries)-1)
 		} else {
-			res, err := s.exec(ctx, query, showVertical)
-			if err != nil {
-				return nil, err
+			var r *statementResult
+			if r, err = s.exec(sctx, bound[i]); err == nil {
+				res = []*statementResult{r}
+			}
+		}
+		stop()
+		if err != nil && errors.Is(context.Cause(sctx), errQueryTimeout) {
+			err = fmt.Errorf("query timed out after %s, %w", timeout, err)
+		}
+		// Messages sent before an error often tell what went wrong.
+		msgs := messages.Take()
+		logServerMessages(ctx, conn, msgs)
+		s.recordHistory(query, start, res, err)
+		if err != nil {
+			return "", err
+		}
+		res[len(res)-1].Messages = msgs
+		results = append(results, res...)
+	}
+
+	return formatResults(formatter, results)
+}
+
+// statementsFrom returns the statement of text enclosing cursor, or that
+// statement and all which follow with toEnd, with their directives applied.
+func (s *Server) statementsFrom(uri, text string, cursor lsp.Position, toEnd bool) (string, error) {
+	stmts, err := getStatements(text, s.driver())
+	if err != nil {
+		return "", err
+	}
+	i := statementAt(stmts, token.Pos{Line: cursor.Line, Col: cursor.Character + 1})
+	if i < 0 {
+		return "", fmt.Errorf("no statement found at the cursor")
+	}
+	// The statements hold all of the text, so joining them gives the text
+	// before and from the cursor without converting any position.
+	var prefix, target strings.Builder
+	for j, stmt := range stmts {
+		switch {
+		case j < i:
+			prefix.WriteString(stmt.String())
+		case j == i || toEnd:
+			target.WriteString(stmt.String())
+		}
+	}
+	return s.scriptTextAfter(uri, prefix.String(), target.String())
+}
+
+// statementAt returns the index of the statement enclosing pos, built as
+// for hover from the cursor. A cursor right between two statements takes
+// the first one, and past the last statement that statement is taken. It
+// is -1 when there is no statement.
+func statementAt(stmts []*ast.Statement, pos token.Pos) int {
+	// pos is one past the cursor, the statement ending right at the cursor
+	// is the one just written.
+	cursor := token.Pos{Line: pos.Line, Col: pos.Col - 1}
+	last := -1
+	for i, stmt := range stmts {
+		if isEmptyStatement(stmt) {
+			continue
+		}
+		if token.ComparePos(cursor, stmt.Pos()) < 0 {
+			break
+		}
+		if token.ComparePos(cursor, stmt.End()) <= 0 {
+			return i
+		}
+		last = i
+	}
+	return last
+}
+
+// isEmptyStatement reports whether stmt holds nothing but a semicolon.
+func isEmptyStatement(stmt *ast.Statement) bool {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(stmt.Query), ";")) == ""
+}
+
+// checkStatements rejects a script before any of it runs when it has
+// statements not known to only read data on a read-only connection, or
+// destructive statements which were not confirmed. Each statement of a
+// query sending a batch of them is checked.
+func (s *Server) checkStatements(queries []string, confirm bool) error {
+	readOnly := s.curDBCfg != nil && s.curDBCfg.ReadOnly
+	for _, q := range queries {
+		stmts, err := getStatements(q, s.driver())
+		if err != nil {
+			return err
+		}
+		for _, stmt := range stmts {
+			query := strings.TrimSpace(stmt.Query)
+			if query == "" {
+				continue
+			}
+			if readOnly && !database.IsReadOnly(query) {
+				return fmt.Errorf("the connection is read-only, cannot run %q", firstLine(query))
+			}
+			if reason := database.ConfirmReason(query); reason != "" && !confirm {
+				return fmt.Errorf("%s in %q, run again with the \"confirm\" option to execute it", reason, firstLine(query))
 			}
-			fmt.Fprintln(buf, res)
 		}
 	}
+	return nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		return s[:i] + " ..."
+	}
+	return s
+}
+
+// logServerMessages sends the messages of the server to the log of the
+// client.
+func logServerMessages(ctx context.Context, conn *jsonrpc2.Conn, messages []database.Message) {
+	if conn == nil {
+		return
+	}
+	for _, m := range messages {
+		typ := lsp.Info
+		switch m.Level {
+		case "WARNING":
+			typ = lsp.Warning
+		case "ERROR":
+			typ = lsp.Error
+		}
+		params := &lsp.LogMessageParams{Type: typ, Message: m.String()}
+		if err := conn.Notify(ctx, "window/logMessage", params); err != nil {
+			log.Println("send server message", err)
+		}
+	}
+}
+
+func formatResults(formatter ResultFormatter, results []*statementResult) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := formatter.Format(buf, results); err != nil {
+		return "", err
+	}
 	return buf.String(), nil
 }
 
+// executeQueryOptions is the optional second argument of executeQuery, given
+// either as an object or as the legacy "-show-vertical" flag.
+type executeQueryOptions struct {
+	Format string `json:"format"`
+	// Limit overrides the configured row limit, a negative value disables it.
+	Limit int `json:"limit"`
+	// Params holds the values of bind parameters, either an object keyed by
+	// name or an array of the values of positional parameters.
+	Params interface{} `json:"params"`
+	// Confirm allows destructive statements, such as a DELETE without a
+	// WHERE clause, to run.
+	Confirm bool `json:"confirm"`
+	// AnyConnection lets rerunHistory run a statement which ran on another
+	// connection.
+	AnyConnection bool `json:"anyConnection"`
+	// Timeout overrides the query timeout of the connection in seconds, a
+	// negative value disables it.
+	Timeout int `json:"timeout"`
+	// Position runs the statement under the cursor instead of the range or
+	// the whole file.
+	Position *lsp.Position `json:"position"`
+	// ToEnd runs from the statement under the cursor to the end of the
+	// file. The cursor is Position, else the start of the range.
+	ToEnd bool `json:"toEnd"`
+}
+
+// cursor returns the position the statements to run are picked at, nil to
+// run the range or the whole file.
+func (opts *executeQueryOptions) cursor(rng *lsp.Range) *lsp.Position {
+	switch {
+	case opts.Position != nil:
+		return opts.Position
+	case !opts.ToEnd:
+		return nil
+	case rng != nil:
+		return &rng.Start
+	}
+	return &lsp.Position{}
+}
+
+// paramValues returns the bind parameter values given with a command by
+// name, positional ones named by their number. Whole JSON numbers are passed
+// on as integers.
+func paramValues(params interface{}) map[string]interface{} {
+	values := map[string]interface{}{}
+	switch v := params.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			values[k] = jsonParamValue(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			values[strconv.Itoa(i+1)] = jsonParamValue(val)
+		}
+	}
+	return values
+}
+
+func jsonParamValue(val interface{}) interface{} {
+	if f, ok := val.(float64); ok && f == float64(int64(f)) {
+		return int64(f)
+	}
+	return val
+}
+
+func (opts *executeQueryOptions) maxRows(cfg *config.Config) int {
+	switch {
+	case opts.Limit < 0:
+		return 0
+	case opts.Limit > 0:
+		return opts.Limit
+	}
+	return cfg.MaxRows()
+}
+
+func (opts *executeQueryOptions) queryTimeout(dbCfg *database.DBConfig) time.Duration {
+	switch {
+	case opts.Timeout < 0:
+		return 0
+	case opts.Timeout > 0:
+		return time.Duration(opts.Timeout) * time.Second
+	case dbCfg != nil:
+		return dbCfg.QueryTimeoutDuration()
+	}
+	return 0
+}
+
+var errQueryTimeout = errors.New("query timeout")
+
+// withQueryTimeout returns a context which is cancelled along with ctx, or
+// once timeout has passed unless stop is called before. Unlike one of
+// context.WithTimeout, it stays usable after stop, as a result set kept open
+// for paging is closed along with the context of its query.
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (tctx context.Context, stop func()) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	tctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(timeout, func() { cancel(errQueryTimeout) })
+	return tctx, func() { timer.Stop() }
+}
+
+func parseExecuteQueryOptions(args []interface{}) (*executeQueryOptions, error) {
+	opts := &executeQueryOptions{}
+	if len(args) == 0 {
+		return opts, nil
+	}
+	switch v := args[0].(type) {
+	case string:
+		if v == "-show-vertical" {
+			opts.Format = ResultFormatVertical
+		}
+	case map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, opts); err != nil {
+			return nil, fmt.Errorf("invalid executeQuery options, %w", err)
+		}
+	}
+	return opts, nil
+}
+
 func extractRangeText(text string, startLine, startChar, endLine, endChar int) string {
 	lines := strings.Split(text, "\n")
 	if startLine < 0 {
@@ -275,82 +734,103 @@ func sliceUTF16(s string, start, end int) string {
 	return s[startByte:endByte]
 }
 
-func (s *Server) query(ctx context.Context, query string, vertical bool) (string, error) {
+// query runs a statement and reads up to limit rows of its result. The rest
+// of the rows are kept open as a result set for the nextPage command, unless
+// autoLimit has the database cut the result short.
+func (s *Server) query(ctx context.Context, q *boundQuery, limit int, autoLimit, keepOpen bool) ([]*statementResult, error) {
 	repo, err := s.newDBRepository(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	rows, err := repo.Query(ctx, query)
+	ex, err := s.executor(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer func() { _ = rows.Close() }()
-	columns, err := database.Columns(rows)
+	execQuery := q.Query
+	limited := false
+	if autoLimit && limit > 0 {
+		// Ask for one more row to tell whether the result was cut short.
+		execQuery, limited = database.LimitQuery(repo.Driver(), q.Query, limit+1)
+	}
+	start := time.Now()
+	rows, err := ex.Query(ctx, execQuery, q.Args...)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	stringRows, err := database.ScanRows(rows, len(columns))
+	reader, err := database.NewResultReader(rows)
 	if err != nil {
-		return "", err
+		_ = rows.Close()
+		return nil, err
 	}
 
-	buf := new(bytes.Buffer)
-	if vertical {
-		table := newVerticalTableWriter(buf)
-		table.setHeaders(columns)
-		for _, stringRow := range stringRows {
-			table.appendRow(stringRow)
+	// Batches and procedure calls may return several result sets, each
+	// reported as a result of its own.
+	var results []*statementResult
+	for {
+		result, more, err := reader.Next(limit)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
 		}
-		table.render()
-	} else {
-		table := tablewriter.NewTable(buf, tablewriter.WithHeaderConfig(tw.CellConfig{
-			Formatting: tw.CellFormatting{AutoFormat: tw.Off},
-		}))
-		// Convert []string to []any for Header
-		headers := make([]any, len(columns))
-		for i, v := range columns {
-			headers[i] = v
-		}
-		table.Header(headers...)
-		for _, stringRow := range stringRows {
-			// Convert []string to []any for Append
-			row := make([]any, len(stringRow))
-			for i, v := range stringRow {
-				row[i] = v
-			}
-			if err := table.Append(row...); err != nil {
-				return "", err
-			}
+		res := &statementResult{Query: q.Text, Result: result, More: more}
+		res.Duration = time.Since(start)
+		if firstRow := reader.FirstRowAt(); !firstRow.IsZero() {
+			res.FirstRow = firstRow.Sub(start)
 		}
-		if err := table.Render(); err != nil {
-			return "", err
+		// Sets without columns come from statements of a batch which
+		// return no rows.
+		if len(result.Columns) > 0 {
+			results = append(results, res)
+		}
+		if more && !limited && keepOpen {
+			// The sets after this one are read by nextPage once it is done.
+			res.ResultSetID = s.openResultSet(q.Text, rows, reader)
+			return results, nil
+		}
+		start = time.Now()
+		ok, err := reader.NextResultSet()
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		if !ok {
+			break
 		}
 	}
-	fmt.Fprintf(buf, "%d rows in set", len(stringRows))
-	fmt.Fprintln(buf, "")
-	fmt.Fprintln(buf, "")
-	return buf.String(), nil
+	_ = rows.Close()
+	s.readMessages(ctx)
+	if len(results) == 0 {
+		results = append(results, &statementResult{
+			Query:    q.Text,
+			Result:   &database.Result{Columns: reader.Columns(), Rows: [][]interface{}{}},
+			Duration: time.Since(start),
+		})
+	}
+	return results, nil
 }
 
-func (s *Server) exec(ctx context.Context, query string, vertical bool) (string, error) {
-	repo, err := s.newDBRepository(ctx)
+func (s *Server) exec(ctx context.Context, q *boundQuery) (*statementResult, error) {
+	ex, err := s.executor(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	result, err := repo.Exec(ctx, query)
+	start := time.Now()
+	result, err := ex.Exec(ctx, q.Query, q.Args...)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	duration := time.Since(start)
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	buf := new(bytes.Buffer)
-	fmt.Fprintf(buf, "Query OK, %d row affected", rowsAffected)
-	fmt.Fprintln(buf, "")
-	fmt.Fprintln(buf, "")
-	return buf.String(), nil
+	res := &statementResult{Query: q.Text, RowsAffected: rowsAffected, Duration: duration}
+	// Not every driver supports LastInsertId, and those that do report 0
+	// for statements which insert nothing.
+	if id, err := result.LastInsertId(); err == nil && id != 0 {
+		res.LastInsertID = &id
+	}
+	return res, nil
 }
 
 func (s *Server) showDatabases(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
@@ -494,8 +974,114 @@ func (s *Server) showTables(ctx context.Context, params lsp.ExecuteCommandParams
 	return strings.Join(results, "\n"), nil
 }
 
-func getStatements(text string) ([]*ast.Statement, error) {
-	parsed, err := parser.Parse(text)
+func (s *Server) showViews(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	repo, err := s.newDBRepository(ctx)
+	if err != nil {
+		return "", err
+	}
+	views, err := repo.Views(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Join(views, "\n"), nil
+}
+
+func (s *Server) showFunctions(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	repo, err := s.newDBRepository(ctx)
+	if err != nil {
+		return "", err
+	}
+	functions, err := repo.Functions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Join(functions, "\n"), nil
+}
+
+func (s *Server) showSequences(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	repo, err := s.newDBRepository(ctx)
+	if err != nil {
+		return "", err
+	}
+	sequences, err := repo.Sequences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Join(sequences, "\n"), nil
+}
+
+func (s *Server) showIndexes(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	tableName, err := tableNameArgument(params)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := s.newDBRepository(ctx)
+	if err != nil {
+		return "", err
+	}
+	indexes, err := repo.Indexes(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	results := []string{}
+	for _, idx := range indexes {
+		res := fmt.Sprintf("%s (%s)", idx.Name, strings.Join(idx.Columns, ", "))
+		if idx.Unique {
+			res += " UNIQUE"
+		}
+		results = append(results, res)
+	}
+	return strings.Join(results, "\n"), nil
+}
+
+func (s *Server) showTriggers(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	tableName, err := tableNameArgument(params)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := s.newDBRepository(ctx)
+	if err != nil {
+		return "", err
+	}
+	triggers, err := repo.Triggers(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Join(triggers, "\n"), nil
+}
+
+// showCreateTable opens the DDL of a table as a read-only document, and
+// returns it for clients which cannot open documents.
+func (s *Server) showCreateTable(ctx context.Context, conn *jsonrpc2.Conn, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	tableName, err := tableNameArgument(params)
+	if err != nil {
+		return nil, err
+	}
+	var schemaName string
+	if i := strings.LastIndex(tableName, "."); i >= 0 {
+		schemaName, tableName = tableName[:i], tableName[i+1:]
+	}
+	uri, ddl, err := s.openCreateTableDocument(ctx, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	s.showDocument(conn, uri, tableNameRange(ddl, tableName))
+	return ddl, nil
+}
+
+func tableNameArgument(params lsp.ExecuteCommandParams) (string, error) {
+	if len(params.Arguments) != 1 {
+		return "", fmt.Errorf("required arguments were not provided: <Table Name>")
+	}
+	tableName, ok := params.Arguments[0].(string)
+	if !ok {
+		return "", fmt.Errorf("specify the table name as a string")
+	}
+	return tableName, nil
+}
+
+func getStatements(text string, driver dialect.DatabaseDriver) ([]*ast.Statement, error) {
+	parsed, err := parser.ParseFor(text, driver)
 	if err != nil {
 		return nil, err
 	}
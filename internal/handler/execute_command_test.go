@@ -2,14 +2,28 @@ package handler
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/olekukonko/tablewriter"
 	"github.com/olekukonko/tablewriter/tw"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/sqls-server/sqls/dialect"
 	"github.com/sqls-server/sqls/internal/config"
 	"github.com/sqls-server/sqls/internal/database"
 	"github.com/sqls-server/sqls/internal/lsp"
+	"github.com/sqls-server/sqls/token"
 )
 
 func Test_executeQuery(t *testing.T) {
@@ -59,6 +73,206 @@ func Test_executeQuery(t *testing.T) {
 	// pass error
 }
 
+func Test_showCatalogObjects(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	didChangeConfigurationParams := lsp.DidChangeConfigurationParams{
+		Settings: struct {
+			SQLS *config.Config "json:\"sqls\""
+		}{
+			SQLS: &config.Config{
+				Connections: []*database.DBConfig{
+					{
+						Driver:         "mock",
+						DataSourceName: "",
+					},
+				},
+			},
+		},
+	}
+	if err := tx.conn.Call(tx.ctx, "workspace/didChangeConfiguration", didChangeConfigurationParams, nil); err != nil {
+		t.Fatal("conn.Call workspace/didChangeConfiguration:", err)
+	}
+
+	tests := []struct {
+		name      string
+		command   string
+		arguments []interface{}
+		want      string
+	}{
+		{"views", CommandShowViews, []interface{}{}, "city_population"},
+		{"functions", CommandShowFunctions, []interface{}{}, "language_count"},
+		{"sequences", CommandShowSequences, []interface{}{}, ""},
+		{"indexes", CommandShowIndexes, []interface{}{"countrylanguage"}, "CountryCode (CountryCode)\nPRIMARY (CountryCode, Language) UNIQUE"},
+		{"triggers", CommandShowTriggers, []interface{}{"city"}, "city_before_insert"},
+		{"create table", CommandShowCreateTable, []interface{}{"world.countrylanguage"}, `CREATE TABLE world.countrylanguage (
+  CountryCode char(3) NOT NULL,
+  Language char(30) NOT NULL,
+  IsOfficial enum('T','F') NOT NULL,
+  Percentage decimal(4,1) NOT NULL,
+  PRIMARY KEY (CountryCode, Language),
+  FOREIGN KEY (CountryCode) REFERENCES world.country (Code)
+);
+CREATE INDEX CountryCode ON world.countrylanguage (CountryCode);
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := lsp.ExecuteCommandParams{
+				Command:   tt.command,
+				Arguments: tt.arguments,
+			}
+			var got string
+			if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err != nil {
+				t.Fatal("conn.Call workspace/executeCommand:", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	params := lsp.ExecuteCommandParams{
+		Command:   CommandShowIndexes,
+		Arguments: []interface{}{},
+	}
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, nil); err == nil {
+		t.Error("expected error for missing table name")
+	}
+}
+
+func Test_tableCodeActions(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	tx.addWorkspaceConfig(t, &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: "mock"},
+		},
+	})
+	tx.textDocumentDidOpen(t, testFileURI, "SELECT * FROM city")
+
+	codeActions := func(character int) map[string]lsp.Command {
+		params := lsp.CodeActionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: testFileURI},
+			Range: lsp.Range{
+				Start: lsp.Position{Line: 0, Character: character},
+				End:   lsp.Position{Line: 0, Character: character},
+			},
+		}
+		var got []lsp.Command
+		if err := tx.conn.Call(tx.ctx, "textDocument/codeAction", params, &got); err != nil {
+			t.Fatal("conn.Call textDocument/codeAction:", err)
+		}
+		commands := map[string]lsp.Command{}
+		for _, c := range got {
+			commands[c.Title] = c
+		}
+		return commands
+	}
+
+	if _, ok := codeActions(1)["Show Indexes"]; ok {
+		t.Error("unexpected table command without a table under the cursor")
+	}
+
+	want := map[string]string{
+		"Show Indexes":      "CountryCode (CountryCode)\nPRIMARY (ID) UNIQUE",
+		"Show Triggers":     "city_before_insert",
+		"Show Create Table": "CREATE TABLE world.city (",
+	}
+	commands := codeActions(16)
+	for title, output := range want {
+		command, ok := commands[title]
+		if !ok {
+			t.Errorf("no %q code action", title)
+			continue
+		}
+		params := lsp.ExecuteCommandParams{
+			Command:   command.Command,
+			Arguments: command.Arguments,
+		}
+		var got string
+		if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err != nil {
+			t.Errorf("%s: %+v", title, err)
+			continue
+		}
+		if !strings.HasPrefix(got, output) {
+			t.Errorf("%s: got %q, want %q", title, got, output)
+		}
+	}
+}
+
+func Test_showCreateTableOpensDocument(t *testing.T) {
+	tx := newTestContext()
+	shown := make(chan lsp.ShowDocumentParams, 1)
+	tx.h = jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		if req.Method == "window/showDocument" {
+			var params lsp.ShowDocumentParams
+			if err := json.Unmarshal(*req.Params, &params); err != nil {
+				return nil, err
+			}
+			shown <- params
+			return lsp.ShowDocumentResult{Success: true}, nil
+		}
+		return tx.server.Handle(ctx, conn, req)
+	})
+	tx.setup(t)
+	defer tx.tearDown()
+	tx.server.canShowDocument = true
+
+	tx.addWorkspaceConfig(t, &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: "mock"},
+		},
+	})
+	params := lsp.ExecuteCommandParams{
+		Command:   CommandShowCreateTable,
+		Arguments: []interface{}{"world.city"},
+	}
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, nil); err != nil {
+		t.Fatal("conn.Call workspace/executeCommand:", err)
+	}
+
+	var got lsp.ShowDocumentParams
+	select {
+	case got = <-shown:
+	case <-time.After(time.Second):
+		t.Fatal("window/showDocument was not sent")
+	}
+	if got.URI != ddlDocumentURI("world", "city") {
+		t.Errorf("unexpected document %q", got.URI)
+	}
+	if _, ok := tx.server.virtualDocs[got.URI]; !ok {
+		t.Fatalf("document %q is not registered", got.URI)
+	}
+
+	closeParams := lsp.DidCloseTextDocumentParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: got.URI},
+	}
+	if err := tx.conn.Call(tx.ctx, "textDocument/didClose", closeParams, nil); err != nil {
+		t.Fatal("conn.Call textDocument/didClose:", err)
+	}
+	if _, ok := tx.server.virtualDocs[got.URI]; ok {
+		t.Errorf("document %q is kept after it was closed", got.URI)
+	}
+}
+
+func Test_virtualDocumentsAreBounded(t *testing.T) {
+	s := &Server{virtualDocs: map[string]string{}}
+	for i := 0; i <= maxVirtualDocuments; i++ {
+		s.putVirtualDocument(ddlDocumentURI("world", fmt.Sprintf("t%d", i)), "")
+	}
+	if len(s.virtualDocs) != maxVirtualDocuments || len(s.virtualDocURIs) != maxVirtualDocuments {
+		t.Fatalf("got %d documents, want %d", len(s.virtualDocs), maxVirtualDocuments)
+	}
+	if _, ok := s.virtualDocs[ddlDocumentURI("world", "t0")]; ok {
+		t.Error("the oldest document is kept")
+	}
+}
+
 func Test_queryResultHeaderPreservesColumnNames(t *testing.T) {
 	// Regression test: column names with underscores should not be
 	// auto-formatted (e.g. "user_name" must not become "USER NAME").
@@ -172,3 +386,517 @@ func Test_extractRangeText(t *testing.T) {
 		})
 	}
 }
+
+func Test_nextPageUnknownResultSet(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	params := lsp.ExecuteCommandParams{
+		Command:   CommandNextPage,
+		Arguments: []interface{}{"1"},
+	}
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, nil); err == nil {
+		t.Error("expected error for unknown result set")
+	}
+}
+
+func Test_executeQueryCallResultSets(t *testing.T) {
+	registerProcedureTestDriver()
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	cfg := &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: procedureTestDriverName},
+		},
+	}
+	tx.addWorkspaceConfig(t, cfg)
+
+	for _, text := range []string{"CALL city_stats()", "EXECUTE city_stats"} {
+		t.Run(text, func(t *testing.T) {
+			tx.textDocumentDidOpen(t, testFileURI, text)
+			params := lsp.ExecuteCommandParams{
+				Command:   CommandExecuteQuery,
+				Arguments: []interface{}{testFileURI, map[string]interface{}{"format": ResultFormatJSON}},
+			}
+			var got string
+			if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err != nil {
+				t.Fatal("conn.Call workspace/executeCommand:", err)
+			}
+			for _, want := range []string{`"name"`, `"Kabul"`, `"country"`, `"AFG"`} {
+				if !strings.Contains(got, want) {
+					t.Errorf("result %s does not have %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+const procedureTestDriverName = "procedure_test"
+
+var registerProcedureTestDriverOnce sync.Once
+
+// registerProcedureTestDriver registers a connection whose queries return
+// two result sets, as a procedure call does. It cannot run anything else.
+func registerProcedureTestDriver() {
+	registerProcedureTestDriverOnce.Do(func() {
+		sql.Register(procedureTestDriverName, procedureTestDriver{})
+		database.RegisterOpen(procedureTestDriverName, func(*database.DBConfig) (*database.DBConnection, error) {
+			db, err := sql.Open(procedureTestDriverName, "")
+			if err != nil {
+				return nil, err
+			}
+			return &database.DBConnection{Conn: db}, nil
+		})
+		database.RegisterFactory(procedureTestDriverName, database.NewMockDBRepository)
+	})
+}
+
+type procedureTestDriver struct{}
+
+func (procedureTestDriver) Open(string) (driver.Conn, error) {
+	return procedureTestConn{}, nil
+}
+
+type procedureTestConn struct{}
+
+func (procedureTestConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (procedureTestConn) Close() error {
+	return nil
+}
+
+func (procedureTestConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (procedureTestConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &procedureTestRows{sets: [][][]driver.Value{
+		{{"name"}, {"Kabul"}, {"Qandahar"}},
+		{{"country", "count"}, {"AFG", int64(4)}},
+	}}, nil
+}
+
+// procedureTestRows holds result sets as the column names followed by the
+// rows.
+type procedureTestRows struct {
+	sets [][][]driver.Value
+	set  int
+	row  int
+}
+
+func (r *procedureTestRows) Columns() []string {
+	var columns []string
+	for _, name := range r.sets[r.set][0] {
+		columns = append(columns, name.(string))
+	}
+	return columns
+}
+
+func (r *procedureTestRows) Close() error {
+	return nil
+}
+
+func (r *procedureTestRows) Next(dest []driver.Value) error {
+	rows := r.sets[r.set][1:]
+	if r.row >= len(rows) {
+		return io.EOF
+	}
+	copy(dest, rows[r.row])
+	r.row++
+	return nil
+}
+
+func (r *procedureTestRows) HasNextResultSet() bool {
+	return r.set+1 < len(r.sets)
+}
+
+func (r *procedureTestRows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.set++
+	r.row = 0
+	return nil
+}
+
+func Test_transactionWithoutSession(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	cfg := &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: "mock"},
+		},
+	}
+	tx.addWorkspaceConfig(t, cfg)
+
+	// The mock connection has no pool to pin a connection from.
+	params := lsp.ExecuteCommandParams{Command: CommandBeginTransaction}
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, nil); err == nil {
+		t.Error("expected error for beginTransaction without a session")
+	}
+
+	var got string
+	params = lsp.ExecuteCommandParams{Command: CommandShowStatus}
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err != nil {
+		t.Fatal("conn.Call workspace/executeCommand:", err)
+	}
+	want := "Connection: 1 mock\nTransaction: not supported"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func Test_executeQueryBindParams(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	cfg := &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: "mock"},
+		},
+	}
+	tx.addWorkspaceConfig(t, cfg)
+
+	tests := []struct {
+		name    string
+		text    string
+		opts    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "missing values",
+			text:    "UPDATE city SET Name = ? WHERE ID = ?",
+			wantErr: true,
+		},
+		{
+			name: "command arguments",
+			text: "UPDATE city SET Name = ? WHERE ID = ?",
+			opts: map[string]interface{}{"params": map[string]interface{}{"1": "Kabul", "2": 1}},
+		},
+		{
+			name: "param comments",
+			text: "-- @param 2 = 1\n-- @param 1 = 'Kabul'\nUPDATE city SET Name = ? WHERE ID = ?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx.textDocumentDidOpen(t, testFileURI, tt.text)
+			args := []interface{}{testFileURI}
+			if tt.opts != nil {
+				args = append(args, tt.opts)
+			}
+			params := lsp.ExecuteCommandParams{
+				Command:   CommandExecuteQuery,
+				Arguments: args,
+			}
+			var got string
+			err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error for missing bind parameter values")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("conn.Call workspace/executeCommand:", err)
+			}
+			if !strings.HasPrefix(got, "Query OK, 22 row affected") {
+				t.Errorf("unexpected result %q", got)
+			}
+		})
+	}
+}
+
+func Test_executeQueryBindsBeforeRunning(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	cfg := &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: "mock"},
+		},
+	}
+	tx.addWorkspaceConfig(t, cfg)
+	tx.textDocumentDidOpen(t, testFileURI, "UPDATE city SET Name = 'Kabul' WHERE ID = 1;\nUPDATE city SET Name = ? WHERE ID = 2")
+
+	params := lsp.ExecuteCommandParams{
+		Command:   CommandExecuteQuery,
+		Arguments: []interface{}{testFileURI},
+	}
+	var got string
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err == nil {
+		t.Fatal("expected error for missing bind parameter values")
+	}
+
+	// The first statement did not run before the error.
+	entries, err := tx.server.history.entries(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !strings.HasPrefix(entries[0].Query, "UPDATE city SET Name = ?") || entries[0].Error == "" {
+		t.Errorf("unexpected history entries %+v", entries)
+	}
+}
+
+func Test_executeQueryGuards(t *testing.T) {
+	tests := []struct {
+		name     string
+		readOnly bool
+		text     string
+		opts     map[string]interface{}
+		wantErr  string
+	}{
+		{
+			name:    "delete without where",
+			text:    "DELETE FROM city",
+			wantErr: "DELETE without a WHERE clause",
+		},
+		{
+			name: "confirmed delete",
+			text: "DELETE FROM city",
+			opts: map[string]interface{}{"confirm": true},
+		},
+		{
+			name:    "drop in a script",
+			text:    "UPDATE city SET Name = 'Kabul' WHERE ID = 1; DROP TABLE city;",
+			wantErr: "DROP TABLE",
+		},
+		{
+			name:     "read-only connection",
+			readOnly: true,
+			text:     "UPDATE city SET Name = 'Kabul' WHERE ID = 1",
+			opts:     map[string]interface{}{"confirm": true},
+			wantErr:  "read-only",
+		},
+		{
+			name:     "procedure on a read-only connection",
+			readOnly: true,
+			text:     "EXEC dbo.purge_cities",
+			wantErr:  "read-only",
+		},
+		{
+			name:    "delete after a with clause",
+			text:    "WITH old AS (SELECT ID FROM city WHERE ID < 10) DELETE FROM city",
+			wantErr: "DELETE without a WHERE clause",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The connection is opened with the first configuration only.
+			tx := newTestContext()
+			tx.setup(t)
+			defer tx.tearDown()
+
+			tx.addWorkspaceConfig(t, &config.Config{
+				Connections: []*database.DBConfig{
+					{Driver: "mock", ReadOnly: tt.readOnly},
+				},
+			})
+			tx.textDocumentDidOpen(t, testFileURI, tt.text)
+			args := []interface{}{testFileURI}
+			if tt.opts != nil {
+				args = append(args, tt.opts)
+			}
+			params := lsp.ExecuteCommandParams{
+				Command:   CommandExecuteQuery,
+				Arguments: args,
+			}
+			var got string
+			err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("conn.Call workspace/executeCommand:", err)
+			}
+			if !strings.HasPrefix(got, "Query OK") {
+				t.Errorf("unexpected result %q", got)
+			}
+		})
+	}
+}
+
+func Test_checkStatementsInBatches(t *testing.T) {
+	stmts, err := getStatements("SELECT 1;\nDELETE FROM orders\nGO\nSELECT 2;\nSELECT 3\n", dialect.DatabaseDriverMssql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := batchQueries(stmts)
+	if diff := cmp.Diff([]string{"SELECT 1;\nDELETE FROM orders", "SELECT 2;\nSELECT 3"}, queries); diff != "" {
+		t.Fatalf("unmatch batches (- want, + got):\n%s", diff)
+	}
+	tests := []struct {
+		name     string
+		readOnly bool
+		confirm  bool
+		wantErr  string
+	}{
+		{"read-only connection", true, true, "read-only, cannot run \"DELETE FROM orders\""},
+		{"unconfirmed delete", false, false, "DELETE without a WHERE clause"},
+		{"confirmed delete", false, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{
+				curDBCfg: &database.DBConfig{Driver: dialect.DatabaseDriverMssql, ReadOnly: tt.readOnly},
+				dbConn:   &database.DBConnection{Driver: dialect.DatabaseDriverMssql},
+			}
+			err := s.checkStatements(queries, tt.confirm)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_statementAt(t *testing.T) {
+	stmts, err := getStatements("SELECT 1;\nSELECT 2;\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Positions are built as for hover, one past the cursor.
+	tests := []struct {
+		name string
+		pos  token.Pos
+		want int
+	}{
+		{"start", token.Pos{Line: 0, Col: 1}, 0},
+		{"first", token.Pos{Line: 0, Col: 4}, 0},
+		{"end of first", token.Pos{Line: 0, Col: 10}, 0},
+		{"second", token.Pos{Line: 1, Col: 4}, 1},
+		{"past the end", token.Pos{Line: 2, Col: 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statementAt(stmts, tt.pos); got != tt.want {
+				t.Errorf("statementAt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_executeQueryPosition(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	tx.addWorkspaceConfig(t, &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: "mock"},
+		},
+	})
+	text := "\\set id 2\nUPDATE city SET Name = 'a' WHERE ID = 1;\n\nUPDATE city SET Name = 'b'\n WHERE ID = ${id};\nUPDATE city SET Name = 'c' WHERE ID = 3;\n"
+	tx.textDocumentDidOpen(t, testFileURI, text)
+
+	tests := []struct {
+		name string
+		opts map[string]interface{}
+		want []string
+	}{
+		{
+			name: "first statement",
+			opts: map[string]interface{}{"position": lsp.Position{Line: 1, Character: 3}},
+			want: []string{"UPDATE city SET Name = 'a' WHERE ID = 1;"},
+		},
+		{
+			name: "end of a statement",
+			opts: map[string]interface{}{"position": lsp.Position{Line: 1, Character: 40}},
+			want: []string{"UPDATE city SET Name = 'a' WHERE ID = 1;"},
+		},
+		{
+			name: "statement over lines keeps variables",
+			opts: map[string]interface{}{"position": lsp.Position{Line: 4, Character: 2}},
+			want: []string{"UPDATE city SET Name = 'b'\n WHERE ID = 2;"},
+		},
+		{
+			name: "past the last statement",
+			opts: map[string]interface{}{"position": lsp.Position{Line: 6, Character: 0}},
+			want: []string{"UPDATE city SET Name = 'c' WHERE ID = 3;"},
+		},
+		{
+			name: "to end",
+			opts: map[string]interface{}{"position": lsp.Position{Line: 2, Character: 0}, "toEnd": true},
+			want: []string{
+				"UPDATE city SET Name = 'b'\n WHERE ID = 2;",
+				"UPDATE city SET Name = 'c' WHERE ID = 3;",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx.server.history = newHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+			params := lsp.ExecuteCommandParams{
+				Command:   CommandExecuteQuery,
+				Arguments: []interface{}{testFileURI, tt.opts},
+			}
+			var got string
+			if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err != nil {
+				t.Fatal("conn.Call workspace/executeCommand:", err)
+			}
+			entries, err := tx.server.history.entries(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var queries []string
+			for _, e := range entries {
+				queries = append(queries, e.Query)
+			}
+			if diff := cmp.Diff(tt.want, queries); diff != "" {
+				t.Errorf("unmatch statements (- want, + got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_withQueryTimeout(t *testing.T) {
+	ctx, stop := withQueryTimeout(context.Background(), 10*time.Millisecond)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context was not cancelled after the timeout")
+	}
+	stop()
+	if !errors.Is(context.Cause(ctx), errQueryTimeout) {
+		t.Errorf("cause = %v, want %v", context.Cause(ctx), errQueryTimeout)
+	}
+
+	ctx, stop = withQueryTimeout(context.Background(), 10*time.Millisecond)
+	stop()
+	time.Sleep(20 * time.Millisecond)
+	if err := ctx.Err(); err != nil {
+		t.Errorf("context was cancelled after stop, %v", err)
+	}
+
+	dbCfg := &database.DBConfig{QueryTimeout: 30}
+	for _, tt := range []struct {
+		timeout int
+		want    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{5, 5 * time.Second},
+		{-1, 0},
+	} {
+		opts := &executeQueryOptions{Timeout: tt.timeout}
+		if got := opts.queryTimeout(dbCfg); got != tt.want {
+			t.Errorf("queryTimeout() with %d = %s, want %s", tt.timeout, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+const (
+	PlanFormatText = "text"
+	PlanFormatJSON = "json"
+)
+
+type explainQueryOptions struct {
+	Format string `json:"format"`
+}
+
+func (s *Server) explainQuery(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if s.dbConn == nil {
+		return nil, errors.New("database connection is not open")
+	}
+	if len(params.Arguments) == 0 {
+		return nil, fmt.Errorf("required arguments were not provided: <File URI>")
+	}
+	uri, ok := params.Arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("specify the file uri as a string")
+	}
+	f, ok := s.files[uri]
+	if !ok {
+		return nil, fmt.Errorf("document not found, %q", uri)
+	}
+	opts := &explainQueryOptions{}
+	if len(params.Arguments) > 1 {
+		if v, ok := params.Arguments[1].(map[string]interface{}); ok {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(b, opts); err != nil {
+				return nil, fmt.Errorf("invalid explainQuery options, %w", err)
+			}
+		}
+	}
+
+	// Directives are applied as for executeQuery, taking the statement
+	// under the cursor before its variables are replaced.
+	var text string
+	if params.Range != nil {
+		text, err = s.statementsFrom(uri, f.Text, params.Range.Start, false)
+	} else {
+		text, err = s.scriptText(uri, f.Text, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stmts, err := getStatements(text, s.driver())
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := onlyStatement(stmts)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.newDBRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ex, err := s.executor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := database.Explain(ctx, repo, ex, strings.TrimSpace(stmt.Query))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	switch strings.ToLower(opts.Format) {
+	case "", PlanFormatText:
+		renderPlanText(buf, plan)
+	case PlanFormatJSON:
+		enc := json.NewEncoder(buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(plan); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported plan format: %q", opts.Format)
+	}
+	return buf.String(), nil
+}
+
+// onlyStatement returns the single statement of stmts, which is the one at
+// the cursor when the command was given a range.
+func onlyStatement(stmts []*ast.Statement) (*ast.Statement, error) {
+	var nonEmpty []*ast.Statement
+	for _, stmt := range stmts {
+		if !isEmptyStatement(stmt) {
+			nonEmpty = append(nonEmpty, stmt)
+		}
+	}
+	if len(nonEmpty) != 1 {
+		return nil, fmt.Errorf("place the cursor on the statement to explain")
+	}
+	return nonEmpty[0], nil
+}
+
+// renderPlanText writes the plan as an indented tree, one node per line,
+// flagging full table scans and the nodes doing most of the work.
+func renderPlanText(w io.Writer, plan *database.Plan) {
+	if len(plan.Roots) == 0 {
+		fmt.Fprintln(w, "(empty plan)")
+		return
+	}
+	for _, root := range plan.Roots {
+		fmt.Fprintln(w, planNodeLine(root))
+		renderPlanChildren(w, root.Children, "")
+	}
+}
+
+func renderPlanChildren(w io.Writer, children []*database.PlanNode, indent string) {
+	for i, c := range children {
+		branch, next := "├─ ", "│  "
+		if i == len(children)-1 {
+			branch, next = "└─ ", "   "
+		}
+		fmt.Fprintln(w, indent+branch+planNodeLine(c))
+		renderPlanChildren(w, c.Children, indent+next)
+	}
+}
+
+func planNodeLine(n *database.PlanNode) string {
+	var b strings.Builder
+	b.WriteString(n.Operation)
+	if n.Object != "" {
+		b.WriteString(" on ")
+		b.WriteString(n.Object)
+	}
+	var estimates []string
+	if n.Cost != nil {
+		estimates = append(estimates, fmt.Sprintf("cost=%.2f", *n.Cost))
+	}
+	if n.Rows != nil {
+		estimates = append(estimates, fmt.Sprintf("rows=%.0f", *n.Rows))
+	}
+	if len(estimates) > 0 {
+		fmt.Fprintf(&b, "  (%s)", strings.Join(estimates, " "))
+	}
+	if n.Detail != "" {
+		fmt.Fprintf(&b, "  %s", n.Detail)
+	}
+	if n.SeqScan {
+		b.WriteString("  [SEQ SCAN]")
+	}
+	if n.HighCost {
+		b.WriteString("  [HIGH COST]")
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sqls-server/sqls/internal/config"
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+func TestExplainQuery(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	cfg := &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: "mock"},
+		},
+	}
+	tx.addWorkspaceConfig(t, cfg)
+	tx.textDocumentDidOpen(t, testFileURI, "SELECT 1;\nSELECT * FROM city JOIN country ON city.CountryCode = country.Code;")
+
+	params := lsp.ExecuteCommandParams{
+		Command:   CommandExplainQuery,
+		Arguments: []interface{}{testFileURI},
+		Range: &lsp.Range{
+			Start: lsp.Position{Line: 1, Character: 3},
+			End:   lsp.Position{Line: 1, Character: 3},
+		},
+	}
+	var got string
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err != nil {
+		t.Fatal("conn.Call workspace/executeCommand:", err)
+	}
+	want := `Hash Join  (cost=150.50 rows=4079)  Join Type: Inner, Hash Cond: (city.countrycode = country.code)
+├─ Seq Scan on world.city  (cost=120.00 rows=4079)  [SEQ SCAN]  [HIGH COST]
+└─ Hash  (cost=12.00 rows=239)
+   └─ Index Scan on world.country  (cost=9.50 rows=239)  Index Name: country_pkey
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unmatch result (- want, + got):\n%s", diff)
+	}
+
+	// Without a cursor the statement to explain is ambiguous.
+	params.Range = nil
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err == nil {
+		t.Error("expected error without a cursor")
+	}
+}
+
+const explainTestDriverName = "explain_test"
+
+var (
+	registerExplainTestDriverOnce sync.Once
+	// explainedQueries holds the queries the explain test driver was asked
+	// to explain.
+	explainedQueries []string
+)
+
+// registerExplainTestDriver registers a mock connection which records the
+// queries it explains.
+func registerExplainTestDriver() {
+	registerExplainTestDriverOnce.Do(func() {
+		database.RegisterOpen(explainTestDriverName, func(*database.DBConfig) (*database.DBConnection, error) {
+			return &database.DBConnection{}, nil
+		})
+		database.RegisterFactory(explainTestDriverName, func(conn *sql.DB) database.DBRepository {
+			repo := database.NewMockDBRepository(conn).(*database.MockDBRepository)
+			explain := repo.MockExplain
+			repo.MockExplain = func(ctx context.Context, query string) (*database.Plan, error) {
+				explainedQueries = append(explainedQueries, query)
+				return explain(ctx, query)
+			}
+			return repo
+		})
+	})
+}
+
+func TestExplainQuery_directives(t *testing.T) {
+	registerExplainTestDriver()
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	cfg := &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: explainTestDriverName},
+		},
+	}
+	tx.addWorkspaceConfig(t, cfg)
+	tx.textDocumentDidOpen(t, testFileURI, "\\set t city\nSELECT 1;\nSELECT * FROM ${t};")
+
+	params := lsp.ExecuteCommandParams{
+		Command:   CommandExplainQuery,
+		Arguments: []interface{}{testFileURI},
+		Range: &lsp.Range{
+			Start: lsp.Position{Line: 2, Character: 3},
+			End:   lsp.Position{Line: 2, Character: 3},
+		},
+	}
+	explainedQueries = nil
+	var got string
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err != nil {
+		t.Fatal("conn.Call workspace/executeCommand:", err)
+	}
+	if diff := cmp.Diff([]string{"SELECT * FROM city"}, explainedQueries); diff != "" {
+		t.Errorf("unmatch explained queries (- want, + got):\n%s", diff)
+	}
+}
+
+func Test_onlyStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"single", "SELECT 1;\n", "SELECT 1;", false},
+		{"many", "SELECT 1;\nSELECT 2;\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := getStatements(tt.text, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := onlyStatement(stmts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("onlyStatement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("onlyStatement() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/sourcegraph/jsonrpc2"
 
+	"github.com/sqls-server/sqls/dialect"
 	"github.com/sqls-server/sqls/internal/config"
 	"github.com/sqls-server/sqls/internal/database"
 	"github.com/sqls-server/sqls/internal/lsp"
@@ -25,6 +26,9 @@ type Server struct {
 	WSCfg           *config.Config
 
 	dbConn *database.DBConnection
+	// session pins a connection for executions, nil when the connection
+	// cannot be pinned.
+	session *database.Session
 
 	curDBCfg           *database.DBConfig
 	curDBName          string
@@ -38,6 +42,23 @@ type Server struct {
 
 	worker *database.Worker
 	files  map[string]*File
+	// Virtual documents generated by the server, oldest first in
+	// virtualDocURIs.
+	virtualDocs    map[string]string
+	virtualDocURIs []string
+
+	// Result sets kept open for paging, oldest first in resultSetIDs.
+	resultSets   map[string]*resultSet
+	resultSetIDs []string
+	resultSetSeq int
+
+	bindParams *bindParamStore
+	// canPrompt tells whether the client answers window/showMessageRequest.
+	canPrompt bool
+	// canShowDocument tells whether the client answers window/showDocument.
+	canShowDocument bool
+
+	history *historyStore
 }
 
 type File struct {
@@ -50,8 +71,12 @@ func NewServer() *Server {
 	worker.Start()
 
 	return &Server{
-		files:  make(map[string]*File),
-		worker: worker,
+		files:       make(map[string]*File),
+		virtualDocs: make(map[string]string),
+		resultSets:  make(map[string]*resultSet),
+		bindParams:  newBindParamStore(),
+		history:     newHistoryStore(config.HistoryFilePath),
+		worker:      worker,
 	}
 }
 
@@ -129,7 +154,11 @@ func (s *Server) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.
 		return s.handleDefinition(ctx, conn, req)
 	case "textDocument/typeDefinition":
 		return s.handleDefinition(ctx, conn, req)
-	case "window/showMessage":
+	case "sqls/virtualDocument":
+		return s.handleVirtualDocument(ctx, conn, req)
+	case "window/showMessage", "window/logMessage":
+		return
+	case "textDocument/publishDiagnostics":
 		return
 	}
 	return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", req.Method)}
@@ -168,6 +197,8 @@ func (s *Server) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req
 	}
 
 	s.initOptionDBConfig = params.InitializationOptions.ConnectionConfig
+	s.canPrompt = params.Capabilities.Window != nil && params.Capabilities.Window.ShowMessage != nil
+	s.canShowDocument = params.Capabilities.Window != nil && params.Capabilities.Window.ShowDocument != nil && params.Capabilities.Window.ShowDocument.Support
 
 	// Initialize database database connection
 	// NOTE: If no connection is found at this point, it is possible that the connection settings are sent to workspace config, so don't make an error
@@ -189,6 +220,7 @@ func (s *Server) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req
 }
 
 func (s *Server) handleShutdown(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
+	s.closeSession()
 	if s.dbConn != nil {
 		s.dbConn.Close()
 	}
@@ -196,6 +228,7 @@ func (s *Server) handleShutdown(ctx context.Context, conn *jsonrpc2.Conn, req *j
 }
 
 func (s *Server) handleExit(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
+	s.closeSession()
 	if s.dbConn != nil {
 		s.dbConn.Close()
 	}
@@ -219,6 +252,7 @@ func (s *Server) handleTextDocumentDidOpen(ctx context.Context, conn *jsonrpc2.C
 	if err := s.updateFile(params.TextDocument.URI, params.TextDocument.Text); err != nil {
 		return nil, err
 	}
+	s.publishDiagnostics(ctx, conn, params.TextDocument.URI)
 	return nil, nil
 }
 
@@ -238,6 +272,7 @@ func (s *Server) handleTextDocumentDidChange(ctx context.Context, conn *jsonrpc2
 	if err := s.updateFile(params.TextDocument.URI, params.ContentChanges[0].Text); err != nil {
 		return nil, err
 	}
+	s.publishDiagnostics(ctx, conn, params.TextDocument.URI)
 	return nil, nil
 }
 
@@ -289,6 +324,8 @@ func (s *Server) openFile(uri string, languageID string) error {
 
 func (s *Server) closeFile(uri string) error {
 	delete(s.files, uri)
+	s.bindParams.forget(uri)
+	s.closeVirtualDocument(uri)
 	return nil
 }
 
@@ -338,6 +375,7 @@ func (s *Server) handleWorkspaceDidChangeConfiguration(ctx context.Context, conn
 }
 
 func (s *Server) reconnectionDB(ctx context.Context) error {
+	s.closeSession()
 	if err := s.dbConn.Close(); err != nil {
 		return err
 	}
@@ -347,11 +385,12 @@ func (s *Server) reconnectionDB(ctx context.Context) error {
 		return err
 	}
 	s.dbConn = dbConn
+	s.openSession()
 	dbRepo, err := s.newDBRepository(ctx)
 	if err != nil {
 		return err
 	}
-	if err := s.worker.ReCache(ctx, dbRepo); err != nil {
+	if err := s.worker.ReCache(ctx, dbRepo, s.curDBCfg.IntrospectionTimeoutDuration()); err != nil {
 		return err
 	}
 	return nil
@@ -414,6 +453,15 @@ func (s *Server) getConnection(index int) *database.DBConfig {
 	return cfg.Connections[index]
 }
 
+// driver returns the driver of the open connection, which tells how scripts
+// are parsed, or "" without a connection.
+func (s *Server) driver() dialect.DatabaseDriver {
+	if s.dbConn == nil {
+		return ""
+	}
+	return s.dbConn.Driver
+}
+
 func (s *Server) getConfig() *config.Config {
 	var cfg *config.Config
 	switch {
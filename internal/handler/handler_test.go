@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"net"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -59,6 +60,9 @@ func (tx *TestContext) tearDown() {
 func (tx *TestContext) initServer(t *testing.T) {
 	t.Helper()
 
+	// Keep the statements run by tests out of the history of the user.
+	tx.server.history = newHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+
 	// Prepare the server and client connection.
 	client, server := net.Pipe()
 	tx.connServer = jsonrpc2.NewConn(tx.ctx, jsonrpc2.NewBufferedStream(server, jsonrpc2.VSCodeObjectCodec{}), tx.h)
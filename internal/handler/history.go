@@ -0,0 +1,320 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+// defaultHistoryLimit is the number of entries showHistory and
+// searchHistory list when no limit is given.
+const defaultHistoryLimit = 20
+
+// historyEntry is a statement run by executeQuery. Its ID is its line
+// number in the history file, which stays the same as the file is only
+// ever appended to.
+type historyEntry struct {
+	ID         int       `json:"-"`
+	Time       time.Time `json:"time"`
+	Connection string    `json:"connection,omitempty"`
+	Database   string    `json:"database,omitempty"`
+	Query      string    `json:"query"`
+	DurationMs int64     `json:"durationMs"`
+	// Rows is the number of rows read by a query, or affected by
+	// another statement. More tells that the query had rows left unread.
+	Rows  int64  `json:"rows"`
+	More  bool   `json:"more,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// historyStore is an append-only file of the statements run, one JSON
+// object per line.
+type historyStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newHistoryStore(path string) *historyStore {
+	return &historyStore{path: path}
+}
+
+func (h *historyStore) add(e *historyEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+	// Statements often hold data of their own, keep them to the user.
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// entries returns the entries accepted by match, oldest first. Lines which
+// cannot be read are skipped but keep their ID.
+func (h *historyStore) entries(match func(*historyEntry) bool) ([]*historyEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []*historyEntry
+	r := bufio.NewReader(f)
+	for id := 1; ; id++ {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			e := &historyEntry{}
+			if json.Unmarshal(line, e) == nil {
+				e.ID = id
+				if match == nil || match(e) {
+					entries = append(entries, e)
+				}
+			}
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (h *historyStore) entry(id int) (*historyEntry, error) {
+	entries, err := h.entries(func(e *historyEntry) bool { return e.ID == id })
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("history entry %d not found", id)
+	}
+	return entries[0], nil
+}
+
+// recordHistory adds a statement to the history. A query whose result set
+// is kept open for paging is recorded once the result set is closed, with
+// all the rows read by then. The execution goes on when the statement
+// cannot be recorded.
+func (s *Server) recordHistory(query string, start time.Time, results []*statementResult, err error) {
+	if s.history == nil {
+		return
+	}
+	e := &historyEntry{
+		Time:       start,
+		Connection: s.connectionName(),
+		Query:      query,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if s.curDBCfg != nil {
+		e.Database = s.curDBCfg.DBName
+	}
+	if s.curDBName != "" {
+		e.Database = s.curDBName
+	}
+	for _, res := range results {
+		if res.Result != nil {
+			e.Rows += int64(len(res.Result.Rows))
+			e.More = e.More || res.More
+		} else {
+			e.Rows += res.RowsAffected
+		}
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if len(results) > 0 {
+		if rs, ok := s.resultSets[results[len(results)-1].ResultSetID]; ok {
+			rs.history = e
+			return
+		}
+	}
+	s.addHistory(e)
+}
+
+func (s *Server) addHistory(e *historyEntry) {
+	if err := s.history.add(e); err != nil {
+		log.Println("record query history", err)
+	}
+}
+
+// connectionName is the name of the current connection in the history: its
+// alias, or its driver when it has none.
+func (s *Server) connectionName() string {
+	if s.curDBCfg == nil {
+		return ""
+	}
+	if s.curDBCfg.Alias != "" {
+		return s.curDBCfg.Alias
+	}
+	return string(s.curDBCfg.Driver)
+}
+
+func (s *Server) showHistory(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	limit, err := historyLimitArgument(params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.history.entries(nil)
+	if err != nil {
+		return nil, err
+	}
+	return formatHistory(entries, limit)
+}
+
+func (s *Server) searchHistory(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if len(params.Arguments) == 0 {
+		return nil, fmt.Errorf("required arguments were not provided: <Text>")
+	}
+	text, ok := params.Arguments[0].(string)
+	if !ok || text == "" {
+		return nil, fmt.Errorf("specify the text to search as a string")
+	}
+	limit, err := historyLimitArgument(params.Arguments[1:])
+	if err != nil {
+		return nil, err
+	}
+	text = strings.ToLower(text)
+	entries, err := s.history.entries(func(e *historyEntry) bool {
+		return strings.Contains(strings.ToLower(e.Query), text)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return formatHistory(entries, limit)
+}
+
+// rerunHistory runs the statement of a history entry again on the current
+// connection, taking the options of executeQuery. A statement which ran on
+// another connection is only run with the anyConnection option.
+func (s *Server) rerunHistory(ctx context.Context, conn *jsonrpc2.Conn, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if s.dbConn == nil {
+		return nil, errors.New("database connection is not open")
+	}
+	if len(params.Arguments) == 0 {
+		return nil, fmt.Errorf("required arguments were not provided: <History ID>")
+	}
+	id, err := historyIDArgument(params.Arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.history.entry(id)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := parseExecuteQueryOptions(params.Arguments[1:])
+	if err != nil {
+		return nil, err
+	}
+	if current := s.connectionName(); e.Connection != current && !opts.AnyConnection {
+		return nil, fmt.Errorf("history entry %d ran on connection %q, not %q, run again with the \"anyConnection\" option to execute it", id, e.Connection, current)
+	}
+	return s.runStatements(ctx, conn, "", []string{e.Query}, opts)
+}
+
+func historyIDArgument(arg interface{}) (int, error) {
+	switch v := arg.(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid history id %q", v)
+		}
+		return id, nil
+	}
+	return 0, fmt.Errorf("specify the history id as a number")
+}
+
+// historyLimitArgument returns the optional number of entries to list.
+func historyLimitArgument(args []interface{}) (int, error) {
+	if len(args) == 0 {
+		return defaultHistoryLimit, nil
+	}
+	limit, err := historyIDArgument(args[0])
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("specify the number of entries to list as a positive number")
+	}
+	return limit, nil
+}
+
+// formatHistory renders the last limit entries, the most recent last.
+func formatHistory(entries []*historyEntry, limit int) (string, error) {
+	if len(entries) == 0 {
+		return "No history", nil
+	}
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	columns := []string{"id", "time", "connection", "database", "duration", "rows", "error", "query"}
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		rows[i] = []string{
+			strconv.Itoa(e.ID),
+			e.Time.Local().Format("2006-01-02 15:04:05"),
+			e.Connection,
+			e.Database,
+			(time.Duration(e.DurationMs) * time.Millisecond).String(),
+			historyRows(e),
+			historyQueryText(e.Error),
+			historyQueryText(e.Query),
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := renderTable(buf, columns, rows); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// historyRows is the number of rows of an entry, with a "+" when rows
+// were left unread.
+func historyRows(e *historyEntry) string {
+	rows := strconv.FormatInt(e.Rows, 10)
+	if e.More {
+		rows += "+"
+	}
+	return rows
+}
+
+// maxHistoryQueryText is the number of characters of a statement shown in
+// history listings.
+const maxHistoryQueryText = 80
+
+// historyQueryText puts s on a single line short enough for a listing.
+func historyQueryText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxHistoryQueryText {
+		return string(r[:maxHistoryQueryText-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sqls-server/sqls/internal/config"
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+func Test_queryHistory(t *testing.T) {
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	tx.addWorkspaceConfig(t, &config.Config{
+		Connections: []*database.DBConfig{
+			{Alias: "world", Driver: "mock", DBName: "world"},
+		},
+	})
+	tx.textDocumentDidOpen(t, testFileURI, "UPDATE city SET Name = 'Kabul' WHERE ID = 1;\nDELETE FROM country WHERE Code = 'AFG';")
+
+	call := func(command string, args ...interface{}) string {
+		t.Helper()
+		var got string
+		params := lsp.ExecuteCommandParams{Command: command, Arguments: args}
+		if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, &got); err != nil {
+			t.Fatalf("conn.Call workspace/executeCommand %s: %v", command, err)
+		}
+		return got
+	}
+	call(CommandExecuteQuery, testFileURI)
+
+	entries, err := tx.server.history.entries(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(entries))
+	}
+	e := entries[1]
+	if e.ID != 2 || e.Connection != "world" || e.Database != "world" || e.Rows != 22 || e.Error != "" {
+		t.Errorf("unexpected history entry %+v", e)
+	}
+	if e.Query != "DELETE FROM country WHERE Code = 'AFG';" {
+		t.Errorf("unexpected history query %q", e.Query)
+	}
+
+	if got := call(CommandShowHistory); !strings.Contains(got, "UPDATE city") || !strings.Contains(got, "DELETE FROM country") {
+		t.Errorf("unexpected history:\n%s", got)
+	}
+	got := call(CommandSearchHistory, "delete from")
+	if !strings.Contains(got, "DELETE FROM country") || strings.Contains(got, "UPDATE city") {
+		t.Errorf("unexpected search result:\n%s", got)
+	}
+	if got := call(CommandSearchHistory, "nothing"); got != "No history" {
+		t.Errorf("unexpected search result:\n%s", got)
+	}
+
+	if got := call(CommandRerunHistory, 1); !strings.HasPrefix(got, "Query OK") {
+		t.Errorf("unexpected rerun result %q", got)
+	}
+	entries, err = tx.server.history.entries(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[2].Query != entries[0].Query {
+		t.Errorf("expected the rerun statement to be recorded, got %+v", entries)
+	}
+
+	// Statements of another connection are only run again on request.
+	if err := tx.server.history.add(&historyEntry{Connection: "staging", Query: "UPDATE city SET Name = 'Kabul' WHERE ID = 1"}); err != nil {
+		t.Fatal(err)
+	}
+	params := lsp.ExecuteCommandParams{Command: CommandRerunHistory, Arguments: []interface{}{4}}
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, nil); err == nil || !strings.Contains(err.Error(), "staging") {
+		t.Errorf("expected error for a statement of another connection, got %v", err)
+	}
+	if got := call(CommandRerunHistory, 4, map[string]interface{}{"anyConnection": true}); !strings.HasPrefix(got, "Query OK") {
+		t.Errorf("unexpected rerun result %q", got)
+	}
+	// Running on another connection does not confirm destructive statements.
+	if err := tx.server.history.add(&historyEntry{Connection: "staging", Query: "DELETE FROM city"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = tx.server.history.entries(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleteID := entries[len(entries)-1].ID
+	params = lsp.ExecuteCommandParams{Command: CommandRerunHistory, Arguments: []interface{}{deleteID, map[string]interface{}{"anyConnection": true}}}
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, nil); err == nil || !strings.Contains(err.Error(), "confirm") {
+		t.Errorf("expected error for an unconfirmed DELETE, got %v", err)
+	}
+	if got := call(CommandRerunHistory, deleteID, map[string]interface{}{"anyConnection": true, "confirm": true}); !strings.HasPrefix(got, "Query OK") {
+		t.Errorf("unexpected rerun result %q", got)
+	}
+
+	// Statements which cannot be bound are recorded with their error.
+	tx.textDocumentDidOpen(t, testFileURI, "UPDATE city SET Name = ? WHERE ID = 1")
+	params = lsp.ExecuteCommandParams{Command: CommandExecuteQuery, Arguments: []interface{}{testFileURI}}
+	if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, nil); err == nil {
+		t.Fatal("expected error for a missing bind parameter value")
+	}
+	entries, err = tx.server.history.entries(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := entries[len(entries)-1]; last.Query != "UPDATE city SET Name = ? WHERE ID = 1" || last.Error == "" {
+		t.Errorf("expected the unbound statement to be recorded with its error, got %+v", last)
+	}
+}
+
+func Test_queryHistoryPagedRows(t *testing.T) {
+	registerProcedureTestDriver()
+	tx := newTestContext()
+	tx.setup(t)
+	defer tx.tearDown()
+
+	tx.addWorkspaceConfig(t, &config.Config{
+		Connections: []*database.DBConfig{
+			{Driver: procedureTestDriverName},
+		},
+	})
+	tx.textDocumentDidOpen(t, testFileURI, "CALL city_stats()")
+
+	call := func(command string, args ...interface{}) {
+		t.Helper()
+		params := lsp.ExecuteCommandParams{Command: command, Arguments: args}
+		if err := tx.conn.Call(tx.ctx, "workspace/executeCommand", params, nil); err != nil {
+			t.Fatalf("conn.Call workspace/executeCommand %s: %v", command, err)
+		}
+	}
+	lastEntry := func() *historyEntry {
+		t.Helper()
+		entries, err := tx.server.history.entries(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return entries[len(entries)-1]
+	}
+
+	// The query is recorded once all of its pages are read.
+	call(CommandExecuteQuery, testFileURI, map[string]interface{}{"limit": 1})
+	if e := lastEntry(); e != nil {
+		t.Fatalf("expected the open result set not to be recorded yet, got %+v", e)
+	}
+	call(CommandNextPage, "1", map[string]interface{}{"limit": 1})
+	call(CommandNextPage, "1", map[string]interface{}{"limit": 1})
+	if e := lastEntry(); e == nil || e.Rows != 3 || e.More {
+		t.Errorf("expected 3 rows recorded, got %+v", e)
+	}
+
+	// A result set closed early is recorded with the rows read so far.
+	call(CommandExecuteQuery, testFileURI, map[string]interface{}{"limit": 1})
+	call(CommandCloseResultSet, "2")
+	if e := lastEntry(); e == nil || e.Rows != 1 || !e.More {
+		t.Errorf("expected 1 row and more recorded, got %+v", e)
+	}
+	if got := historyRows(lastEntry()); got != "1+" {
+		t.Errorf("historyRows() = %q, want %q", got, "1+")
+	}
+}
+
+func Test_historyStoreSkipsBrokenLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	text := "{\"query\":\"SELECT 1\"}\nnot json\n{\"query\":\"SELECT 3\"}\n"
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h := newHistoryStore(path)
+	e, err := h.entry(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Query != "SELECT 3" {
+		t.Errorf("unexpected query %q", e.Query)
+	}
+	if _, err := h.entry(2); err == nil {
+		t.Error("expected an error for a broken line")
+	}
+}
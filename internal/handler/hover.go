@@ -9,6 +9,7 @@ import (
 	"github.com/sourcegraph/jsonrpc2"
 	"github.com/sqls-server/sqls/ast"
 	"github.com/sqls-server/sqls/ast/astutil"
+	"github.com/sqls-server/sqls/dialect"
 	"github.com/sqls-server/sqls/internal/database"
 	"github.com/sqls-server/sqls/internal/lsp"
 	"github.com/sqls-server/sqls/parser"
@@ -33,7 +34,7 @@ func (s *Server) handleTextDocumentHover(ctx context.Context, conn *jsonrpc2.Con
 		return nil, fmt.Errorf("document not found: %s", params.TextDocument.URI)
 	}
 
-	res, err := hover(f.Text, params, s.worker.Cache())
+	res, err := hover(f.Text, s.driver(), params, s.worker.Cache())
 	if err != nil {
 		if errors.Is(err, ErrNoHover) {
 			return nil, nil
@@ -43,7 +44,7 @@ func (s *Server) handleTextDocumentHover(ctx context.Context, conn *jsonrpc2.Con
 	return res, nil
 }
 
-func hover(text string, params lsp.HoverParams, dbCache *database.DBCache) (*lsp.Hover, error) {
+func hover(text string, driver dialect.DatabaseDriver, params lsp.HoverParams, dbCache *database.DBCache) (*lsp.Hover, error) {
 	if dbCache == nil {
 		return nil, nil
 	}
@@ -52,7 +53,7 @@ func hover(text string, params lsp.HoverParams, dbCache *database.DBCache) (*lsp
 		Line: params.Position.Line,
 		Col:  params.Position.Character + 1,
 	}
-	parsed, err := parser.Parse(text)
+	parsed, err := parser.ParseFor(text, driver)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +104,10 @@ func hover(text string, params lsp.HoverParams, dbCache *database.DBCache) (*lsp
 		// The cursor is on the dot with the member identifier
 		// example "world[.]city"
 		hoverContent = hoverContentFromChildIdent(ctx, memIdent.ChildTok.NoQuoteString(), dbCache, hoverEnv)
+	} else if cte, ok := hoverEnv.declaredCTE(ident); ok {
+		// The cursor is on the name of a CTE where it is declared
+		// example "WITH [t] AS (SELECT ...)"
+		hoverContent = cteHoverInfo(cte, dbCache)
 	} else if ident != nil && memIdent == nil {
 		// The cursor is on the identifier
 		// example "c[i]ty"
@@ -137,15 +142,50 @@ type hoverEnvironment struct {
 	aliases    []ast.Node
 	tables     []*parseutil.TableInfo
 	subQueries []*parseutil.SubQueryInfo
+	ctes       []*parseutil.CTEInfo
 }
 
-func (e *hoverEnvironment) getTableRealName(aliasName string) (string, bool) {
+// getTable finds the referenced table by its alias, or by its name when
+// it is not aliased.
+func (e *hoverEnvironment) getTable(name string) (*parseutil.TableInfo, bool) {
 	for _, table := range e.tables {
-		if table.Alias == aliasName {
-			return table.Name, true
+		if table.Alias == name {
+			return table, true
 		}
 	}
-	return "", false
+	for _, table := range e.tables {
+		if table.Alias == "" && table.Name == name {
+			return table, true
+		}
+	}
+	return nil, false
+}
+
+// getCTE finds a common table expression by its name, or by the alias of
+// the table reading it.
+func (e *hoverEnvironment) getCTE(name string) (*parseutil.CTEInfo, bool) {
+	if table, ok := e.getTable(name); ok {
+		return e.tableCTE(table)
+	}
+	return parseutil.FindCTE(e.ctes, name)
+}
+
+// tableCTE finds the common table expression a table reference reads.
+func (e *hoverEnvironment) tableCTE(table *parseutil.TableInfo) (*parseutil.CTEInfo, bool) {
+	if table.DatabaseSchema != "" {
+		return nil, false
+	}
+	return parseutil.FindCTE(e.ctes, table.Name)
+}
+
+// declaredCTE finds the common table expression ident declares.
+func (e *hoverEnvironment) declaredCTE(ident *ast.Identifier) (*parseutil.CTEInfo, bool) {
+	for _, cte := range e.ctes {
+		if ident != nil && cte.Ident == ident {
+			return cte, true
+		}
+	}
+	return nil, false
 }
 
 func (e *hoverEnvironment) getColumnRealName(aliasedName string) (string, bool) {
@@ -196,10 +236,15 @@ func collectEnvironment(parsed ast.TokenList, pos token.Pos) (*hoverEnvironment,
 	if err != nil {
 		return nil, err
 	}
+	ctes, err := parseutil.ExtractCTEs(parsed, pos)
+	if err != nil {
+		return nil, err
+	}
 	environment := &hoverEnvironment{
 		aliases:    aliases,
 		tables:     definedTables,
 		subQueries: subQueries,
+		ctes:       ctes,
 	}
 	return environment, nil
 }
@@ -228,7 +273,13 @@ func hoverContentFromIdent(ctx *hoverContext, identName string, dbCache *databas
 		}
 		hoverContents := []*lsp.MarkupContent{}
 		for _, table := range hoverEnv.tables {
-			colDesc, ok := dbCache.Column(table.Name, columnName)
+			if cte, ok := hoverEnv.tableCTE(table); ok {
+				if colDesc, ok := cteColumnDesc(cte, identName, dbCache); ok {
+					hoverContents = append(hoverContents, columnHoverInfo(cte.Name, identName, colDesc))
+				}
+				continue
+			}
+			colDesc, ok := dbCache.ColumnBySchema(table.DatabaseSchema, table.Name, columnName)
 			if ok {
 				hoverContents = append(
 					hoverContents,
@@ -244,15 +295,18 @@ func hoverContentFromIdent(ctx *hoverContext, identName string, dbCache *databas
 		}
 	}
 	if hoverTypeIs(ctx.types, hoverTypeTable) {
+		if cte, ok := hoverEnv.getCTE(identName); ok {
+			return cteHoverInfo(cte, dbCache)
+		}
 		// translate table alias
-		tableName := identName
+		tableName, schemaName := identName, ""
 		for _, table := range hoverEnv.tables {
 			if table.Alias == tableName {
-				tableName = table.Name
+				tableName, schemaName = table.Name, table.DatabaseSchema
 			}
 		}
 		// find table
-		cols, ok := dbCache.ColumnDescs(tableName)
+		cols, ok := dbCache.ColumnDatabase(schemaName, tableName)
 		if ok {
 			return tableHoverInfo(tableName, cols)
 		}
@@ -274,12 +328,14 @@ func hoverContentFromParentIdent(ctx *hoverContext, identName string, dbCache *d
 		return nil
 	case parentTypeSchema:
 	case parentTypeTable:
-		tableName := identName
-		realName, ok := hoverEnv.getTableRealName(tableName)
-		if ok {
-			tableName = realName
+		if cte, ok := hoverEnv.getCTE(identName); ok && ctx.parent.Schema == "" {
+			return cteHoverInfo(cte, dbCache)
 		}
-		columns, ok := dbCache.ColumnDescs(tableName)
+		tableName, schemaName := identName, ctx.parent.Schema
+		if table, ok := hoverEnv.getTable(tableName); ok && schemaName == "" {
+			tableName, schemaName = table.Name, table.DatabaseSchema
+		}
+		columns, ok := dbCache.ColumnDatabase(schemaName, tableName)
 		if ok {
 			return tableHoverInfo(tableName, columns)
 		}
@@ -299,17 +355,22 @@ func hoverContentFromChildIdent(ctx *hoverContext, identName string, dbCache *da
 	case parentTypeNone:
 		return nil
 	case parentTypeSchema:
-		columns, ok := dbCache.ColumnDescs(identName)
+		columns, ok := dbCache.ColumnDatabase(ctx.parent.Name, identName)
 		if ok {
 			return tableHoverInfo(identName, columns)
 		}
 	case parentTypeTable:
-		tableName := ctx.parent.Name
-		realName, ok := hoverEnv.getTableRealName(tableName)
-		if ok {
-			tableName = realName
+		if cte, ok := hoverEnv.getCTE(ctx.parent.Name); ok && ctx.parent.Schema == "" {
+			if colDesc, ok := cteColumnDesc(cte, identName, dbCache); ok {
+				return columnHoverInfo(cte.Name, identName, colDesc)
+			}
+			return nil
+		}
+		tableName, schemaName := ctx.parent.Name, ctx.parent.Schema
+		if table, ok := hoverEnv.getTable(tableName); ok && schemaName == "" {
+			tableName, schemaName = table.Name, table.DatabaseSchema
 		}
-		if colDesc, ok := dbCache.Column(tableName, identName); ok {
+		if colDesc, ok := dbCache.ColumnBySchema(schemaName, tableName, identName); ok {
 			return columnHoverInfo(tableName, identName, colDesc)
 		}
 		return nil
@@ -351,6 +412,22 @@ func subqueryColumnHoverInfo(identName string, subQuery *parseutil.SubQueryInfo,
 	}
 }
 
+func cteHoverInfo(cte *parseutil.CTEInfo, dbCache *database.DBCache) *lsp.MarkupContent {
+	return &lsp.MarkupContent{
+		Kind:  lsp.Markdown,
+		Value: database.CTEDoc(cte, dbCache),
+	}
+}
+
+func cteColumnDesc(cte *parseutil.CTEInfo, name string, dbCache *database.DBCache) (*database.ColumnDesc, bool) {
+	for _, col := range database.CTEColumnDescs(cte, dbCache) {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return nil, false
+}
+
 func hoverTypeIs(hoverTypes []hoverType, expect hoverType) bool {
 	for _, t := range hoverTypes {
 		if t == expect {
@@ -389,6 +466,8 @@ const (
 type hoverParent struct {
 	Type parentType
 	Name string
+	// Schema qualifies a table parent, as in "schema.table.column".
+	Schema string
 }
 
 var noneParent = &hoverParent{Type: parentTypeNone}
@@ -419,8 +498,9 @@ func getHoverTypes(nw *parseutil.NodeWalker, hoverEnv *hoverEnvironment) *hoverC
 			}
 			name := mi.Parent.String()
 			p = &hoverParent{
-				Type: parentTypeTable,
-				Name: name,
+				Type:   parentTypeTable,
+				Name:   name,
+				Schema: mi.QualifierName(),
 			}
 			if hoverEnv.isSubQuery(name) {
 				p = &hoverParent{
@@ -454,8 +534,9 @@ func getHoverTypes(nw *parseutil.NodeWalker, hoverEnv *hoverEnvironment) *hoverC
 			}
 			name := mi.Parent.String()
 			p = &hoverParent{
-				Type: parentTypeTable,
-				Name: name,
+				Type:   parentTypeTable,
+				Name:   name,
+				Schema: mi.QualifierName(),
 			}
 			if hoverEnv.isSubQuery(name) {
 				p = &hoverParent{
@@ -512,8 +593,9 @@ func getHoverTypes(nw *parseutil.NodeWalker, hoverEnv *hoverEnvironment) *hoverC
 				hoverTypeFunction,
 			}
 			p = &hoverParent{
-				Type: parentTypeTable,
-				Name: mi.Parent.String(),
+				Type:   parentTypeTable,
+				Name:   mi.Parent.String(),
+				Schema: mi.QualifierName(),
 			}
 		} else {
 			t = []hoverType{
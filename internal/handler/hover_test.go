@@ -114,6 +114,27 @@ var hoverTestCases = []struct {
 		line:   0,
 		col:    25,
 	},
+	{
+		name:   "select schema qualified member ident child",
+		input:  "SELECT world.city.ID FROM world.city",
+		output: "`city`.`ID` column\n\n`int(11)` PRI auto_increment\n",
+		line:   0,
+		col:    19,
+	},
+	{
+		name:   "select schema qualified member ident parent",
+		input:  "SELECT world.city.ID FROM world.city",
+		output: "# `city` table\n\n\n| Name&nbsp;&nbsp; | Type&nbsp;&nbsp; | Primary&nbsp;key&nbsp;&nbsp; | Default&nbsp;&nbsp; | Extra&nbsp;&nbsp; |\n| :--------------- | :--------------- | :---------------------- | :------------------ | :---------------- |\n| `ID` | `int(11)` | `PRI` | `<null>` | auto_increment |\n| `Name` | `char(35)` | `` | `-` |  |\n| `CountryCode` | `char(3)` | `MUL` | `-` |  |\n| `District` | `char(20)` | `` | `-` |  |\n| `Population` | `int(11)` | `` | `-` |  |\n",
+		line:   0,
+		col:    15,
+	},
+	{
+		name:   "select schema qualified member ident unknown schema",
+		input:  "SELECT sakila.city.ID FROM sakila.city",
+		output: "",
+		line:   0,
+		col:    20,
+	},
 	{
 		name:   "select aliased member ident parent",
 		input:  "SELECT ci.ID, ci.Name FROM city AS ci",
@@ -163,6 +184,20 @@ var hoverTestCases = []struct {
 		line:   0,
 		col:    11,
 	},
+	{
+		name:   "select subquery derived column",
+		input:  "SELECT s.n, s.total FROM (SELECT count(*) AS n, sum(Population) * 2 AS total FROM city) AS s",
+		output: "total subquery column\n\n- total: `int(11)`\n",
+		line:   0,
+		col:    16,
+	},
+	{
+		name:   "select subquery with derived columns ident parent",
+		input:  "SELECT s.n FROM (SELECT CountryCode, count(*) AS n, CASE WHEN Population > 1000000 THEN 'big' ELSE 'small' END AS bucket FROM city GROUP BY CountryCode) AS s",
+		output: "s subquery\n\n- CountryCode(city.CountryCode): `char(3)` MUL\n- n: `bigint`\n- bucket: `text`\n",
+		line:   0,
+		col:    8,
+	},
 	{
 		name:   "select aliased select identifier",
 		input:  "SELECT ID AS city_id, Name AS city_name FROM city",
@@ -177,6 +212,41 @@ var hoverTestCases = []struct {
 		line:   0,
 		col:    19,
 	},
+	{
+		name:   "cte declaration",
+		input:  "WITH c(city_id, city_name) AS (SELECT ID, Name FROM city) SELECT city_id FROM c",
+		output: "# `c` common table expression\n\n- city_id: `int(11)` PRI auto_increment\n- city_name: `char(35)`\n",
+		line:   0,
+		col:    6,
+	},
+	{
+		name:   "cte table reference",
+		input:  "WITH RECURSIVE r AS (SELECT 1 AS n UNION ALL SELECT n + 1 FROM r) SELECT n FROM r",
+		output: "# `r` recursive common table expression\n\n- n: `integer`\n",
+		line:   0,
+		col:    81,
+	},
+	{
+		name:   "cte column",
+		input:  "WITH c AS (SELECT ID, Name AS city_name FROM city) SELECT c.city_name FROM c",
+		output: "`c`.`city_name` column\n\n`char(35)`\n",
+		line:   0,
+		col:    62,
+	},
+	{
+		name:   "cte column of a name in another case",
+		input:  "WITH C AS (SELECT ID, Name AS city_name FROM city) SELECT c.city_name FROM c",
+		output: "`C`.`city_name` column\n\n`char(35)`\n",
+		line:   0,
+		col:    61,
+	},
+	{
+		name:   "cte derived column",
+		input:  "WITH c AS (SELECT CountryCode, avg(Population) AS avg_pop FROM city GROUP BY CountryCode) SELECT c.avg_pop FROM c",
+		output: "`c`.`avg_pop` column\n\n`numeric`\n",
+		line:   0,
+		col:    102,
+	},
 	{
 		name: "multi line head",
 		input: `SELECT
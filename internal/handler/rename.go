@@ -8,6 +8,7 @@ import (
 	"github.com/sourcegraph/jsonrpc2"
 	"github.com/sqls-server/sqls/ast"
 	"github.com/sqls-server/sqls/ast/astutil"
+	"github.com/sqls-server/sqls/dialect"
 	"github.com/sqls-server/sqls/internal/lsp"
 	"github.com/sqls-server/sqls/parser"
 	"github.com/sqls-server/sqls/parser/parseutil"
@@ -29,15 +30,15 @@ func (s *Server) handleTextDocumentRename(ctx context.Context, conn *jsonrpc2.Co
 		return nil, fmt.Errorf("document not found: %s", params.TextDocument.URI)
 	}
 
-	res, err := rename(f.Text, params)
+	res, err := rename(f.Text, s.driver(), params)
 	if err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
-func rename(text string, params lsp.RenameParams) (*lsp.WorkspaceEdit, error) {
-	parsed, err := parser.Parse(text)
+func rename(text string, driver dialect.DatabaseDriver, params lsp.RenameParams) (*lsp.WorkspaceEdit, error) {
+	parsed, err := parser.ParseFor(text, driver)
 	if err != nil {
 		return nil, err
 	}
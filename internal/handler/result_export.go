@@ -0,0 +1,570 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatJSONL   = "jsonl"
+	ExportFormatParquet = "parquet"
+)
+
+// exportBatchSize is the number of rows read from the database before they
+// are handed to the export writer, which bounds the memory held at once.
+const exportBatchSize = 500
+
+// parquetRowGroupSize is the number of rows of a parquet row group. The
+// writer buffers a whole row group before writing it out.
+const parquetRowGroupSize = 10000
+
+// exportWriter writes the rows of a result set to a file one batch at a time.
+type exportWriter interface {
+	Write(rows [][]interface{}) error
+	Close() error
+}
+
+func newExportWriter(format string, w io.Writer, columns []*database.ResultColumn) (exportWriter, error) {
+	switch strings.ToLower(format) {
+	case ExportFormatCSV:
+		return newCSVExportWriter(w, columns)
+	case ExportFormatJSONL:
+		return &jsonlExportWriter{enc: json.NewEncoder(w), columns: columns}, nil
+	case ExportFormatParquet:
+		return newParquetExportWriter(w, columns), nil
+	}
+	return nil, fmt.Errorf("unsupported export format: %q", format)
+}
+
+// exportFormatFromPath guesses the export format from the file extension.
+func exportFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ExportFormatCSV
+	case ".jsonl", ".ndjson":
+		return ExportFormatJSONL
+	case ".parquet":
+		return ExportFormatParquet
+	}
+	return ""
+}
+
+// csvExportWriter writes a header row followed by the rows. A NULL is
+// written as an empty field.
+type csvExportWriter struct {
+	cw *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer, columns []*database.ResultColumn) (*csvExportWriter, error) {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvExportWriter{cw: cw}, nil
+}
+
+func (e *csvExportWriter) Write(rows [][]interface{}) error {
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, val := range row {
+			if val == nil {
+				continue
+			}
+			s, err := database.ValueString(val)
+			if err != nil {
+				return err
+			}
+			record[i] = s
+		}
+		if err := e.cw.Write(record); err != nil {
+			return err
+		}
+	}
+	e.cw.Flush()
+	return e.cw.Error()
+}
+
+func (e *csvExportWriter) Close() error {
+	e.cw.Flush()
+	return e.cw.Error()
+}
+
+// jsonlExportWriter writes one JSON object per row, keyed by column name in
+// the order of the columns.
+type jsonlExportWriter struct {
+	enc     *json.Encoder
+	columns []*database.ResultColumn
+}
+
+type jsonlRow struct {
+	columns []*database.ResultColumn
+	values  []interface{}
+}
+
+func (r *jsonlRow) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, col := range r.columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, err := json.Marshal(col.Name)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+func (e *jsonlExportWriter) Write(rows [][]interface{}) error {
+	for _, row := range rows {
+		if err := e.enc.Encode(&jsonlRow{columns: e.columns, values: row}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *jsonlExportWriter) Close() error {
+	return nil
+}
+
+// parquetKind is the physical representation a result column is exported
+// with, chosen from the Go type the driver scans it into.
+type parquetKind int
+
+const (
+	parquetString parquetKind = iota
+	parquetInt64
+	parquetDouble
+	parquetBoolean
+	parquetTimestamp
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func parquetKindOf(t reflect.Type) parquetKind {
+	if t == nil {
+		return parquetString
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return parquetInt64
+	case reflect.Float32, reflect.Float64:
+		return parquetDouble
+	case reflect.Bool:
+		return parquetBoolean
+	}
+	if t == timeType {
+		return parquetTimestamp
+	}
+	// sql.NullInt64 and friends hold the value in their first field.
+	if t.Kind() == reflect.Struct && t.NumField() == 2 && t.Field(1).Name == "Valid" {
+		return parquetKindOf(t.Field(0).Type)
+	}
+	return parquetString
+}
+
+func (k parquetKind) node() parquet.Node {
+	switch k {
+	case parquetInt64:
+		return parquet.Int(64)
+	case parquetDouble:
+		return parquet.Leaf(parquet.DoubleType)
+	case parquetBoolean:
+		return parquet.Leaf(parquet.BooleanType)
+	case parquetTimestamp:
+		return parquet.Timestamp(parquet.Microsecond)
+	}
+	return parquet.String()
+}
+
+// parquetExportWriter writes every column as an optional leaf. Parquet orders
+// the leaves of a group by name, so leaves maps each result column to its
+// leaf index.
+type parquetExportWriter struct {
+	w      *parquet.Writer
+	kinds  []parquetKind
+	leaves []int
+}
+
+func newParquetExportWriter(w io.Writer, columns []*database.ResultColumn) *parquetExportWriter {
+	group := parquet.Group{}
+	names := make([]string, len(columns))
+	kinds := make([]parquetKind, len(columns))
+	for i, col := range columns {
+		name := col.Name
+		// Column names must be unique within a group.
+		for n := 2; ; n++ {
+			if _, ok := group[name]; !ok {
+				break
+			}
+			name = col.Name + "_" + strconv.Itoa(n)
+		}
+		names[i] = name
+		kinds[i] = parquetKindOf(col.ScanType)
+		group[name] = parquet.Optional(kinds[i].node())
+	}
+
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	leaves := make([]int, len(columns))
+	for i, name := range names {
+		leaves[i] = sort.SearchStrings(sorted, name)
+	}
+
+	return &parquetExportWriter{
+		w:      parquet.NewWriter(w, parquet.NewSchema("result", group), parquet.MaxRowsPerRowGroup(parquetRowGroupSize)),
+		kinds:  kinds,
+		leaves: leaves,
+	}
+}
+
+func (e *parquetExportWriter) Write(rows [][]interface{}) error {
+	out := make([]parquet.Row, len(rows))
+	for i, row := range rows {
+		values := make(parquet.Row, len(row))
+		for j, val := range row {
+			v, err := e.value(j, val)
+			if err != nil {
+				return err
+			}
+			values[e.leaves[j]] = v
+		}
+		out[i] = values
+	}
+	_, err := e.w.WriteRows(out)
+	return err
+}
+
+func (e *parquetExportWriter) value(col int, val interface{}) (parquet.Value, error) {
+	leaf := e.leaves[col]
+	if val == nil {
+		return parquet.NullValue().Level(0, 0, leaf), nil
+	}
+	var v parquet.Value
+	switch e.kinds[col] {
+	case parquetInt64:
+		n, err := toInt64(val)
+		if err != nil {
+			return v, err
+		}
+		v = parquet.Int64Value(n)
+	case parquetDouble:
+		f, err := toFloat64(val)
+		if err != nil {
+			return v, err
+		}
+		v = parquet.DoubleValue(f)
+	case parquetBoolean:
+		b, err := toBool(val)
+		if err != nil {
+			return v, err
+		}
+		v = parquet.BooleanValue(b)
+	case parquetTimestamp:
+		t, err := toTime(val)
+		if err != nil {
+			return v, err
+		}
+		v = parquet.Int64Value(t.UnixMicro())
+	default:
+		s, err := database.ValueString(val)
+		if err != nil {
+			return v, err
+		}
+		v = parquet.ByteArrayValue([]byte(s))
+	}
+	return v.Level(0, 1, leaf), nil
+}
+
+func (e *parquetExportWriter) Close() error {
+	return e.w.Close()
+}
+
+func toInt64(val interface{}) (int64, error) {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), nil
+	case reflect.String:
+		return strconv.ParseInt(rv.String(), 10, 64)
+	}
+	return 0, fmt.Errorf("cannot export %T as an integer", val)
+}
+
+func toFloat64(val interface{}) (float64, error) {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.String:
+		return strconv.ParseFloat(rv.String(), 64)
+	}
+	return 0, fmt.Errorf("cannot export %T as a float", val)
+}
+
+func toBool(val interface{}) (bool, error) {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() != 0, nil
+	case reflect.String:
+		return strconv.ParseBool(rv.String())
+	}
+	return false, fmt.Errorf("cannot export %T as a boolean", val)
+}
+
+// timeLayouts are the layouts of the date and time values drivers return as
+// text, as MySQL does without parseTime.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+func toTime(val interface{}) (time.Time, error) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("cannot export %q as a timestamp", v)
+	}
+	return time.Time{}, fmt.Errorf("cannot export %T as a timestamp", val)
+}
+
+type exportQueryOptions struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	// Params holds the values of bind parameters, as for executeQuery.
+	Params interface{} `json:"params"`
+
+	// keep refuses to replace a file at Path, which was not chosen by the
+	// user.
+	keep bool
+}
+
+func (s *Server) executeQueryToFile(ctx context.Context, conn *jsonrpc2.Conn, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if s.dbConn == nil {
+		return nil, errors.New("database connection is not open")
+	}
+	if len(params.Arguments) == 0 {
+		return nil, fmt.Errorf("required arguments were not provided: <File URI>")
+	}
+	uri, ok := params.Arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("specify the file uri as a string")
+	}
+	f, ok := s.files[uri]
+	if !ok {
+		return nil, fmt.Errorf("document not found, %q", uri)
+	}
+	opts := &exportQueryOptions{}
+	if len(params.Arguments) > 1 {
+		switch v := params.Arguments[1].(type) {
+		case string:
+			opts.Path = v
+		case map[string]interface{}:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(b, opts); err != nil {
+				return nil, fmt.Errorf("invalid executeQueryToFile options, %w", err)
+			}
+		}
+	}
+	if opts.Path == "" {
+		opts.Path = defaultExportPath(uri)
+		opts.keep = true
+	}
+	if opts.Path == "" {
+		return nil, fmt.Errorf("specify the target path")
+	}
+	if opts.Format == "" {
+		opts.Format = exportFormatFromPath(opts.Path)
+	}
+	if opts.Format == "" {
+		return nil, fmt.Errorf("cannot tell the export format of %q, specify csv, jsonl or parquet", opts.Path)
+	}
+
+	text, err := s.scriptText(uri, f.Text, params.Range)
+	if err != nil {
+		return nil, err
+	}
+	stmts, err := getStatements(text, s.driver())
+	if err != nil {
+		return nil, err
+	}
+	var queries []string
+	for _, stmt := range stmts {
+		if query := strings.TrimSpace(stmt.Query); query != "" {
+			queries = append(queries, query)
+		}
+	}
+	if len(queries) != 1 {
+		return nil, fmt.Errorf("specify a single query to export, found %d statements", len(queries))
+	}
+	if _, isQuery := database.QueryExecType(queries[0], ""); !isQuery {
+		return nil, fmt.Errorf("only a query returning rows can be exported")
+	}
+	if err := s.checkStatements(queries, false); err != nil {
+		return nil, err
+	}
+
+	bound, err := s.bindQuery(ctx, conn, uri, queries[0], paramValues(opts.Params))
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	count, err := s.exportQuery(ctx, bound, opts)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("%d rows written to %s in %s", count, opts.Path, time.Since(start).Round(time.Millisecond)), nil
+}
+
+// defaultExportPath returns the path of a CSV file next to the document, or
+// "" when the document is not a file.
+func defaultExportPath(uri string) string {
+	path := uriToPath(uri)
+	if path == "" {
+		return ""
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".csv"
+}
+
+// exportQuery streams the rows of query to the target file, which is only
+// replaced once the export succeeds.
+func (s *Server) exportQuery(ctx context.Context, q *boundQuery, opts *exportQueryOptions) (count int, err error) {
+	ex, err := s.executor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := ex.Query(ctx, q.Query, q.Args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	reader, err := database.NewResultReader(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeExportFile(opts.Path, opts.keep, func(file io.Writer) error {
+		buf := bufio.NewWriter(file)
+		w, err := newExportWriter(opts.Format, buf, reader.Columns())
+		if err != nil {
+			return err
+		}
+		for {
+			batch, more, err := reader.Next(exportBatchSize)
+			if err != nil {
+				return err
+			}
+			if err := w.Write(batch.Rows); err != nil {
+				return err
+			}
+			count += len(batch.Rows)
+			if !more {
+				break
+			}
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		return buf.Flush()
+	})
+	return count, err
+}
+
+// writeExportFile writes path through a temporary file next to it, which
+// replaces path only when write succeeds, so that a failed export leaves
+// an existing file as it was. With keep, an existing file is never
+// replaced.
+func writeExportFile(path string, keep bool, write func(w io.Writer) error) (err error) {
+	if keep {
+		// Hold the name so that no other file takes it meanwhile.
+		f, oerr := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(oerr, fs.ErrExist) {
+			return fmt.Errorf("%s already exists, specify the target path to replace it", path)
+		} else if oerr != nil {
+			return oerr
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				_ = os.Remove(path)
+			}
+		}()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if err := tmp.Chmod(0o644); err != nil {
+		return err
+	}
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/parquet-go/parquet-go"
+	"github.com/sqls-server/sqls/internal/database"
+)
+
+var exportTestColumns = []*database.ResultColumn{
+	{Name: "ID", DatabaseType: "INT", ScanType: reflect.TypeOf(int64(0))},
+	{Name: "Name", DatabaseType: "VARCHAR", ScanType: reflect.TypeOf("")},
+}
+
+var exportTestRows = [][]interface{}{
+	{int64(1), "Kabul"},
+	{int64(2), nil},
+}
+
+func TestExportWriter(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: ExportFormatCSV,
+			want:   "ID,Name\n1,Kabul\n2,\n",
+		},
+		{
+			format: ExportFormatJSONL,
+			want:   "{\"ID\":1,\"Name\":\"Kabul\"}\n{\"ID\":2,\"Name\":null}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			w, err := newExportWriter(tt.format, buf, exportTestColumns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Write(exportTestRows[:1]); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Write(exportTestRows[1:]); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("unmatch result (- want, + got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := newExportWriter("xlsx", new(bytes.Buffer), exportTestColumns); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestExportWriterParquet(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := newExportWriter(ExportFormatParquet, buf, exportTestColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(exportTestRows); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	type row struct {
+		ID   *int64  `parquet:"ID,optional"`
+		Name *string `parquet:"Name,optional"`
+	}
+	got, err := parquet.Read[row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id1, id2, name := int64(1), int64(2), "Kabul"
+	want := []row{
+		{ID: &id1, Name: &name},
+		{ID: &id2},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unmatch result (- want, + got):\n%s", diff)
+	}
+}
+
+func TestExportWriterParquet_timestampText(t *testing.T) {
+	// MySQL returns DATETIME values as text unless parseTime is set.
+	columns := []*database.ResultColumn{
+		{Name: "created", DatabaseType: "DATETIME", ScanType: reflect.TypeOf(time.Time{})},
+	}
+	buf := new(bytes.Buffer)
+	w, err := newExportWriter(ExportFormatParquet, buf, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([][]interface{}{{"2024-05-01 12:30:00"}, {"2024-05-02"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	type row struct {
+		Created *time.Time `parquet:"created,optional,timestamp(microsecond)"`
+	}
+	got, err := parquet.Read[row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Created == nil || !got[i].Created.Equal(want[i]) {
+			t.Errorf("row %d = %v, want %v", i, got[i].Created, want[i])
+		}
+	}
+
+	if err := w.Write([][]interface{}{{"yesterday"}}); err == nil {
+		t.Error("expected error for a value which is not a timestamp")
+	}
+}
+
+func TestExportWriterParquet_rowGroups(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := newExportWriter(ExportFormatParquet, buf, exportTestColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make([][]interface{}, exportBatchSize)
+	for i := range rows {
+		rows[i] = []interface{}{int64(i), "Kabul"}
+	}
+	for n := 0; n <= parquetRowGroupSize; n += len(rows) {
+		if err := w.Write(rows); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(f.RowGroups()); got != 2 {
+		t.Errorf("got %d row groups, want 2", got)
+	}
+}
+
+func Test_defaultExportPath(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"file:///tmp/query.sql", "/tmp/query.csv"},
+		{"file:///tmp/query", "/tmp/query.csv"},
+		{"sqls://create-table/city.sql", ""},
+	}
+	for _, tt := range tests {
+		if got := defaultExportPath(tt.uri); got != tt.want {
+			t.Errorf("defaultExportPath(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func Test_exportFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/tmp/out.csv", ExportFormatCSV},
+		{"/tmp/out.JSONL", ExportFormatJSONL},
+		{"/tmp/out.parquet", ExportFormatParquet},
+		{"/tmp/out.txt", ""},
+	}
+	for _, tt := range tests {
+		if got := exportFormatFromPath(tt.path); got != tt.want {
+			t.Errorf("exportFormatFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func Test_writeExportFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	write := func(text string, err error) func(io.Writer) error {
+		return func(w io.Writer) error {
+			if _, werr := io.WriteString(w, text); werr != nil {
+				return werr
+			}
+			return err
+		}
+	}
+	content := func() string {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	if err := writeExportFile(path, true, write("new", nil)); err == nil {
+		t.Error("replaced a file which was not chosen")
+	}
+	if got := content(); got != "old" {
+		t.Errorf("content = %q after refusing to replace it, want %q", got, "old")
+	}
+
+	if err := writeExportFile(path, false, write("partial", errors.New("query failed"))); err == nil {
+		t.Error("no error for a failed write")
+	}
+	if got := content(); got != "old" {
+		t.Errorf("content = %q after a failed export, want %q", got, "old")
+	}
+
+	if err := writeExportFile(path, false, write("new", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := content(); got != "new" {
+		t.Errorf("content = %q, want %q", got, "new")
+	}
+
+	fresh := filepath.Join(dir, "fresh.csv")
+	if err := writeExportFile(fresh, true, write("partial", errors.New("query failed"))); err == nil {
+		t.Error("no error for a failed write")
+	}
+	if _, err := os.Stat(fresh); !os.IsNotExist(err) {
+		t.Errorf("a failed export left %s behind", fresh)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary files are left behind: %v", entries)
+	}
+}
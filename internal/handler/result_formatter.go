@@ -0,0 +1,359 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/tw"
+	"github.com/sqls-server/sqls/internal/database"
+)
+
+const (
+	ResultFormatTable    = "table"
+	ResultFormatVertical = "vertical"
+	ResultFormatJSON     = "json"
+	ResultFormatCSV      = "csv"
+	ResultFormatTSV      = "tsv"
+	ResultFormatMarkdown = "markdown"
+	ResultFormatHTML     = "html"
+)
+
+// statementResult is the outcome of one executed statement. Result is nil
+// for a statement which does not return rows. More reports that Result does
+// not hold all of the rows, and ResultSetID names the result set holding the
+// rest when they can be fetched.
+//
+// Duration is the wall-clock time the statement took and FirstRow the time
+// until its first row was read, which is zero when there were no rows.
+// LastInsertID is set when the driver reports one.
+type statementResult struct {
+	Query        string
+	Result       *database.Result
+	RowsAffected int64
+	LastInsertID *int64
+	More         bool
+	ResultSetID  string
+	Duration     time.Duration
+	FirstRow     time.Duration
+	// Messages are what the server sent besides the result, such as
+	// notices, PRINT output and warnings.
+	Messages []database.Message
+}
+
+// ResultFormatter renders the results of the statements of one execution.
+type ResultFormatter interface {
+	Format(w io.Writer, results []*statementResult) error
+}
+
+func newResultFormatter(format string) (ResultFormatter, error) {
+	switch strings.ToLower(format) {
+	case "", ResultFormatTable:
+		return &textFormatter{render: renderTable, null: nullString}, nil
+	case ResultFormatVertical:
+		return &textFormatter{render: renderVertical, null: nullString}, nil
+	case ResultFormatJSON:
+		return &jsonFormatter{}, nil
+	case ResultFormatCSV:
+		return &delimitedFormatter{comma: ','}, nil
+	case ResultFormatTSV:
+		return &delimitedFormatter{comma: '\t'}, nil
+	case ResultFormatMarkdown:
+		return &textFormatter{render: renderMarkdown, null: nullString}, nil
+	case ResultFormatHTML:
+		return &textFormatter{render: renderHTML, null: nullString}, nil
+	}
+	return nil, fmt.Errorf("unsupported result format: %q", format)
+}
+
+// nullString is how text formats show a NULL, so that it can be told apart
+// from an empty string.
+const nullString = "NULL"
+
+// textFormatter writes each result set with render, followed by a summary
+// line, and reports statements without a result set by their affected rows.
+// A NULL is written as null.
+type textFormatter struct {
+	render func(w io.Writer, columns []string, rows [][]string) error
+	null   string
+}
+
+func (f *textFormatter) Format(w io.Writer, results []*statementResult) error {
+	for _, res := range results {
+		if res.Result == nil {
+			fmt.Fprintf(w, "Query OK, %d row affected", res.RowsAffected)
+			if res.LastInsertID != nil {
+				fmt.Fprintf(w, ", last insert id %d", *res.LastInsertID)
+			}
+			fmt.Fprintf(w, " (%s)", formatSeconds(res.Duration))
+			fmt.Fprintln(w, "")
+			writeMessages(w, res.Messages)
+			fmt.Fprintln(w, "")
+			fmt.Fprintln(w, "")
+			continue
+		}
+		columns, rows, err := stringResult(res.Result, f.null)
+		if err != nil {
+			return err
+		}
+		if err := f.render(w, columns, rows); err != nil {
+			return err
+		}
+		switch {
+		case res.ResultSetID != "":
+			fmt.Fprintf(w, "%d rows shown, more available (result set %s)", len(rows), res.ResultSetID)
+		case res.More:
+			fmt.Fprintf(w, "%d rows shown, more available", len(rows))
+		default:
+			fmt.Fprintf(w, "%d rows in set", len(rows))
+		}
+		if res.FirstRow > 0 {
+			fmt.Fprintf(w, " (%s, first row %s)", formatSeconds(res.Duration), formatSeconds(res.FirstRow))
+		} else {
+			fmt.Fprintf(w, " (%s)", formatSeconds(res.Duration))
+		}
+		fmt.Fprintln(w, "")
+		writeMessages(w, res.Messages)
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "")
+	}
+	return nil
+}
+
+// delimitedFormatter writes only the header and rows of each result set,
+// so that the output stays valid CSV or TSV. Result sets are separated by
+// a blank line, statements without one are left out and a NULL is empty.
+type delimitedFormatter struct {
+	comma rune
+}
+
+func (f *delimitedFormatter) Format(w io.Writer, results []*statementResult) error {
+	render := renderDelimited(f.comma)
+	first := true
+	for _, res := range results {
+		if res.Result == nil {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(w, "")
+		}
+		first = false
+		columns, rows, err := stringResult(res.Result, "")
+		if err != nil {
+			return err
+		}
+		if err := render(w, columns, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeMessages(w io.Writer, messages []database.Message) {
+	for _, m := range messages {
+		fmt.Fprintln(w, m.String())
+	}
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f sec", d.Seconds())
+}
+
+func stringResult(res *database.Result, null string) ([]string, [][]string, error) {
+	columns := make([]string, len(res.Columns))
+	for i, col := range res.Columns {
+		columns[i] = col.Name
+	}
+	rows := make([][]string, len(res.Rows))
+	for i, row := range res.Rows {
+		rows[i] = make([]string, len(row))
+		for j, val := range row {
+			if val == nil {
+				rows[i][j] = null
+				continue
+			}
+			s, err := database.ValueString(val)
+			if err != nil {
+				return nil, nil, err
+			}
+			rows[i][j] = s
+		}
+	}
+	return columns, rows, nil
+}
+
+func renderTable(w io.Writer, columns []string, rows [][]string) error {
+	table := tablewriter.NewTable(w, tablewriter.WithHeaderConfig(tw.CellConfig{
+		Formatting: tw.CellFormatting{AutoFormat: tw.Off},
+	}))
+	// Convert []string to []any for Header
+	headers := make([]any, len(columns))
+	for i, v := range columns {
+		headers[i] = v
+	}
+	table.Header(headers...)
+	for _, stringRow := range rows {
+		// Convert []string to []any for Append
+		row := make([]any, len(stringRow))
+		for i, v := range stringRow {
+			row[i] = v
+		}
+		if err := table.Append(row...); err != nil {
+			return err
+		}
+	}
+	return table.Render()
+}
+
+func renderVertical(w io.Writer, columns []string, rows [][]string) error {
+	table := newVerticalTableWriter(w)
+	table.setHeaders(columns)
+	for _, row := range rows {
+		table.appendRow(row)
+	}
+	table.render()
+	return nil
+}
+
+func renderDelimited(comma rune) func(w io.Writer, columns []string, rows [][]string) error {
+	return func(w io.Writer, columns []string, rows [][]string) error {
+		cw := csv.NewWriter(w)
+		cw.Comma = comma
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+}
+
+var markdownEscaper = strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>")
+
+func renderMarkdown(w io.Writer, columns []string, rows [][]string) error {
+	writeRow := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for i, c := range cells {
+			escaped[i] = markdownEscaper.Replace(c)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+	}
+	writeRow(columns)
+	sep := make([]string, len(columns))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	writeRow(sep)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return nil
+}
+
+func renderHTML(w io.Writer, columns []string, rows [][]string) error {
+	fmt.Fprintln(w, "<table>")
+	fmt.Fprint(w, "<thead><tr>")
+	for _, c := range columns {
+		fmt.Fprintf(w, "<th>%s</th>", html.EscapeString(c))
+	}
+	fmt.Fprintln(w, "</tr></thead>")
+	fmt.Fprintln(w, "<tbody>")
+	for _, row := range rows {
+		fmt.Fprint(w, "<tr>")
+		for _, c := range row {
+			fmt.Fprintf(w, "<td>%s</td>", html.EscapeString(c))
+		}
+		fmt.Fprintln(w, "</tr>")
+	}
+	fmt.Fprintln(w, "</tbody>")
+	fmt.Fprintln(w, "</table>")
+	return nil
+}
+
+// jsonFormatter writes an array with one object per statement, holding the
+// column metadata and typed values of its result set.
+type jsonFormatter struct{}
+
+type jsonColumn struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Nullable  *bool  `json:"nullable,omitempty"`
+	Length    *int64 `json:"length,omitempty"`
+	Precision *int64 `json:"precision,omitempty"`
+	Scale     *int64 `json:"scale,omitempty"`
+}
+
+type jsonQueryResult struct {
+	Query       string             `json:"query"`
+	Columns     []*jsonColumn      `json:"columns"`
+	Rows        [][]interface{}    `json:"rows"`
+	RowCount    int                `json:"rowCount"`
+	More        bool               `json:"more,omitempty"`
+	ResultSetID string             `json:"resultSetId,omitempty"`
+	DurationMs  float64            `json:"durationMs"`
+	FirstRowMs  *float64           `json:"firstRowMs,omitempty"`
+	Messages    []database.Message `json:"messages,omitempty"`
+}
+
+type jsonExecResult struct {
+	Query        string             `json:"query"`
+	RowsAffected int64              `json:"rowsAffected"`
+	LastInsertID *int64             `json:"lastInsertId,omitempty"`
+	DurationMs   float64            `json:"durationMs"`
+	Messages     []database.Message `json:"messages,omitempty"`
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func (f *jsonFormatter) Format(w io.Writer, results []*statementResult) error {
+	out := make([]interface{}, len(results))
+	for i, res := range results {
+		if res.Result == nil {
+			out[i] = &jsonExecResult{
+				Query:        res.Query,
+				RowsAffected: res.RowsAffected,
+				LastInsertID: res.LastInsertID,
+				DurationMs:   milliseconds(res.Duration),
+				Messages:     res.Messages,
+			}
+			continue
+		}
+		columns := make([]*jsonColumn, len(res.Result.Columns))
+		for j, col := range res.Result.Columns {
+			columns[j] = &jsonColumn{
+				Name:      col.Name,
+				Type:      col.DatabaseType,
+				Nullable:  col.Nullable,
+				Length:    col.Length,
+				Precision: col.Precision,
+				Scale:     col.Scale,
+			}
+		}
+		qr := &jsonQueryResult{
+			Query:       res.Query,
+			Columns:     columns,
+			Rows:        res.Result.Rows,
+			RowCount:    len(res.Result.Rows),
+			More:        res.More,
+			ResultSetID: res.ResultSetID,
+			DurationMs:  milliseconds(res.Duration),
+			Messages:    res.Messages,
+		}
+		if res.FirstRow > 0 {
+			firstRow := milliseconds(res.FirstRow)
+			qr.FirstRowMs = &firstRow
+		}
+		out[i] = qr
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sqls-server/sqls/internal/database"
+)
+
+func TestResultFormatter(t *testing.T) {
+	lastInsertID := int64(4080)
+	results := []*statementResult{
+		{
+			Query: "SELECT ID, Name FROM city",
+			Result: &database.Result{
+				Columns: []*database.ResultColumn{
+					{Name: "ID", DatabaseType: "INT"},
+					{Name: "Name", DatabaseType: "VARCHAR"},
+				},
+				Rows: [][]interface{}{
+					{int64(1), "Kabul"},
+					{int64(2), "a|b"},
+				},
+			},
+			Duration: 12 * time.Millisecond,
+			FirstRow: 4 * time.Millisecond,
+		},
+		{
+			Query:        "INSERT INTO city (Name) VALUES ('a'), ('b')",
+			RowsAffected: 2,
+			LastInsertID: &lastInsertID,
+			Duration:     3 * time.Millisecond,
+			Messages: []database.Message{
+				{Level: "WARNING", Text: "Data truncated for column 'Name' at row 2"},
+			},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: ResultFormatCSV,
+			want: `ID,Name
+1,Kabul
+2,a|b
+`,
+		},
+		{
+			format: ResultFormatTSV,
+			want:   "ID\tName\n1\tKabul\n2\ta|b\n",
+		},
+		{
+			format: ResultFormatMarkdown,
+			want: `| ID | Name |
+| --- | --- |
+| 1 | Kabul |
+| 2 | a\|b |
+2 rows in set (0.012 sec, first row 0.004 sec)
+
+
+Query OK, 2 row affected, last insert id 4080 (0.003 sec)
+WARNING: Data truncated for column 'Name' at row 2
+
+
+`,
+		},
+		{
+			format: ResultFormatHTML,
+			want: `<table>
+<thead><tr><th>ID</th><th>Name</th></tr></thead>
+<tbody>
+<tr><td>1</td><td>Kabul</td></tr>
+<tr><td>2</td><td>a|b</td></tr>
+</tbody>
+</table>
+2 rows in set (0.012 sec, first row 0.004 sec)
+
+
+Query OK, 2 row affected, last insert id 4080 (0.003 sec)
+WARNING: Data truncated for column 'Name' at row 2
+
+
+`,
+		},
+		{
+			format: ResultFormatJSON,
+			want: `[
+  {
+    "query": "SELECT ID, Name FROM city",
+    "columns": [
+      {
+        "name": "ID",
+        "type": "INT"
+      },
+      {
+        "name": "Name",
+        "type": "VARCHAR"
+      }
+    ],
+    "rows": [
+      [
+        1,
+        "Kabul"
+      ],
+      [
+        2,
+        "a|b"
+      ]
+    ],
+    "rowCount": 2,
+    "durationMs": 12,
+    "firstRowMs": 4
+  },
+  {
+    "query": "INSERT INTO city (Name) VALUES ('a'), ('b')",
+    "rowsAffected": 2,
+    "lastInsertId": 4080,
+    "durationMs": 3,
+    "messages": [
+      {
+        "level": "WARNING",
+        "text": "Data truncated for column 'Name' at row 2"
+      }
+    ]
+  }
+]
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			formatter, err := newResultFormatter(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := new(bytes.Buffer)
+			if err := formatter.Format(buf, results); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("unmatch result (- want, + got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := newResultFormatter("xml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func Test_parseExecuteQueryOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		args []interface{}
+		want string
+	}{
+		{"none", nil, ""},
+		{"legacy vertical flag", []interface{}{"-show-vertical"}, ResultFormatVertical},
+		{"object", []interface{}{map[string]interface{}{"format": "markdown", "limit": float64(10)}}, ResultFormatMarkdown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseExecuteQueryOptions(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts.Format != tt.want {
+				t.Errorf("got format %q, want %q", opts.Format, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultFormatterNull(t *testing.T) {
+	nullable := true
+	results := []*statementResult{
+		{
+			Query: "SELECT Name FROM city",
+			Result: &database.Result{
+				Columns: []*database.ResultColumn{
+					{Name: "Name", DatabaseType: "VARCHAR", Nullable: &nullable},
+				},
+				Rows: [][]interface{}{
+					{nil},
+					{""},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: ResultFormatVertical,
+			want: `***************************[ 1. row ]***************************
+Name | NULL
+***************************[ 2. row ]***************************
+Name | 
+2 rows in set (0.000 sec)
+
+
+`,
+		},
+		{
+			format: ResultFormatCSV,
+			want: `Name
+
+
+`,
+		},
+		{
+			format: ResultFormatJSON,
+			want: `[
+  {
+    "query": "SELECT Name FROM city",
+    "columns": [
+      {
+        "name": "Name",
+        "type": "VARCHAR",
+        "nullable": true
+      }
+    ],
+    "rows": [
+      [
+        null
+      ],
+      [
+        ""
+      ]
+    ],
+    "rowCount": 2,
+    "durationMs": 0
+  }
+]
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			formatter, err := newResultFormatter(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := new(bytes.Buffer)
+			if err := formatter.Format(buf, results); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("unmatch result (- want, + got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResultFormatterCSV_resultSets(t *testing.T) {
+	result := func(name string, rows ...string) *statementResult {
+		res := &database.Result{Columns: []*database.ResultColumn{{Name: name}}}
+		for _, r := range rows {
+			res.Rows = append(res.Rows, []interface{}{r})
+		}
+		return &statementResult{Result: res}
+	}
+	results := []*statementResult{
+		result("Name", "Kabul", "a,b"),
+		{Query: "DELETE FROM city", RowsAffected: 1},
+		result("Code", "AFG"),
+	}
+
+	formatter, err := newResultFormatter(ResultFormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := formatter.Format(buf, results); err != nil {
+		t.Fatal(err)
+	}
+	want := "Name\nKabul\n\"a,b\"\n\nCode\nAFG\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("unmatch result (- want, + got):\n%s", diff)
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+// maxOpenResultSets bounds the result sets kept open for paging, since each
+// one holds a connection of the pool.
+const maxOpenResultSets = 3
+
+// resultSet is a query result whose remaining rows can be fetched with the
+// nextPage command.
+type resultSet struct {
+	query  string
+	rows   *sql.Rows
+	reader *database.ResultReader
+	// history is the history entry of the query, recorded once the result
+	// set is closed.
+	history *historyEntry
+}
+
+func (s *Server) openResultSet(query string, rows *sql.Rows, reader *database.ResultReader) string {
+	for len(s.resultSetIDs) >= maxOpenResultSets {
+		s.closeResultSet(s.resultSetIDs[0])
+	}
+	s.resultSetSeq++
+	id := strconv.Itoa(s.resultSetSeq)
+	s.resultSets[id] = &resultSet{query: query, rows: rows, reader: reader}
+	s.resultSetIDs = append(s.resultSetIDs, id)
+	return id
+}
+
+func (s *Server) closeResultSet(id string) {
+	if rs, ok := s.resultSets[id]; ok {
+		_ = rs.rows.Close()
+		delete(s.resultSets, id)
+		if rs.history != nil {
+			s.addHistory(rs.history)
+		}
+	}
+	for i, v := range s.resultSetIDs {
+		if v == id {
+			s.resultSetIDs = append(s.resultSetIDs[:i], s.resultSetIDs[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *Server) closeResultSets() {
+	for len(s.resultSetIDs) > 0 {
+		s.closeResultSet(s.resultSetIDs[0])
+	}
+}
+
+func (s *Server) nextPage(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if len(params.Arguments) == 0 {
+		return nil, fmt.Errorf("required arguments were not provided: <Result Set ID>")
+	}
+	id, ok := params.Arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("specify the result set id as a string")
+	}
+	rs, ok := s.resultSets[id]
+	if !ok {
+		return nil, fmt.Errorf("result set not found, %q", id)
+	}
+
+	opts, err := parseExecuteQueryOptions(params.Arguments[1:])
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := newResultFormatter(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	page, more, err := rs.reader.Next(opts.maxRows(s.getConfig()))
+	if err != nil {
+		rs.fail(err)
+		s.closeResultSet(id)
+		return nil, err
+	}
+	if !more {
+		// Go on with the next result set of a batch, if there is one.
+		if more, err = rs.nextResultSet(); err != nil {
+			rs.fail(err)
+			s.closeResultSet(id)
+			return nil, err
+		}
+	}
+	if rs.history != nil {
+		rs.history.Rows += int64(len(page.Rows))
+		rs.history.More = more
+	}
+	res := &statementResult{Query: rs.query, Result: page, More: more, Duration: time.Since(start)}
+	if more {
+		res.ResultSetID = id
+	} else {
+		s.closeResultSet(id)
+		messages := &database.MessageCollector{}
+		s.readMessages(database.WithMessages(ctx, messages))
+		res.Messages = messages.Take()
+	}
+	return formatResults(formatter, []*statementResult{res})
+}
+
+// fail records the error which ended the reading of the result set.
+func (rs *resultSet) fail(err error) {
+	if rs.history != nil {
+		rs.history.Error = err.Error()
+	}
+}
+
+// nextResultSet moves to the next result set which has columns.
+func (rs *resultSet) nextResultSet() (bool, error) {
+	for {
+		ok, err := rs.reader.NextResultSet()
+		if err != nil || !ok {
+			return false, err
+		}
+		if len(rs.reader.Columns()) > 0 {
+			return true, nil
+		}
+	}
+}
+
+func (s *Server) closeResultSetCommand(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if len(params.Arguments) != 1 {
+		return nil, fmt.Errorf("required arguments were not provided: <Result Set ID>")
+	}
+	id, ok := params.Arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("specify the result set id as a string")
+	}
+	s.closeResultSet(id)
+	return nil, nil
+}
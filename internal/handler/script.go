@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+var (
+	setDirectivePattern     = regexp.MustCompile(`^\\set\s+(\w+)(?:\s+(.*))?$`)
+	setvarDirectivePattern  = regexp.MustCompile(`(?i)^:setvar\s+(\w+)(?:\s+(.*))?$`)
+	includeDirectivePattern = regexp.MustCompile(`(?i)^--\s*@include\s+(.+)$`)
+	scriptVariablePattern   = regexp.MustCompile(`\$\{(\w+)\}`)
+	delimiterLinePattern    = regexp.MustCompile(`(?i)^delimiter\s+(\S+)`)
+)
+
+// scriptExpander applies the script directives of executed files: "\set"
+// and ":setvar" set variables for the lines which follow, "-- @include"
+// inserts another file and "${name}" is replaced with the value of a
+// variable. A reference to no variable is left as it is, as it may as well
+// be part of a string or a function body.
+type scriptExpander struct {
+	// vars holds the variables set by directives.
+	vars map[string]string
+	// lookup gives the variables which are not set by directives.
+	lookup func(name string) (string, bool)
+	// including holds the files being included, to stop include cycles.
+	including map[string]bool
+	// delimiter is the statement delimiter set by the last DELIMITER line,
+	// which is left for the parser.
+	delimiter string
+}
+
+// scriptText returns the text of the file at uri to execute, the part in rng
+// when given, with its directives applied. Variables set before the range
+// are kept.
+func (s *Server) scriptText(uri, text string, rng *lsp.Range) (string, error) {
+	if rng == nil {
+		return s.scriptTextAfter(uri, "", text)
+	}
+	return s.scriptTextAfter(
+		uri,
+		extractRangeText(text, 0, 0, rng.Start.Line, rng.Start.Character),
+		extractRangeText(
+			text,
+			rng.Start.Line,
+			rng.Start.Character,
+			rng.End.Line,
+			rng.End.Character,
+		),
+	)
+}
+
+// scriptTextAfter returns text, a part of the file at uri, with its
+// directives applied. prefix is the part of the file before text, whose
+// variables are kept.
+func (s *Server) scriptTextAfter(uri, prefix, text string) (string, error) {
+	e := &scriptExpander{
+		vars:      map[string]string{},
+		lookup:    s.scriptVariable,
+		including: map[string]bool{},
+	}
+	path := uriToPath(uri)
+	if prefix != "" {
+		if _, err := e.expand(prefix, path, true); err != nil {
+			return "", err
+		}
+	}
+	delimiter := e.delimiter
+	expanded, err := e.expand(text, path, false)
+	if err != nil {
+		return "", err
+	}
+	// The statements after a DELIMITER line only split right with it.
+	if delimiter != "" && delimiter != ";" {
+		expanded = "DELIMITER " + delimiter + "\n" + expanded
+	}
+	return expanded, nil
+}
+
+// scriptVariable looks a variable up in the variables of the connection,
+// then of the configuration, then of the environment.
+func (s *Server) scriptVariable(name string) (string, bool) {
+	if s.curDBCfg != nil {
+		if v, ok := s.curDBCfg.Variables[name]; ok {
+			return v, true
+		}
+	}
+	if v, ok := s.getConfig().Variables[name]; ok {
+		return v, true
+	}
+	return os.LookupEnv(name)
+}
+
+// expand applies the directives of text, read from path. With defineOnly,
+// only the variables are set and nothing is written.
+func (e *scriptExpander) expand(text, path string, defineOnly bool) (string, error) {
+	var b strings.Builder
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		trimmed := strings.TrimSpace(line)
+		if m := setDirectivePattern.FindStringSubmatch(trimmed); m != nil {
+			e.set(m[1], m[2])
+			continue
+		}
+		if m := setvarDirectivePattern.FindStringSubmatch(trimmed); m != nil {
+			e.set(m[1], m[2])
+			continue
+		}
+		if m := includeDirectivePattern.FindStringSubmatch(trimmed); m != nil {
+			included, err := e.include(strings.TrimSpace(m[1]), path, defineOnly)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(included)
+			continue
+		}
+		if m := delimiterLinePattern.FindStringSubmatch(trimmed); m != nil {
+			e.delimiter = m[1]
+		}
+		if defineOnly {
+			continue
+		}
+		b.WriteString(e.substitute(line))
+	}
+	return b.String(), nil
+}
+
+func (e *scriptExpander) set(name, value string) {
+	e.vars[name] = unquoteScriptValue(e.substitute(strings.TrimSpace(value)))
+}
+
+func (e *scriptExpander) include(target, from string, defineOnly bool) (string, error) {
+	target = unquoteScriptValue(target)
+	if !filepath.IsAbs(target) {
+		if from == "" {
+			return "", fmt.Errorf("cannot include %q, the file has no path to resolve it from", target)
+		}
+		target = filepath.Join(filepath.Dir(from), target)
+	}
+	target = filepath.Clean(target)
+	if e.including[target] {
+		return "", fmt.Errorf("include cycle at %q", target)
+	}
+	b, err := os.ReadFile(target)
+	if err != nil {
+		return "", fmt.Errorf("cannot include %q, %w", target, err)
+	}
+	e.including[target] = true
+	defer delete(e.including, target)
+	return e.expand(strings.TrimRight(string(b), "\r\n"), target, defineOnly)
+}
+
+func (e *scriptExpander) substitute(line string) string {
+	return scriptVariablePattern.ReplaceAllStringFunc(line, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		if v, ok := e.vars[name]; ok {
+			return v
+		}
+		if e.lookup != nil {
+			if v, ok := e.lookup(name); ok {
+				return v
+			}
+		}
+		return ref
+	})
+}
+
+// unquoteScriptValue strips the quotes around a value, as in
+// ":setvar name "value"".
+func unquoteScriptValue(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// uriToPath returns the path of a file URI, or "" for other URIs.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sqls-server/sqls/internal/config"
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+func Test_scriptText(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "common"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile := func(name, text string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("common/setup.sql", "\\set to 2024-02-01\nSET search_path = ${schema};\n")
+	writeFile("loop.sql", "-- @include loop.sql\n")
+	uri := "file://" + filepath.ToSlash(filepath.Join(dir, "main.sql"))
+
+	s := &Server{
+		WSCfg:    &config.Config{Variables: map[string]string{"schema": "public", "from": "2023-01-01"}},
+		curDBCfg: &database.DBConfig{Variables: map[string]string{"schema": "staging"}},
+	}
+	t.Setenv("SQLS_TEST_TABLE", "orders")
+
+	tests := []struct {
+		name    string
+		text    string
+		rng     *lsp.Range
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "config and environment",
+			text: "SELECT * FROM ${schema}.${SQLS_TEST_TABLE} WHERE d >= '${from}'",
+			want: "SELECT * FROM staging.orders WHERE d >= '2023-01-01'",
+		},
+		{
+			name: "directives",
+			text: "\\set schema 'sales'\n:setvar from \"2024-01-01\"\nSELECT * FROM ${schema}.t WHERE d >= '${from}'",
+			want: "\n\nSELECT * FROM sales.t WHERE d >= '2024-01-01'",
+		},
+		{
+			name: "include",
+			text: "-- @include common/setup.sql\nSELECT '${to}'",
+			want: "\nSET search_path = staging;\nSELECT '2024-02-01'",
+		},
+		{
+			name: "range keeps earlier variables",
+			text: "\\set schema sales\nSELECT 1;\nSELECT * FROM ${schema}.t",
+			rng: &lsp.Range{
+				Start: lsp.Position{Line: 2, Character: 0},
+				End:   lsp.Position{Line: 2, Character: 26},
+			},
+			want: "SELECT * FROM sales.t",
+		},
+		{
+			name: "range keeps the delimiter",
+			text: "DELIMITER $$\nSELECT 1$$\nSELECT 2$$",
+			rng: &lsp.Range{
+				Start: lsp.Position{Line: 2, Character: 0},
+				End:   lsp.Position{Line: 2, Character: 10},
+			},
+			want: "DELIMITER $$\nSELECT 2$$",
+		},
+		{
+			name: "undefined variable",
+			text: "SELECT '${nothing}';\nCREATE FUNCTION f() RETURNS text AS $$ SELECT '${x}' $$ LANGUAGE sql",
+			want: "SELECT '${nothing}';\nCREATE FUNCTION f() RETURNS text AS $$ SELECT '${x}' $$ LANGUAGE sql",
+		},
+		{
+			name:    "include cycle",
+			text:    "-- @include loop.sql",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.scriptText(uri, tt.text, tt.rng)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unmatch text (- want, + got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+)
+
+// executor returns where executions run: the session connection when the
+// driver connection can be pinned, and the repository otherwise.
+func (s *Server) executor(ctx context.Context) (database.Executor, error) {
+	if s.session == nil {
+		return s.newDBRepository(ctx)
+	}
+	// Result sets kept open for paging hold the session connection.
+	s.closeResultSets()
+	return s.session, nil
+}
+
+// setStatementTimeout makes the server cancel the next statements of the
+// session once timeout has passed, 0 for no limit.
+func (s *Server) setStatementTimeout(ctx context.Context, timeout time.Duration) error {
+	if s.session == nil {
+		return nil
+	}
+	// Result sets kept open for paging hold the session connection.
+	s.closeResultSets()
+	return s.session.SetTimeout(ctx, timeout)
+}
+
+// readMessages reads the messages of a query whose rows were just closed.
+func (s *Server) readMessages(ctx context.Context) {
+	if s.session != nil {
+		s.session.ReadMessages(ctx)
+	}
+}
+
+func (s *Server) openSession() {
+	s.closeSession()
+	if s.dbConn != nil && s.dbConn.Conn != nil && s.curDBCfg != nil {
+		s.session = database.NewSession(s.dbConn.Conn, s.curDBCfg.Driver)
+	}
+}
+
+func (s *Server) closeSession() {
+	s.closeResultSets()
+	if s.session != nil {
+		_ = s.session.Close()
+		s.session = nil
+	}
+}
+
+func (s *Server) beginTransaction(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if s.session == nil {
+		return nil, database.ErrSessionNotPinned
+	}
+	s.closeResultSets()
+	if err := s.session.Begin(ctx); err != nil {
+		return nil, err
+	}
+	return "Transaction started", nil
+}
+
+func (s *Server) commit(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if s.session == nil {
+		return nil, database.ErrSessionNotPinned
+	}
+	// Open result sets of the transaction would block the commit.
+	s.closeResultSets()
+	if err := s.session.Commit(); err != nil {
+		return nil, err
+	}
+	return "Transaction committed", nil
+}
+
+func (s *Server) rollback(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if s.session == nil {
+		return nil, database.ErrSessionNotPinned
+	}
+	s.closeResultSets()
+	if err := s.session.Rollback(); err != nil {
+		return nil, err
+	}
+	return "Transaction rolled back", nil
+}
+
+func (s *Server) showStatus(ctx context.Context, params lsp.ExecuteCommandParams) (result interface{}, err error) {
+	if s.dbConn == nil || s.curDBCfg == nil {
+		return "Not connected", nil
+	}
+	lines := []string{
+		strings.TrimSpace(fmt.Sprintf("Connection: %d %s %s", s.curConnectionIndex+1, s.curDBCfg.Driver, s.curDBCfg.Alias)),
+	}
+	if s.curDBName != "" {
+		lines = append(lines, "Database: "+s.curDBName)
+	}
+	switch inTx, since := s.session.InTransaction(); {
+	case s.session == nil:
+		lines = append(lines, "Transaction: not supported")
+	case inTx:
+		lines = append(lines, fmt.Sprintf("Transaction: active for %s", time.Since(since).Round(time.Second)))
+	default:
+		lines = append(lines, "Transaction: none")
+	}
+	return strings.Join(lines, "\n"), nil
+}
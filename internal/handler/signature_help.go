@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/sourcegraph/jsonrpc2"
+	"github.com/sqls-server/sqls/dialect"
 	"github.com/sqls-server/sqls/internal/database"
 	"github.com/sqls-server/sqls/internal/lsp"
 	"github.com/sqls-server/sqls/parser"
@@ -28,19 +29,19 @@ func (s *Server) handleTextDocumentSignatureHelp(ctx context.Context, conn *json
 		return nil, fmt.Errorf("document not found: %s", params.TextDocument.URI)
 	}
 
-	res, err := SignatureHelp(f.Text, params, s.worker.Cache())
+	res, err := SignatureHelp(f.Text, s.driver(), params, s.worker.Cache())
 	if err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
-func SignatureHelp(text string, params lsp.SignatureHelpParams, dbCache *database.DBCache) (*lsp.SignatureHelp, error) {
+func SignatureHelp(text string, driver dialect.DatabaseDriver, params lsp.SignatureHelpParams, dbCache *database.DBCache) (*lsp.SignatureHelp, error) {
 	if dbCache == nil {
 		return nil, nil
 	}
 
-	parsed, err := parser.Parse(text)
+	parsed, err := parser.ParseFor(text, driver)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +72,7 @@ func SignatureHelp(text string, params lsp.SignatureHelpParams, dbCache *databas
 		for _, col := range cols.GetIdentifiers() {
 			colName := col.String()
 			colDoc := ""
-			colDesc, ok := dbCache.Column(tableName, colName)
+			colDesc, ok := dbCache.ColumnBySchema(table.DatabaseSchema, tableName, colName)
 			if ok {
 				colDoc = colDesc.OnelineDesc()
 			}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/ast/astutil"
+	"github.com/sqls-server/sqls/internal/database"
+	"github.com/sqls-server/sqls/internal/lsp"
+	"github.com/sqls-server/sqls/parser"
+	"github.com/sqls-server/sqls/parser/parseutil"
+	"github.com/sqls-server/sqls/token"
+)
+
+// Virtual documents are generated by the server and are read-only. Clients
+// fetch their text with the "sqls/virtualDocument" request.
+const virtualDocumentScheme = "sqls"
+
+// maxVirtualDocuments bounds the virtual documents kept for clients which
+// do not close them.
+const maxVirtualDocuments = 32
+
+func ddlDocumentURI(schemaName, tableName string) string {
+	name := tableName
+	if schemaName != "" {
+		name = schemaName + "." + tableName
+	}
+	return virtualDocumentScheme + ":/ddl/" + url.PathEscape(name) + ".sql"
+}
+
+func (s *Server) handleVirtualDocument(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+	}
+
+	var params lsp.TextDocumentIdentifier
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+
+	text, ok := s.virtualDocs[params.URI]
+	if !ok {
+		return nil, fmt.Errorf("document not found: %s", params.URI)
+	}
+	return text, nil
+}
+
+// openCreateTableDocument generates the DDL of a table and registers it as a
+// virtual document.
+func (s *Server) openCreateTableDocument(ctx context.Context, schemaName, tableName string) (uri string, text string, err error) {
+	repo, err := s.newDBRepository(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	dbCache := s.worker.Cache()
+	if schemaName == "" && dbCache != nil {
+		if resolved, ok := dbCache.ResolveSchema(tableName); ok {
+			schemaName = resolved
+		}
+	}
+	text, err = database.CreateTable(ctx, repo, dbCache, schemaName, tableName)
+	if err != nil {
+		return "", "", err
+	}
+	uri = ddlDocumentURI(schemaName, tableName)
+	s.putVirtualDocument(uri, text)
+	return uri, text, nil
+}
+
+func (s *Server) putVirtualDocument(uri, text string) {
+	s.closeVirtualDocument(uri)
+	for len(s.virtualDocURIs) >= maxVirtualDocuments {
+		s.closeVirtualDocument(s.virtualDocURIs[0])
+	}
+	s.virtualDocs[uri] = text
+	s.virtualDocURIs = append(s.virtualDocURIs, uri)
+}
+
+func (s *Server) closeVirtualDocument(uri string) {
+	if _, ok := s.virtualDocs[uri]; !ok {
+		return
+	}
+	delete(s.virtualDocs, uri)
+	for i, v := range s.virtualDocURIs {
+		if v == uri {
+			s.virtualDocURIs = append(s.virtualDocURIs[:i], s.virtualDocURIs[i+1:]...)
+			break
+		}
+	}
+}
+
+// showDocument asks the client to open a virtual document in the
+// background, as the client cannot answer while a request is handled.
+func (s *Server) showDocument(conn *jsonrpc2.Conn, uri string, selection lsp.Range) {
+	if conn == nil || !s.canShowDocument {
+		return
+	}
+	go func() {
+		params := lsp.ShowDocumentParams{
+			URI:       uri,
+			TakeFocus: true,
+			Selection: &selection,
+		}
+		var res lsp.ShowDocumentResult
+		if err := conn.Call(context.Background(), "window/showDocument", params, &res); err != nil {
+			log.Println("show document:", err)
+		}
+	}()
+}
+
+// tableDefinition jumps from a table name to its generated DDL.
+func (s *Server) tableDefinition(ctx context.Context, text string, params lsp.DefinitionParams) (lsp.Definition, error) {
+	dbCache := s.worker.Cache()
+	if dbCache == nil || s.dbConn == nil {
+		return nil, nil
+	}
+	pos := token.Pos{
+		Line: params.Position.Line,
+		Col:  params.Position.Character + 1,
+	}
+	parsed, err := parser.ParseFor(text, s.driver())
+	if err != nil {
+		return nil, err
+	}
+	table, ok := focusedTable(parsed, pos)
+	if !ok {
+		return nil, nil
+	}
+	if _, ok := dbCache.ColumnDatabase(table.DatabaseSchema, table.Name); !ok {
+		return nil, nil
+	}
+
+	uri, ddl, err := s.openCreateTableDocument(ctx, table.DatabaseSchema, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	res := []lsp.Location{
+		{
+			URI:   uri,
+			Range: tableNameRange(ddl, table.Name),
+		},
+	}
+	return res, nil
+}
+
+// focusedTable returns the table of the statement whose name is at pos,
+// as in "FROM world.c[i]ty" or "SELECT c[i]ty.ID FROM city".
+func focusedTable(parsed ast.TokenList, pos token.Pos) (*parseutil.TableInfo, bool) {
+	nodeWalker := parseutil.NewNodeWalker(parsed, pos)
+	m := astutil.NodeMatcher{
+		NodeTypes: []ast.NodeType{
+			ast.TypeMemberIdentifier,
+			ast.TypeIdentifier,
+		},
+	}
+	ident, memIdent := findIdent(nodeWalker.CurNodeMatches(m))
+
+	var schemaName, tableName string
+	switch {
+	case memIdent != nil && memIdent.ChildTok != nil:
+		if ident != nil && ident.NoQuoteString() == memIdent.ParentTok.NoQuoteString() {
+			schemaName, tableName = memIdent.QualifierName(), memIdent.ParentTok.NoQuoteString()
+		} else {
+			schemaName, tableName = memIdent.ParentTok.NoQuoteString(), memIdent.ChildTok.NoQuoteString()
+		}
+	case ident != nil:
+		tableName = ident.NoQuoteString()
+	default:
+		return nil, false
+	}
+
+	tables, err := parseutil.ExtractTable(parsed, pos)
+	if err != nil {
+		return nil, false
+	}
+	for _, t := range tables {
+		if strings.EqualFold(t.Name, tableName) && (schemaName == "" || strings.EqualFold(t.DatabaseSchema, schemaName)) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// tableNameRange locates the table name in the header of a CREATE TABLE
+// statement.
+func tableNameRange(ddl, tableName string) lsp.Range {
+	header := ddl
+	if i := strings.IndexAny(header, "(\n"); i >= 0 {
+		header = header[:i]
+	}
+	i := strings.LastIndex(strings.ToUpper(header), strings.ToUpper(tableName))
+	if i < 0 {
+		return lsp.Range{}
+	}
+	start := utf16Len(header[:i])
+	return lsp.Range{
+		Start: lsp.Position{Line: 0, Character: start},
+		End:   lsp.Position{Line: 0, Character: start + utf16Len(tableName)},
+	}
+}
@@ -24,6 +24,22 @@ type InitializeOptions struct {
 }
 
 type ClientCapabilities struct {
+	Window *WindowClientCapabilities `json:"window,omitempty"`
+}
+
+type WindowClientCapabilities struct {
+	ShowMessage  *ShowMessageRequestClientCapabilities `json:"showMessage,omitempty"`
+	ShowDocument *ShowDocumentClientCapabilities       `json:"showDocument,omitempty"`
+}
+
+type ShowDocumentClientCapabilities struct {
+	Support bool `json:"support"`
+}
+
+type ShowMessageRequestClientCapabilities struct {
+	MessageActionItem *struct {
+		AdditionalPropertiesSupport bool `json:"additionalPropertiesSupport,omitempty"`
+	} `json:"messageActionItem,omitempty"`
 }
 
 type InitializeResult struct {
@@ -272,6 +288,18 @@ type Diagnostic struct {
 	RelatedInformation []DiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
 }
 
+const (
+	SeverityError       = 1
+	SeverityWarning     = 2
+	SeverityInformation = 3
+	SeverityHint        = 4
+)
+
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
 type WorkDoneProgressParams struct {
 	WorkDoneToken interface{} `json:"workDoneToken"`
 }
@@ -408,6 +436,11 @@ type ShowMessageParams struct {
 	Message string      `json:"message"`
 }
 
+type LogMessageParams struct {
+	Type    MessageType `json:"type"`
+	Message string      `json:"message"`
+}
+
 type ShowMessageRequestParams struct {
 	Type    MessageType         `json:"type"`
 	Message string              `json:"message"`
@@ -418,6 +451,17 @@ type MessageActionItem struct {
 	Title string `json:"title"`
 }
 
+type ShowDocumentParams struct {
+	URI       string `json:"uri"`
+	External  bool   `json:"external,omitempty"`
+	TakeFocus bool   `json:"takeFocus,omitempty"`
+	Selection *Range `json:"selection,omitempty"`
+}
+
+type ShowDocumentResult struct {
+	Success bool `json:"success"`
+}
+
 type MessageType float64
 
 var (
@@ -54,11 +54,19 @@ func parseInfixGroup(reader *astutil.NodeReader, matcher astutil.NodeMatcher, ig
 }
 
 func Parse(text string) (ast.TokenList, error) {
+	return ParseFor(text, "")
+}
+
+// ParseFor parses text as a script of the database of driver, in its
+// dialect and split into statements as its client does. An empty driver
+// accepts the client syntax of any database.
+func ParseFor(text string, driver dialect.DatabaseDriver) (ast.TokenList, error) {
 	src := bytes.NewBuffer([]byte(text))
-	p, err := NewParser(src, &dialect.GenericSQLDialect{})
+	p, err := NewParser(src, dialect.ForDriver(driver))
 	if err != nil {
 		return nil, err
 	}
+	p.split = splitRulesOf(driver)
 	parsed, err := p.Parse()
 	if err != nil {
 		return nil, err
@@ -67,7 +75,8 @@ func Parse(text string) (ast.TokenList, error) {
 }
 
 type Parser struct {
-	root ast.TokenList
+	root  ast.TokenList
+	split *splitRules
 }
 
 func NewParser(src io.Reader, d dialect.Dialect) (*Parser, error) {
@@ -83,7 +92,8 @@ func NewParser(src io.Reader, d dialect.Dialect) (*Parser, error) {
 	}
 
 	parser := &Parser{
-		root: &ast.Query{Toks: parsed},
+		root:  &ast.Query{Toks: parsed},
+		split: splitRulesOf(""),
 	}
 
 	return parser, nil
@@ -91,7 +101,7 @@ func NewParser(src io.Reader, d dialect.Dialect) (*Parser, error) {
 
 func (p *Parser) Parse() (ast.TokenList, error) {
 	root := p.root
-	root = parseStatement(astutil.NewNodeReader(root))
+	root.SetTokens(splitStatements(root.GetTokens(), p.split))
 
 	root = parsePrefixGroup(astutil.NewNodeReader(root), parenthesisPrefixMatcher, parseParenthesis)
 	root = parsePrefixGroup(astutil.NewNodeReader(root), functionPrefixMatcher, parseFunctions)
@@ -110,37 +120,6 @@ func (p *Parser) Parse() (ast.TokenList, error) {
 	return root, nil
 }
 
-var statementMatcher = astutil.NodeMatcher{
-	ExpectTokens: []token.Kind{
-		token.Semicolon,
-	},
-}
-
-func parseStatement(reader *astutil.NodeReader) ast.TokenList {
-	var replaceNodes []ast.Node
-	var startIndex int
-	for reader.NextNode(false) {
-		if list, ok := reader.CurNode.(ast.TokenList); ok {
-			replaceNodes = append(replaceNodes, parseStatement(astutil.NewNodeReader(list)))
-			continue
-		}
-
-		tmpReader, node := reader.FindNode(true, statementMatcher)
-		if node != nil {
-			stmt := &ast.Statement{Toks: reader.NodesWithRange(startIndex, tmpReader.Index)}
-			replaceNodes = append(replaceNodes, stmt)
-			reader = tmpReader
-			startIndex = reader.Index
-		}
-	}
-	if reader.Index != startIndex {
-		stmt := &ast.Statement{Toks: reader.NodesWithRange(startIndex, reader.Index)}
-		replaceNodes = append(replaceNodes, stmt)
-	}
-	reader.Node.SetTokens(replaceNodes)
-	return reader.Node
-}
-
 var parenthesisPrefixMatcher = astutil.NodeMatcher{
 	ExpectTokens: []token.Kind{
 		token.LParen,
@@ -248,6 +227,33 @@ func parseMemberIdentifier(reader *astutil.NodeReader) ast.Node {
 		child,
 	)
 
+	reader.NextNode(false)
+	if !reader.PeekNodeIs(false, memberIdentifierInfixMatcher) {
+		return memberIdentifier
+	}
+
+	// schema.table.column
+	tmpReader := reader.CopyReader()
+	tmpReader.NextNode(false)
+	if !tmpReader.PeekNodeIs(true, memberIdentifierTargetMatcher) {
+		// schema.table. (the column is not typed yet)
+		memberIdentifier = ast.NewQualifiedMemberIdentifier(
+			reader.NodesWithRange(startIndex, reader.Index+1),
+			parent,
+			child,
+			nil,
+		)
+		reader.NextNode(false)
+		return memberIdentifier
+	}
+	endIndex, grandChild := tmpReader.PeekNode(true)
+	memberIdentifier = ast.NewQualifiedMemberIdentifier(
+		reader.NodesWithRange(startIndex, endIndex+1),
+		parent,
+		child,
+		grandChild,
+	)
+	reader.NextNode(false)
 	reader.NextNode(false)
 	return memberIdentifier
 }
@@ -336,6 +342,9 @@ var operatorTargetMatcher = astutil.NodeMatcher{
 		token.Char,
 		token.SingleQuotedString,
 		token.NationalStringLiteral,
+		token.EscapeStringLiteral,
+		token.DollarQuotedString,
+		token.Placeholder,
 	},
 }
 
@@ -412,6 +421,9 @@ var comparisonTargetMatcher = astutil.NodeMatcher{
 		token.Char,
 		token.SingleQuotedString,
 		token.NationalStringLiteral,
+		token.EscapeStringLiteral,
+		token.DollarQuotedString,
+		token.Placeholder,
 	},
 	ExpectKeyword: []string{
 		"TRUE",
@@ -511,7 +523,10 @@ func parseAliased(reader *astutil.NodeReader) ast.Node {
 		isLiteral := tok.Kind == token.Number ||
 			tok.Kind == token.Char ||
 			tok.Kind == token.SingleQuotedString ||
-			tok.Kind == token.NationalStringLiteral
+			tok.Kind == token.NationalStringLiteral ||
+			tok.Kind == token.EscapeStringLiteral ||
+			tok.Kind == token.DollarQuotedString ||
+			tok.Kind == token.Placeholder
 
 		// Also allow TRUE/FALSE/NULL keywords as literals
 		if !isLiteral && tok.Kind == token.SQLKeyword {
@@ -571,6 +586,9 @@ var identifierListTargetMatcher = astutil.NodeMatcher{
 		token.Char,
 		token.SingleQuotedString,
 		token.NationalStringLiteral,
+		token.EscapeStringLiteral,
+		token.DollarQuotedString,
+		token.Placeholder,
 	},
 	NodeTypes: []ast.NodeType{
 		ast.TypeFunctionLiteral,
@@ -493,6 +493,34 @@ func TestMemberIdentifier(t *testing.T) {
 				testMemberIdentifier(t, list[6], "myschema.abc", "myschema", "abc")
 			},
 		},
+		{
+			name:  "invalid schema qualified member identifier",
+			input: "myschema.abc.",
+			checkFn: func(t *testing.T, stmts []*ast.Statement, input string) {
+				testStatement(t, stmts[0], 1, input)
+				list := stmts[0].GetTokens()
+				testMemberIdentifier(t, list[0], input, "abc", "")
+			},
+		},
+		{
+			name:  "schema qualified member identifier",
+			input: "select myschema.abc.foo from myschema.abc",
+			checkFn: func(t *testing.T, stmts []*ast.Statement, input string) {
+				testStatement(t, stmts[0], 7, input)
+				list := stmts[0].GetTokens()
+				testItem(t, list[0], "select")
+				testItem(t, list[1], " ")
+				testMemberIdentifier(t, list[2], "myschema.abc.foo", "abc", "foo")
+				testPos(t, list[2], genPosOneline(7), genPosOneline(23))
+				if q := list[2].(*ast.MemberIdentifier).Qualifier; q == nil || q.String() != "myschema" {
+					t.Errorf("qualifier expected %q, got %v", "myschema", q)
+				}
+				testItem(t, list[3], " ")
+				testItem(t, list[4], "from")
+				testItem(t, list[5], " ")
+				testMemberIdentifier(t, list[6], "myschema.abc", "myschema", "abc")
+			},
+		},
 	}
 	for _, tt := range testcases {
 		t.Run(tt.name, func(t *testing.T) {
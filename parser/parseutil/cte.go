@@ -0,0 +1,264 @@
+package parseutil
+
+import (
+	"strings"
+
+	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/token"
+)
+
+// CTEInfo is a common table expression declared by a WITH clause.
+type CTEInfo struct {
+	Name      string
+	Recursive bool
+	// Columns are the columns selected by the query of the CTE. When it
+	// has a column list, they are named by the list, in the same order.
+	Columns []*SubQueryColumn
+	// Ident is the name of the CTE in its declaration.
+	Ident *ast.Identifier
+
+	body *ast.Parenthesis
+}
+
+// ExtractCTEs returns the common table expressions declared in the
+// statement at pos, including those of WITH clauses in its subqueries.
+func ExtractCTEs(parsed ast.TokenList, pos token.Pos) ([]*CTEInfo, error) {
+	stmt, err := extractFocusedStatement(parsed, pos)
+	if err != nil {
+		return nil, err
+	}
+	return extractCTEs(stmt), nil
+}
+
+// FindCTE returns the common table expression named name, whose case does
+// not matter as for other unquoted names.
+func FindCTE(ctes []*CTEInfo, name string) (*CTEInfo, bool) {
+	for _, cte := range ctes {
+		if strings.EqualFold(cte.Name, name) {
+			return cte, true
+		}
+	}
+	return nil, false
+}
+
+// withoutCTEBodies returns stmt without the queries of its CTEs, whose
+// tables are not those of the statement.
+func withoutCTEBodies(stmt ast.TokenList) ast.TokenList {
+	ctes := extractCTEs(stmt)
+	if len(ctes) == 0 {
+		return stmt
+	}
+	bodies := map[ast.Node]bool{}
+	for _, cte := range ctes {
+		bodies[cte.body] = true
+	}
+	var toks []ast.Node
+	for _, node := range stmt.GetTokens() {
+		if !bodies[node] {
+			toks = append(toks, node)
+		}
+	}
+	return &ast.Statement{Toks: toks}
+}
+
+var (
+	withMatcher         = genKeywordMatcher([]string{"WITH"})
+	recursiveMatcher    = genKeywordMatcher([]string{"RECURSIVE"})
+	asMatcher           = genKeywordMatcher([]string{"AS"})
+	materializedMatcher = genKeywordMatcher([]string{"NOT", "MATERIALIZED"})
+	selectMatcher       = genKeywordMatcher([]string{"SELECT"})
+	quantifierMatcher   = genKeywordMatcher([]string{"ALL", "DISTINCT"})
+	commaMatcher        = genTokenMatcher([]token.Kind{token.Comma})
+)
+
+func extractCTEs(list ast.TokenList) []*CTEInfo {
+	var ctes []*CTEInfo
+	nodes := SignificantNodes(list)
+	for i, node := range nodes {
+		if _, ok := node.(*ast.Item); ok && withMatcher.IsMatch(node) {
+			ctes = append(ctes, parseWithClause(nodes[i+1:])...)
+		}
+		if child, ok := node.(ast.TokenList); ok {
+			ctes = append(ctes, extractCTEs(child)...)
+		}
+	}
+	return ctes
+}
+
+// parseWithClause reads the CTEs following a WITH keyword, separated by
+// commas.
+func parseWithClause(nodes []ast.Node) []*CTEInfo {
+	recursive := len(nodes) > 0 && recursiveMatcher.IsMatch(nodes[0])
+	if recursive {
+		nodes = nodes[1:]
+	}
+	var ctes []*CTEInfo
+	for {
+		cte, rest, ok := parseCTE(nodes)
+		if !ok {
+			return ctes
+		}
+		cte.Recursive = recursive
+		ctes = append(ctes, cte)
+		if len(rest) == 0 || !commaMatcher.IsMatch(rest[0]) {
+			return ctes
+		}
+		nodes = rest[1:]
+	}
+}
+
+// parseCTE reads "name [(columns)] AS [[NOT] MATERIALIZED] (query)" and
+// returns the nodes after it.
+func parseCTE(nodes []ast.Node) (*CTEInfo, []ast.Node, bool) {
+	if len(nodes) == 0 {
+		return nil, nil, false
+	}
+	var (
+		ident *ast.Identifier
+		names []string
+	)
+	switch v := nodes[0].(type) {
+	case *ast.Identifier:
+		ident = v
+		nodes = nodes[1:]
+		if len(nodes) > 0 {
+			if p, ok := nodes[0].(*ast.Parenthesis); ok {
+				names = columnListNames(p)
+				nodes = nodes[1:]
+			}
+		}
+	case *ast.FunctionLiteral:
+		// "name(columns)" reads as a function call
+		toks := SignificantNodes(v)
+		if len(toks) != 2 {
+			return nil, nil, false
+		}
+		id, ok := toks[0].(*ast.Identifier)
+		if !ok {
+			return nil, nil, false
+		}
+		p, ok := toks[1].(*ast.Parenthesis)
+		if !ok {
+			return nil, nil, false
+		}
+		ident = id
+		names = columnListNames(p)
+		nodes = nodes[1:]
+	default:
+		return nil, nil, false
+	}
+
+	if len(nodes) == 0 || !asMatcher.IsMatch(nodes[0]) {
+		return nil, nil, false
+	}
+	nodes = nodes[1:]
+	for len(nodes) > 0 && materializedMatcher.IsMatch(nodes[0]) {
+		nodes = nodes[1:]
+	}
+	if len(nodes) == 0 {
+		return nil, nil, false
+	}
+	body, ok := nodes[0].(*ast.Parenthesis)
+	if !ok {
+		return nil, nil, false
+	}
+	cte := &CTEInfo{
+		Name:    ident.NoQuoteString(),
+		Columns: cteColumns(body.Inner(), names),
+		Ident:   ident,
+		body:    body,
+	}
+	return cte, nodes[1:], true
+}
+
+func columnListNames(p *ast.Parenthesis) []string {
+	var names []string
+	for _, node := range SignificantNodes(p.Inner()) {
+		switch v := node.(type) {
+		case *ast.Identifier:
+			names = append(names, v.NoQuoteString())
+		case *ast.IdentifierList:
+			for _, ident := range v.GetIdentifiers() {
+				if id, ok := ident.(*ast.Identifier); ok {
+					names = append(names, id.NoQuoteString())
+				}
+			}
+		}
+	}
+	return names
+}
+
+// cteColumns returns the columns of a CTE query, renamed by names when the
+// CTE has a column list.
+func cteColumns(body ast.TokenList, names []string) []*SubQueryColumn {
+	projected := projectedColumns(body)
+	if len(names) == 0 {
+		var cols []*SubQueryColumn
+		for _, col := range projected {
+			if col.DisplayName() != "" {
+				cols = append(cols, col)
+			}
+		}
+		return cols
+	}
+	cols := make([]*SubQueryColumn, len(names))
+	for i, name := range names {
+		cols[i] = &SubQueryColumn{ColumnName: name}
+		if i < len(projected) && (projected[i].Expr != nil || projected[i].ColumnName != "" && projected[i].ColumnName != "*") {
+			col := *projected[i]
+			col.AliasName = name
+			cols[i] = &col
+		}
+	}
+	return cols
+}
+
+// projectedColumns returns a column for each expression selected by the
+// first SELECT of query, which is the non-recursive part of a recursive
+// CTE. Expressions without a name give a derived column without one.
+func projectedColumns(query ast.TokenList) []*SubQueryColumn {
+	nodes := SignificantNodes(query)
+	i := 0
+	for i < len(nodes) && !selectMatcher.IsMatch(nodes[i]) {
+		i++
+	}
+	i++
+	for i < len(nodes) && quantifierMatcher.IsMatch(nodes[i]) {
+		i++
+	}
+	if i >= len(nodes) {
+		return nil
+	}
+	// Tables which cannot be read leave the columns without a parent.
+	tables, _ := extractAllTableIdentifiers(query, true)
+
+	items := []ast.Node{nodes[i]}
+	if list, ok := nodes[i].(*ast.IdentifierList); ok {
+		items = list.GetIdentifiers()
+	}
+	var cols []*SubQueryColumn
+	for _, item := range items {
+		itemCols, err := parseSubQueryColumns(item, tables)
+		if err != nil {
+			itemCols = []*SubQueryColumn{{Expr: item, Tables: tables}}
+		}
+		cols = append(cols, itemCols...)
+	}
+	return cols
+}
+
+// SignificantNodes returns the nodes of list without whitespace and
+// comments.
+func SignificantNodes(list ast.TokenList) []ast.Node {
+	var nodes []ast.Node
+	for _, node := range list.GetTokens() {
+		if tok, ok := node.(ast.Token); ok {
+			kind := tok.GetToken().Kind
+			if kind == token.Whitespace || kind == token.Comment || kind == token.MultilineComment {
+				continue
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -0,0 +1,142 @@
+package parseutil
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sqls-server/sqls/token"
+)
+
+func TestExtractCTEs(t *testing.T) {
+	city := &TableInfo{Name: "city"}
+	testcases := []struct {
+		name  string
+		input string
+		want  []*CTEInfo
+		// exprs are the expressions of the derived columns, in order.
+		exprs []string
+	}{
+		{
+			name:  "none",
+			input: "SELECT ID FROM city",
+			want:  nil,
+		},
+		{
+			name:  "single",
+			input: "WITH c AS (SELECT ID, Name AS city_name FROM city) SELECT * FROM c",
+			want: []*CTEInfo{
+				{
+					Name: "c",
+					Columns: []*SubQueryColumn{
+						{ParentTable: city, ColumnName: "ID"},
+						{ParentTable: city, ColumnName: "Name", AliasName: "city_name"},
+					},
+				},
+			},
+		},
+		{
+			name:  "column list",
+			input: "WITH c(id, n) AS (SELECT ID, Name FROM city) SELECT * FROM c",
+			want: []*CTEInfo{
+				{
+					Name: "c",
+					Columns: []*SubQueryColumn{
+						{ParentTable: city, ColumnName: "ID", AliasName: "id"},
+						{ParentTable: city, ColumnName: "Name", AliasName: "n"},
+					},
+				},
+			},
+		},
+		{
+			name:  "column list of expressions",
+			input: "WITH c (total) AS MATERIALIZED (SELECT count(*) FROM city) SELECT * FROM c",
+			want: []*CTEInfo{
+				{
+					Name: "c",
+					Columns: []*SubQueryColumn{
+						{AliasName: "total", Tables: []*TableInfo{city}},
+					},
+				},
+			},
+			exprs: []string{"count(*)"},
+		},
+		{
+			name:  "multiple",
+			input: "WITH a AS (SELECT ID FROM city), b AS (SELECT 1 AS one) SELECT * FROM a, b",
+			want: []*CTEInfo{
+				{
+					Name: "a",
+					Columns: []*SubQueryColumn{
+						{ParentTable: city, ColumnName: "ID"},
+					},
+				},
+				{
+					Name: "b",
+					Columns: []*SubQueryColumn{
+						{AliasName: "one"},
+					},
+				},
+			},
+			exprs: []string{"1"},
+		},
+		{
+			name:  "recursive",
+			input: "WITH RECURSIVE r AS (SELECT 1 AS n UNION ALL SELECT n + 1 FROM r) SELECT n FROM r",
+			want: []*CTEInfo{
+				{
+					Name:      "r",
+					Recursive: true,
+					Columns: []*SubQueryColumn{
+						{AliasName: "n", Tables: []*TableInfo{{Name: "r"}}},
+					},
+				},
+			},
+			exprs: []string{"1"},
+		},
+		{
+			name:  "in subquery",
+			input: "SELECT * FROM (WITH c AS (SELECT ID FROM city) SELECT * FROM c) AS sub",
+			want: []*CTEInfo{
+				{
+					Name: "c",
+					Columns: []*SubQueryColumn{
+						{ParentTable: city, ColumnName: "ID"},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			query := initExtractTable(t, tt.input)
+			got, err := ExtractCTEs(query, token.Pos{Line: 0, Col: 1})
+			if err != nil {
+				t.Fatalf("error: %+v", err)
+			}
+			var exprs []string
+			for _, cte := range got {
+				if cte.Ident == nil || cte.Ident.NoQuoteString() != cte.Name {
+					t.Errorf("unmatched ident of %q: %v", cte.Name, cte.Ident)
+				}
+				for _, col := range cte.Columns {
+					if col.Expr != nil {
+						exprs = append(exprs, col.Expr.String())
+					}
+				}
+			}
+			if d := cmp.Diff(tt.exprs, exprs); d != "" {
+				t.Errorf("unmatched derived columns(- want, + got): %s", d)
+			}
+			opts := []cmp.Option{
+				cmpopts.IgnoreFields(CTEInfo{}, "Ident"),
+				cmpopts.IgnoreFields(SubQueryColumn{}, "Expr"),
+				cmpopts.IgnoreUnexported(CTEInfo{}),
+				cmpopts.EquateEmpty(),
+			}
+			if d := cmp.Diff(tt.want, got, opts...); d != "" {
+				t.Errorf("unmatched value(- want, + got): %s", d)
+			}
+		})
+	}
+}
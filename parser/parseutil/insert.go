@@ -9,6 +9,11 @@ type Insert struct {
 	Tables  []*TableInfo
 	Columns []*ast.IdentifierList
 	Values  []*ast.IdentifierList
+	// ColumnList is the column list of the statement, or nil when it
+	// inserts into all the columns of the table.
+	ColumnList *ast.Parenthesis
+	// Rows are all the rows of the VALUES clause, wherever pos is.
+	Rows []*ast.Parenthesis
 }
 
 func (i *Insert) Enable() bool {
@@ -74,10 +79,118 @@ func ExtractInsert(parsed ast.TokenList, pos token.Pos) (*Insert, error) {
 		}
 	}
 
+	columnList, tableIdent := insertColumnList(stmt)
+	if len(tables) == 0 && tableIdent != nil {
+		tables = []*TableInfo{{Name: tableIdent.NoQuoteString()}}
+	}
+
 	res := &Insert{
-		Tables:  tables,
-		Columns: columns,
-		Values:  values,
+		Tables:     tables,
+		Columns:    columns,
+		Values:     values,
+		ColumnList: columnList,
+		Rows:       insertRows(stmt),
 	}
 	return res, nil
 }
+
+var (
+	valuesMatcher = genKeywordMatcher([]string{"VALUES"})
+	// insertSourceMatcher matches the keywords which follow the column list
+	insertSourceMatcher = genKeywordMatcher([]string{"VALUES", "SELECT", "WITH", "SET", "DEFAULT"})
+)
+
+// insertNodes returns the nodes of an INSERT statement, undoing the
+// grouping of "VALUES(...)" as a function call and of the rows following
+// it as an identifier list.
+func insertNodes(stmt ast.TokenList) []ast.Node {
+	var nodes []ast.Node
+	for _, node := range SignificantNodes(stmt) {
+		switch v := node.(type) {
+		case *ast.IdentifierList:
+			nodes = append(nodes, insertNodes(v)...)
+		case *ast.FunctionLiteral:
+			toks := SignificantNodes(v)
+			if len(toks) == 2 && valuesMatcher.IsMatch(toks[0]) {
+				nodes = append(nodes, toks...)
+			} else {
+				nodes = append(nodes, v)
+			}
+		default:
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// insertColumnList returns the parenthesis following the table name. The
+// parser binds it to the name when no space separates them, as in
+// "city(ID, Name)", in which case the name is returned too.
+func insertColumnList(stmt ast.TokenList) (*ast.Parenthesis, *ast.Identifier) {
+	for _, node := range insertNodes(stmt) {
+		if insertSourceMatcher.IsMatch(node) {
+			return nil, nil
+		}
+		switch v := node.(type) {
+		case *ast.Parenthesis:
+			return v, nil
+		case *ast.FunctionLiteral:
+			toks := SignificantNodes(v)
+			if len(toks) != 2 {
+				continue
+			}
+			if p, ok := toks[1].(*ast.Parenthesis); ok {
+				ident, _ := toks[0].(*ast.Identifier)
+				return p, ident
+			}
+		}
+	}
+	return nil, nil
+}
+
+func insertRows(stmt ast.TokenList) []*ast.Parenthesis {
+	nodes := insertNodes(stmt)
+	for i, node := range nodes {
+		if !valuesMatcher.IsMatch(node) {
+			continue
+		}
+		var rows []*ast.Parenthesis
+		for _, n := range nodes[i+1:] {
+			if commaMatcher.IsMatch(n) {
+				continue
+			}
+			p, ok := n.(*ast.Parenthesis)
+			if !ok {
+				break
+			}
+			rows = append(rows, p)
+		}
+		return rows
+	}
+	return nil
+}
+
+// ListItems returns the comma separated items of a column list or a row of
+// values. It returns false when they are not all grouped as a list, as
+// happens when an item cannot be parsed or is still being typed.
+func ListItems(p *ast.Parenthesis) ([]ast.Node, bool) {
+	nodes := SignificantNodes(p.Inner())
+	if len(nodes) == 0 {
+		return nil, true
+	}
+	if len(nodes) > 1 {
+		return nil, false
+	}
+	list, ok := nodes[0].(*ast.IdentifierList)
+	if !ok {
+		if commaMatcher.IsMatch(nodes[0]) {
+			return nil, false
+		}
+		return nodes, true
+	}
+	toks := SignificantNodes(list)
+	if len(toks) == 0 || commaMatcher.IsMatch(toks[len(toks)-1]) {
+		return nil, false
+	}
+	return list.GetIdentifiers(), true
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sqls-server/sqls/ast"
 	"github.com/sqls-server/sqls/token"
 )
 
@@ -76,3 +77,88 @@ func TestExtractInsert(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractInsertRows(t *testing.T) {
+	testcases := []struct {
+		name  string
+		input string
+		table string
+		cols  []string
+		rows  [][]string
+	}{
+		{
+			name:  "multiple rows",
+			input: "INSERT INTO city (ID, Name) VALUES (1, 'a'), (2, now())",
+			cols:  []string{"ID", "Name"},
+			rows:  [][]string{{"1", "'a'"}, {"2", "now()"}},
+		},
+		{
+			name:  "single column",
+			input: "INSERT INTO city (Name) VALUES ('a'), (1 + 2)",
+			cols:  []string{"Name"},
+			rows:  [][]string{{"'a'"}, {"1 + 2"}},
+		},
+		{
+			name:  "without column list",
+			input: "INSERT INTO city VALUES (1, 'a', 'JPN')",
+			cols:  nil,
+			rows:  [][]string{{"1", "'a'", "'JPN'"}},
+		},
+		{
+			name:  "without spaces",
+			input: "INSERT INTO city(ID, Name) VALUES(1, 'a'),(2, 'b')",
+			table: "city",
+			cols:  []string{"ID", "Name"},
+			rows:  [][]string{{"1", "'a'"}, {"2", "'b'"}},
+		},
+		{
+			name:  "unparsed row",
+			input: "INSERT INTO city (ID, Name) VALUES (1, 'a'), (-2, 'b')",
+			cols:  []string{"ID", "Name"},
+			rows:  [][]string{{"1", "'a'"}, nil},
+		},
+		{
+			name:  "select",
+			input: "INSERT INTO city (ID) SELECT ID FROM city",
+			cols:  []string{"ID"},
+			rows:  nil,
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := initExtractTable(t, tt.input)
+			got, err := ExtractInsert(stmt, token.Pos{Line: 0, Col: 12})
+			if err != nil {
+				t.Fatalf("error: %+v", err)
+			}
+			strs := func(p *ast.Parenthesis) []string {
+				items, ok := ListItems(p)
+				if !ok {
+					return nil
+				}
+				var rv []string
+				for _, item := range items {
+					rv = append(rv, item.String())
+				}
+				return rv
+			}
+			if tt.table != "" && (got.GetTable() == nil || got.GetTable().Name != tt.table) {
+				t.Errorf("unmatched table: %+v", got.GetTable())
+			}
+			var cols []string
+			if got.ColumnList != nil {
+				cols = strs(got.ColumnList)
+			}
+			if d := cmp.Diff(tt.cols, cols); d != "" {
+				t.Errorf("unmatched columns(-want, +got): %s", d)
+			}
+			var rows [][]string
+			for _, row := range got.Rows {
+				rows = append(rows, strs(row))
+			}
+			if d := cmp.Diff(tt.rows, rows); d != "" {
+				t.Errorf("unmatched rows(-want, +got): %s", d)
+			}
+		})
+	}
+}
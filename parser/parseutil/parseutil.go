@@ -43,6 +43,10 @@ type SubQueryColumn struct {
 	ParentName  string
 	ColumnName  string
 	AliasName   string
+	// Expr is the expression of a derived column, such as "count(*)" in
+	// "count(*) AS n", whose columns are read from Tables.
+	Expr   ast.Node
+	Tables []*TableInfo
 }
 
 func (sc *SubQueryColumn) DisplayName() string {
@@ -227,7 +231,7 @@ func extractTables(parsed ast.TokenList, pos token.Pos, stopOnPos bool) ([]*Tabl
 	if err != nil {
 		return nil, err
 	}
-	list := stmt
+	list := withoutCTEBodies(stmt)
 	if encloseIsSubQuery(stmt, pos) {
 		list = extractFocusedSubQuery(stmt, pos)
 	}
@@ -328,6 +332,10 @@ func extractSubQueryColumns(selectStmt ast.TokenList) ([]*SubQueryColumn, []*Tab
 	}
 	realIdents := []*SubQueryColumn{}
 	for _, ident := range cols {
+		if ident.Expr != nil {
+			realIdents = append(realIdents, ident)
+			continue
+		}
 		if ident.ColumnName == "*" {
 			return innerIdents, innerTables, nil
 		}
@@ -527,6 +535,10 @@ func parseSubQueryColumns(idents ast.Node, tables []*TableInfo) ([]*SubQueryColu
 	}
 
 	for _, subqueryCol := range subqueryCols {
+		if subqueryCol.Expr != nil {
+			subqueryCol.Tables = tables
+			continue
+		}
 		for _, table := range tables {
 			if table.isMatchTableName(subqueryCol.ParentName) {
 				subqueryCol.ParentTable = table
@@ -554,10 +566,10 @@ func aliasedToSubQueryColumn(aliased *ast.Aliased) (*SubQueryColumn, error) {
 		}
 		return subqueryCol, nil
 	default:
-		return nil, fmt.Errorf(
-			"failed trans alias to column, unknown node type %T, value %q",
-			aliased.AliasedName,
-			aliased.AliasedName,
-		)
+		subqueryCol := &SubQueryColumn{
+			AliasName: aliasedName,
+			Expr:      aliased.RealName,
+		}
+		return subqueryCol, nil
 	}
 }
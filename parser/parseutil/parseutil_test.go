@@ -821,6 +821,27 @@ func TestExtractTable(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "with clause",
+			input: "with c as (select * from abc) select * from c x",
+			pos:   token.Pos{Line: 0, Col: 40},
+			want: []*TableInfo{
+				{
+					Name:  "c",
+					Alias: "x",
+				},
+			},
+		},
+		{
+			name:  "in with clause",
+			input: "with c as (select * from abc) select * from c x",
+			pos:   token.Pos{Line: 0, Col: 20},
+			want: []*TableInfo{
+				{
+					Name: "abc",
+				},
+			},
+		},
 	}
 
 	for _, tt := range testcases {
@@ -0,0 +1,283 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/dialect"
+	"github.com/sqls-server/sqls/token"
+)
+
+// splitRules tell which client syntax of a database ends statements besides
+// semicolons.
+type splitRules struct {
+	// goLines end a batch at a line holding only GO, as sqlcmd does.
+	goLines bool
+	// batches send the statements between GO lines at once, so that the
+	// variables of a batch stay declared for all of it.
+	batches bool
+	// delimiterLines change what ends statements with DELIMITER lines, as
+	// the mysql client does.
+	delimiterLines bool
+	// slashLines end a statement at a line holding only "/", as SQL*Plus
+	// does.
+	slashLines bool
+	// dollarQuotes keep the semicolons of $$ quoted bodies, for dialects
+	// whose lexer does not read them as strings.
+	dollarQuotes bool
+	// isBlock tells whether a statement starting with words, such as a
+	// procedure body, is only ended by a GO or "/" line.
+	isBlock func(words []string) bool
+}
+
+func splitRulesOf(driver dialect.DatabaseDriver) *splitRules {
+	switch driver {
+	case dialect.DatabaseDriverMySQL,
+		dialect.DatabaseDriverMySQL8,
+		dialect.DatabaseDriverMySQL57,
+		dialect.DatabaseDriverMySQL56:
+		return &splitRules{delimiterLines: true}
+	case dialect.DatabaseDriverMssql:
+		return &splitRules{goLines: true, batches: true, isBlock: isTSQLBlock}
+	case dialect.DatabaseDriverOracle:
+		return &splitRules{slashLines: true, isBlock: isPLSQLBlock}
+	case dialect.DatabaseDriverPostgreSQL,
+		dialect.DatabaseDriverSQLite3,
+		dialect.DatabaseDriverH2,
+		dialect.DatabaseDriverVertica,
+		dialect.DatabaseDriverClickhouse:
+		return &splitRules{}
+	}
+	// Without a known database, take the syntax of any of them except
+	// blocks, whose BEGIN could as well start a transaction.
+	return &splitRules{goLines: true, delimiterLines: true, slashLines: true, dollarQuotes: true}
+}
+
+// isTSQLBlock tells whether a statement creates a procedure, function or
+// trigger, whose body runs to the end of the batch.
+func isTSQLBlock(words []string) bool {
+	if len(words) < 2 || (words[0] != "CREATE" && words[0] != "ALTER") {
+		return false
+	}
+	rest := words[1:]
+	if len(rest) > 2 && rest[0] == "OR" && rest[1] == "ALTER" {
+		rest = rest[2:]
+	}
+	switch rest[0] {
+	case "PROCEDURE", "PROC", "FUNCTION", "TRIGGER":
+		return true
+	}
+	return false
+}
+
+// isPLSQLBlock tells whether a statement is a PL/SQL block, either
+// anonymous or the body of a stored object.
+func isPLSQLBlock(words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "DECLARE", "BEGIN":
+		return true
+	case "CREATE":
+	default:
+		return false
+	}
+	for _, w := range words[1:] {
+		switch w {
+		case "OR", "REPLACE", "EDITIONABLE", "NONEDITIONABLE":
+			continue
+		case "PROCEDURE", "FUNCTION", "PACKAGE", "TRIGGER", "TYPE":
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// maxBlockWords is the number of leading words of a statement enough to
+// tell whether it is a block.
+const maxBlockWords = 6
+
+type lineKind int
+
+const (
+	lineSQL lineKind = iota
+	lineSeparator
+	lineDelimiter
+)
+
+// splitStatements groups the tokens of a script into statements. Every
+// token belongs to a statement, so the statements hold the whole script,
+// client syntax included.
+func splitStatements(nodes []ast.Node, rules *splitRules) []ast.Node {
+	var (
+		stmts     []ast.Node
+		query     strings.Builder
+		start     int
+		delimiter = ";"
+		dollarTag string
+		words     []string
+		wordsDone bool
+	)
+	closeStatement := func(end int, endsBatch bool) {
+		stmts = append(stmts, &ast.Statement{
+			Toks:      nodes[start:end],
+			Query:     query.String(),
+			EndsBatch: endsBatch || !rules.batches,
+		})
+		start = end
+		query.Reset()
+		words = nil
+		wordsDone = false
+	}
+	for i := 0; i < len(nodes); {
+		if dollarTag == "" && (i == 0 || isNewline(nodes[i-1])) {
+			end, kind, arg := clientLine(nodes, i, rules)
+			switch kind {
+			case lineSeparator:
+				closeStatement(end, true)
+				i = end
+				continue
+			case lineDelimiter:
+				delimiter = arg
+				i = end
+				continue
+			}
+		}
+		if dollarTag == "" && delimiter != ";" {
+			if n := matchText(nodes, i, delimiter); n > 0 {
+				i += n
+				closeStatement(i, false)
+				continue
+			}
+		}
+		if rules.dollarQuotes {
+			if tag, n := dollarQuote(nodes, i); n > 0 {
+				switch dollarTag {
+				case "":
+					dollarTag = tag
+				case tag:
+					dollarTag = ""
+				}
+				for _, node := range nodes[i : i+n] {
+					query.WriteString(node.String())
+				}
+				i += n
+				continue
+			}
+		}
+
+		node := nodes[i]
+		query.WriteString(node.String())
+		i++
+		if dollarTag != "" {
+			continue
+		}
+		if !wordsDone && !isSpaceOrComment(node) {
+			if w := word(node); w != "" && len(words) < maxBlockWords {
+				words = append(words, w)
+			} else {
+				wordsDone = true
+			}
+		}
+		if delimiter == ";" && isKind(node, token.Semicolon) && (rules.isBlock == nil || !rules.isBlock(words)) {
+			closeStatement(i, false)
+		}
+	}
+	if start < len(nodes) {
+		closeStatement(len(nodes), true)
+	}
+	return stmts
+}
+
+// clientLine tells whether the line starting at nodes[i] is client syntax
+// rather than SQL, and returns the index of its end. The argument of a
+// DELIMITER line is returned with it.
+func clientLine(nodes []ast.Node, i int, rules *splitRules) (end int, kind lineKind, arg string) {
+	end = i
+	var toks []ast.Node
+	for ; end < len(nodes) && !isNewline(nodes[end]); end++ {
+		if !isSpaceOrComment(nodes[end]) {
+			toks = append(toks, nodes[end])
+		}
+	}
+	if len(toks) == 0 {
+		return end, lineSQL, ""
+	}
+	switch {
+	case rules.goLines && word(toks[0]) == "GO" &&
+		(len(toks) == 1 || len(toks) == 2 && isKind(toks[1], token.Number)):
+		return end, lineSeparator, ""
+	case rules.slashLines && len(toks) == 1 && isKind(toks[0], token.Div):
+		return end, lineSeparator, ""
+	case rules.delimiterLines && len(toks) > 1 && word(toks[0]) == "DELIMITER":
+		var rest strings.Builder
+		for _, node := range nodes[i:end] {
+			rest.WriteString(node.String())
+		}
+		if fields := strings.Fields(rest.String()); len(fields) > 1 {
+			return end, lineDelimiter, fields[1]
+		}
+	}
+	return end, lineSQL, ""
+}
+
+// matchText returns the number of nodes from nodes[i] which spell text, 0
+// when they do not.
+func matchText(nodes []ast.Node, i int, text string) int {
+	var b strings.Builder
+	for j := i; j < len(nodes); j++ {
+		b.WriteString(nodes[j].String())
+		switch s := b.String(); {
+		case s == text:
+			return j - i + 1
+		case !strings.HasPrefix(text, s):
+			return 0
+		}
+	}
+	return 0
+}
+
+// dollarQuote returns the tag of a PostgreSQL dollar quote, "$$" or
+// "$name$", starting at nodes[i] with the number of its nodes. The generic
+// lexer reads "$name" as a placeholder.
+func dollarQuote(nodes []ast.Node, i int) (string, int) {
+	switch {
+	case isChar(nodes, i, "$") && isChar(nodes, i+1, "$"):
+		return "$$", 2
+	case isKind(nodes[i], token.Placeholder) && strings.HasPrefix(nodes[i].String(), "$") && isChar(nodes, i+1, "$"):
+		return nodes[i].String() + "$", 2
+	}
+	return "", 0
+}
+
+func isChar(nodes []ast.Node, i int, c string) bool {
+	return i < len(nodes) && isKind(nodes[i], token.Char) && nodes[i].String() == c
+}
+
+func isKind(node ast.Node, kind token.Kind) bool {
+	tok, ok := node.(ast.Token)
+	return ok && tok.GetToken().MatchKind(kind)
+}
+
+func isNewline(node ast.Node) bool {
+	return isKind(node, token.Whitespace) && node.String() == "\n"
+}
+
+func isSpaceOrComment(node ast.Node) bool {
+	return isKind(node, token.Whitespace) || isKind(node, token.Comment) || isKind(node, token.MultilineComment)
+}
+
+// word returns an unquoted word in upper case, or "" for other nodes.
+func word(node ast.Node) string {
+	tok, ok := node.(ast.Token)
+	if !ok || !tok.GetToken().MatchKind(token.SQLKeyword) {
+		return ""
+	}
+	w, ok := tok.GetToken().Value.(*token.SQLWord)
+	if !ok || w.QuoteStyle != 0 {
+		return ""
+	}
+	return strings.ToUpper(w.Value)
+}
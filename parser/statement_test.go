@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sqls-server/sqls/ast"
+	"github.com/sqls-server/sqls/dialect"
+)
+
+func TestParseForSplitsStatements(t *testing.T) {
+	testcases := []struct {
+		name   string
+		driver dialect.DatabaseDriver
+		input  string
+		want   []string
+	}{
+		{
+			name:   "semicolons",
+			driver: dialect.DatabaseDriverSQLite3,
+			input:  "SELECT 1; SELECT 2",
+			want:   []string{"SELECT 1;", "SELECT 2"},
+		},
+		{
+			name:   "mssql go batches",
+			driver: dialect.DatabaseDriverMssql,
+			input: "CREATE OR ALTER PROCEDURE p AS\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND\nGO\n" +
+				"EXEC p;\nSELECT 3\ngo 2\n",
+			want: []string{
+				"CREATE OR ALTER PROCEDURE p AS\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND",
+				"EXEC p;",
+				"SELECT 3",
+			},
+		},
+		{
+			name:   "mysql delimiter",
+			driver: dialect.DatabaseDriverMySQL,
+			input: "DELIMITER $$\nCREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\nEND$$\n" +
+				"SELECT 2$$\nDELIMITER ;\nCALL p();",
+			want: []string{
+				"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\nEND",
+				"SELECT 2",
+				"CALL p();",
+			},
+		},
+		{
+			name:   "oracle slash",
+			driver: dialect.DatabaseDriverOracle,
+			input: "CREATE OR REPLACE PROCEDURE p IS\nBEGIN\n  NULL;\nEND;\n/\n" +
+				"SELECT 1 FROM dual;\nBEGIN\n  p;\nEND;\n/\n",
+			want: []string{
+				"CREATE OR REPLACE PROCEDURE p IS\nBEGIN\n  NULL;\nEND;",
+				"SELECT 1 FROM dual;",
+				"BEGIN\n  p;\nEND;",
+			},
+		},
+		{
+			name:   "postgresql dollar quotes",
+			driver: dialect.DatabaseDriverPostgreSQL,
+			input: "CREATE FUNCTION f() RETURNS int AS $$\nBEGIN\n  PERFORM 1;\n  RETURN 2;\nEND;\n$$ LANGUAGE plpgsql;\n" +
+				"DO $body$ BEGIN PERFORM f(); END $body$;\nSELECT $1;",
+			want: []string{
+				"CREATE FUNCTION f() RETURNS int AS $$\nBEGIN\n  PERFORM 1;\n  RETURN 2;\nEND;\n$$ LANGUAGE plpgsql;",
+				"DO $body$ BEGIN PERFORM f(); END $body$;",
+				"SELECT $1;",
+			},
+		},
+		{
+			name:   "postgresql has no go batches",
+			driver: dialect.DatabaseDriverPostgreSQL,
+			input:  "SELECT 1\nGO\n",
+			want:   []string{"SELECT 1\nGO"},
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseFor(tt.input, tt.driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var text strings.Builder
+			var got []string
+			for _, node := range parsed.GetTokens() {
+				stmt, ok := node.(*ast.Statement)
+				if !ok {
+					t.Fatalf("invalid type want Statement parsed %T", node)
+				}
+				text.WriteString(stmt.String())
+				if query := strings.TrimSpace(stmt.Query); query != "" {
+					got = append(got, query)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unmatch statements (- want, + got):\n%s", diff)
+			}
+			if text.String() != tt.input {
+				t.Errorf("statements do not hold the whole text, got %q", text.String())
+			}
+		})
+	}
+}
+
+func TestParseForMarksBatchEnds(t *testing.T) {
+	testcases := []struct {
+		name   string
+		driver dialect.DatabaseDriver
+		input  string
+		want   []bool
+	}{
+		{
+			name:   "mssql",
+			driver: dialect.DatabaseDriverMssql,
+			input:  "DECLARE @id int = 5;\nSELECT * FROM t WHERE id = @id;\nGO\nSELECT 1;\nSELECT 2",
+			want:   []bool{false, false, true, false, true},
+		},
+		{
+			name:   "postgresql",
+			driver: dialect.DatabaseDriverPostgreSQL,
+			input:  "SELECT 1;\nSELECT 2",
+			want:   []bool{true, true},
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseFor(tt.input, tt.driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []bool
+			for _, node := range parsed.GetTokens() {
+				stmt, ok := node.(*ast.Statement)
+				if !ok {
+					t.Fatalf("invalid type want Statement parsed %T", node)
+				}
+				got = append(got, stmt.EndsBatch)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unmatch batch ends (- want, + got):\n%s", diff)
+			}
+		})
+	}
+}
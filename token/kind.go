@@ -70,6 +70,12 @@ const (
 	LBrace
 	// Right brace `}`
 	RBrace
+	// A bind parameter or variable i.e: $1, :name, @name
+	Placeholder
+	// Dollar quoted string i.e: $$string$$, $tag$string$tag$
+	DollarQuotedString
+	// Escape string i.e: E'string\n'
+	EscapeStringLiteral
 	// ILLEGAL sqltoken
 	ILLEGAL
 )
@@ -32,6 +32,10 @@ func (s *SQLWord) NoQuoteString() string {
 	return s.Value
 }
 
+// HashComment is the value of a Comment token started with # rather than
+// --.
+type HashComment string
+
 func matchingEndQuote(quoteStyle rune) rune {
 	switch quoteStyle {
 	case '"':
@@ -114,6 +118,9 @@ type Tokenizer struct {
 	Col     int
 }
 
+// genericSyntax is the syntax of dialects which do not tell theirs.
+var genericSyntax dialect.SyntaxDialect = &dialect.GenericSQLDialect{}
+
 func NewTokenizer(src io.Reader, dialect dialect.Dialect) *Tokenizer {
 	var scan scanner.Scanner
 	return &Tokenizer{
@@ -124,6 +131,14 @@ func NewTokenizer(src io.Reader, dialect dialect.Dialect) *Tokenizer {
 	}
 }
 
+// syntax returns the string and comment syntax of the dialect.
+func (t *Tokenizer) syntax() dialect.SyntaxDialect {
+	if d, ok := t.Dialect.(dialect.SyntaxDialect); ok {
+		return d
+	}
+	return genericSyntax
+}
+
 func (t *Tokenizer) Tokenize() ([]*Token, error) {
 	var tokenset []*Token
 
@@ -203,6 +218,29 @@ func (t *Tokenizer) next() (Kind, interface{}, error) {
 		v := MakeKeyword(s, 0)
 		return SQLKeyword, v, nil
 
+	case (r == 'E' || r == 'e') && t.syntax().HasEscapeStrings():
+		t.Scanner.Next()
+		if t.Scanner.Peek() == '\'' {
+			t.Col++
+			return EscapeStringLiteral, t.tokenizeEscapeString(), nil
+		}
+		s := t.tokenizeWord(r)
+		return SQLKeyword, MakeKeyword(s, 0), nil
+
+	case r == '#' && t.syntax().HasHashComments():
+		t.Scanner.Next()
+		var s []rune
+		for {
+			ch := t.Scanner.Peek()
+			if ch == scanner.EOF || ch == '\n' {
+				break
+			}
+			t.Scanner.Next()
+			s = append(s, ch)
+		}
+		t.Col += len(s) + 1
+		return Comment, HashComment(s), nil
+
 	case t.Dialect.IsIdentifierStart(r):
 		t.Scanner.Next()
 		s := t.tokenizeWord(r)
@@ -212,10 +250,21 @@ func (t *Tokenizer) next() (Kind, interface{}, error) {
 		s := t.tokenizeSingleQuotedString()
 		return SingleQuotedString, s, nil
 
+	case r == '"' && t.syntax().HasDoubleQuotedStrings():
+		// A double quoted string means the same as a single quoted one.
+		s := t.tokenizeQuotedString('"')
+		return SingleQuotedString, s, nil
+
 	case t.Dialect.IsDelimitedIdentifierStart(r):
 		s := t.tokenizeDelimitedIdentifier(r)
 		return SQLKeyword, s, nil
 
+	case r == '$' && t.syntax().HasDollarQuotedStrings():
+		return t.tokenizeDollar()
+
+	case t.Dialect.IsPlaceHolderStart(r):
+		return t.tokenizePlaceHolder(r)
+
 	case '0' <= r && r <= '9':
 		var s []rune
 		hasE := false
@@ -418,6 +467,12 @@ func (t *Tokenizer) tokenizeWord(f rune) string {
 }
 
 func (t *Tokenizer) tokenizeSingleQuotedString() string {
+	return t.tokenizeQuotedString('\'')
+}
+
+// tokenizeQuotedString reads a string quoted by quote, in which a doubled
+// quote stands for one.
+func (t *Tokenizer) tokenizeQuotedString(quote rune) string {
 	var str []rune
 	t.Scanner.Next()
 	cols := 1
@@ -425,12 +480,12 @@ func (t *Tokenizer) tokenizeSingleQuotedString() string {
 
 	for {
 		n := t.Scanner.Peek()
-		if n == '\'' {
+		if n == quote {
 			t.Scanner.Next()
-			if t.Scanner.Peek() == '\'' {
+			if t.Scanner.Peek() == quote {
 				// An escaped quote consumes two source columns
 				// but is stored as a single rune.
-				str = append(str, '\'')
+				str = append(str, quote)
 				t.Scanner.Next()
 				cols += 2
 			} else {
@@ -451,9 +506,120 @@ func (t *Tokenizer) tokenizeSingleQuotedString() string {
 
 	t.Col += cols
 	if isClosed {
-		return "'" + string(str) + "'"
+		return string(quote) + string(str) + string(quote)
+	}
+	return string(quote) + string(str)
+}
+
+// tokenizeEscapeString reads a string in which a backslash escapes the
+// character after it, keeping the escapes as written.
+func (t *Tokenizer) tokenizeEscapeString() string {
+	str := []rune{'\''}
+	t.Scanner.Next()
+	t.Col++
+	for {
+		n := t.Scanner.Next()
+		if n == scanner.EOF {
+			break
+		}
+		t.advance(n)
+		str = append(str, n)
+		if n == '\\' {
+			if e := t.Scanner.Next(); e != scanner.EOF {
+				t.advance(e)
+				str = append(str, e)
+			}
+			continue
+		}
+		if n == '\'' {
+			if t.Scanner.Peek() != '\'' {
+				break
+			}
+			str = append(str, t.Scanner.Next())
+			t.Col++
+		}
+	}
+	return string(str)
+}
+
+// tokenizeDollar reads a $1 parameter or a string quoted by $$ or $tag$,
+// which runs to the same tag.
+func (t *Tokenizer) tokenizeDollar() (Kind, interface{}, error) {
+	t.Scanner.Next()
+	tag := []rune{'$'}
+	for {
+		r := t.Scanner.Peek()
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '_' {
+			break
+		}
+		tag = append(tag, t.Scanner.Next())
+	}
+	isNumber := len(tag) > 1 && tag[1] >= '0' && tag[1] <= '9'
+	if t.Scanner.Peek() != '$' || isNumber {
+		t.Col += len(tag)
+		if len(tag) == 1 {
+			return Char, "$", nil
+		}
+		return Placeholder, string(tag), nil
+	}
+	tag = append(tag, t.Scanner.Next())
+	t.Col += len(tag)
+
+	str := append([]rune{}, tag...)
+	for !hasRuneSuffix(str[len(tag):], tag) {
+		n := t.Scanner.Next()
+		if n == scanner.EOF {
+			break
+		}
+		t.advance(n)
+		str = append(str, n)
+	}
+	return DollarQuotedString, string(str), nil
+}
+
+func hasRuneSuffix(s, suffix []rune) bool {
+	if len(s) < len(suffix) {
+		return false
+	}
+	for i, r := range suffix {
+		if s[len(s)-len(suffix)+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenizePlaceHolder reads a bind parameter or variable, such as :name or
+// @name. A start rune not followed by a name is read alone.
+func (t *Tokenizer) tokenizePlaceHolder(r rune) (Kind, interface{}, error) {
+	t.Scanner.Next()
+	if r == ':' && t.Scanner.Peek() == ':' {
+		t.Scanner.Next()
+		t.Col += 2
+		return DoubleColon, "::", nil
+	}
+	s := []rune{r}
+	for t.Dialect.IsPlaceHolderPart(t.Scanner.Peek()) {
+		s = append(s, t.Scanner.Next())
+	}
+	t.Col += len(s)
+	if len(s) > 1 {
+		return Placeholder, string(s), nil
+	}
+	if r == ':' {
+		return Colon, ":", nil
+	}
+	return Char, string(r), nil
+}
+
+// advance moves the position past r, which was read as part of a token.
+func (t *Tokenizer) advance(r rune) {
+	if r == '\n' {
+		t.Line++
+		t.Col = 0
+		return
 	}
-	return "'" + string(str)
+	t.Col++
 }
 
 func (t *Tokenizer) tokenizeDelimitedIdentifier(r rune) *SQLWord {
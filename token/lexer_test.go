@@ -906,3 +906,123 @@ test
 		}
 	})
 }
+
+// plainDialect hides the optional interfaces of a dialect, as one written
+// without them.
+type plainDialect struct {
+	dialect.Dialect
+}
+
+func TestTokenizer_Dialects(t *testing.T) {
+	type tok struct {
+		Kind  Kind
+		Value interface{}
+	}
+	cases := []struct {
+		name    string
+		dialect dialect.Dialect
+		in      string
+		out     []tok
+	}{
+		{
+			name:    "mysql hash comment",
+			dialect: &dialect.MySQLDialect{},
+			in:      "# note\n1",
+			out: []tok{
+				{Comment, HashComment(" note")},
+				{Whitespace, "\n"},
+				{Number, "1"},
+			},
+		},
+		{
+			name:    "mysql variables",
+			dialect: &dialect.MySQLDialect{},
+			in:      "@a,@@version",
+			out: []tok{
+				{Placeholder, "@a"},
+				{Comma, ","},
+				{Placeholder, "@@version"},
+			},
+		},
+		{
+			name:    "mysql double quoted string",
+			dialect: &dialect.MySQLDialect{},
+			in:      `"a""b" ` + "`c`",
+			out: []tok{
+				{SingleQuotedString, `"a"b"`},
+				{Whitespace, " "},
+				{SQLKeyword, MakeKeyword("c", '`')},
+			},
+		},
+		{
+			name:    "postgresql dollar quoted string",
+			dialect: &dialect.PostgreSQLDialect{},
+			in:      "$f$ a; 'b' $f$",
+			out: []tok{
+				{DollarQuotedString, "$f$ a; 'b' $f$"},
+			},
+		},
+		{
+			name:    "postgresql escape string",
+			dialect: &dialect.PostgreSQLDialect{},
+			in:      `E'a\'b'`,
+			out: []tok{
+				{EscapeStringLiteral, `'a\'b'`},
+			},
+		},
+		{
+			name:    "postgresql parameter and cast",
+			dialect: &dialect.PostgreSQLDialect{},
+			in:      "$1::int",
+			out: []tok{
+				{Placeholder, "$1"},
+				{DoubleColon, "::"},
+				{SQLKeyword, MakeKeyword("int", 0)},
+			},
+		},
+		{
+			name:    "mssql bracket identifier",
+			dialect: &dialect.MSSQLDialect{},
+			in:      "[order] #tmp",
+			out: []tok{
+				{SQLKeyword, MakeKeyword("order", '[')},
+				{Whitespace, " "},
+				{SQLKeyword, MakeKeyword("#tmp", 0)},
+			},
+		},
+		{
+			name:    "dialect without syntax",
+			dialect: plainDialect{&dialect.PostgreSQLDialect{}},
+			in:      "E'a'",
+			out: []tok{
+				{SQLKeyword, MakeKeyword("E", 0)},
+				{SingleQuotedString, "'a'"},
+			},
+		},
+		{
+			name:    "oracle bind variable",
+			dialect: &dialect.OracleDialect{},
+			in:      ":id",
+			out: []tok{
+				{Placeholder, ":id"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tokenizer := NewTokenizer(strings.NewReader(c.in), c.dialect)
+			toks, err := tokenizer.Tokenize()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]tok, len(toks))
+			for i, tk := range toks {
+				got[i] = tok{tk.Kind, tk.Value}
+			}
+			if d := cmp.Diff(c.out, got); d != "" {
+				t.Errorf("unmatched tokens (-want +got):\n%s", d)
+			}
+		})
+	}
+}